@@ -0,0 +1,259 @@
+package agscheduler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Implemented by the argument types of `RegisterTyped`
+// that need checks beyond their JSON shape.
+type ArgsValidator interface {
+	Validate() error
+}
+
+// Decode the `Args` of a job into `T`.
+//
+// Since `Args` may have passed through gob or protobuf `Struct`,
+// numbers are converted by JSON rather than by type assertions.
+func decodeArgs[T any](args map[string]any) (T, []ArgsFieldError) {
+	var t T
+
+	if args == nil {
+		args = map[string]any{}
+	}
+
+	b, err := json.Marshal(args)
+	if err != nil {
+		return t, []ArgsFieldError{{Description: err.Error()}}
+	}
+
+	fields := make([]ArgsFieldError, 0)
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&t); err != nil {
+		fields = append(fields, argsFieldErrorOf(err))
+	}
+
+	for _, name := range requiredArgs(reflect.TypeOf(t)) {
+		if _, ok := args[name]; !ok {
+			fields = append(fields, ArgsFieldError{Field: name, Description: "is required"})
+		}
+	}
+
+	if len(fields) > 0 {
+		return t, fields
+	}
+
+	var v any = &t
+	if _, ok := v.(ArgsValidator); !ok {
+		v = t
+	}
+	if av, ok := v.(ArgsValidator); ok {
+		if err := av.Validate(); err != nil {
+			var fErr *ArgsFieldError
+			if errors.As(err, &fErr) {
+				return t, []ArgsFieldError{*fErr}
+			}
+			return t, []ArgsFieldError{{Description: err.Error()}}
+		}
+	}
+
+	return t, nil
+}
+
+func argsFieldErrorOf(err error) ArgsFieldError {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return ArgsFieldError{
+			Field:       typeErr.Field,
+			Description: "must be " + schemaType(typeErr.Type) + ", not " + typeErr.Value,
+		}
+	}
+
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return ArgsFieldError{Field: strings.Trim(name, `"`), Description: "is unknown"}
+	}
+
+	return ArgsFieldError{Description: err.Error()}
+}
+
+// Fields of a struct are required unless they are pointers or tagged with `omitempty`.
+func requiredArgs(t reflect.Type) []string {
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	names := make([]string, 0)
+	for _, f := range structFields(t) {
+		if f.required {
+			names = append(names, f.name)
+		}
+	}
+
+	return names
+}
+
+type argsField struct {
+	name     string
+	required bool
+	typ      reflect.Type
+	// From the `description` tag.
+	description string
+}
+
+func structFields(t reflect.Type) []argsField {
+	fs := make([]argsField, 0)
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				fs = append(fs, structFields(ft)...)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		fs = append(fs, argsField{
+			name:     name,
+			required: sf.Type.Kind() != reflect.Pointer && !strings.Contains(opts, "omitempty"),
+			typ:      sf.Type,
+
+			description: sf.Tag.Get("description"),
+		})
+	}
+
+	return fs
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func schemaType(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "string"
+		}
+		return "array"
+	case reflect.Map:
+		return "object"
+	case reflect.Struct:
+		if t == timeType {
+			return "string"
+		}
+		return "object"
+	default:
+		return ""
+	}
+}
+
+func typeSchema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	s := map[string]any{}
+	if typ := schemaType(t); typ != "" {
+		s["type"] = typ
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() != reflect.Uint8 {
+			s["items"] = typeSchema(t.Elem())
+		}
+	case reflect.Map:
+		s["additionalProperties"] = typeSchema(t.Elem())
+	case reflect.Struct:
+		if t == timeType {
+			s["format"] = "date-time"
+			break
+		}
+
+		properties := map[string]any{}
+		// `[]any` rather than `[]string`, so that it can be converted to a protobuf `Struct`.
+		required := make([]any, 0)
+		for _, f := range structFields(t) {
+			fs := typeSchema(f.typ)
+			if f.description != "" {
+				fs["description"] = f.description
+			}
+			properties[f.name] = fs
+			if f.required {
+				required = append(required, f.name)
+			}
+		}
+		s["properties"] = properties
+		s["additionalProperties"] = false
+		if len(required) > 0 {
+			s["required"] = required
+		}
+	}
+
+	return s
+}
+
+// Generate the JSON Schema of `T` used as the `Args` of a function.
+func argsSchema[T any](name string) map[string]any {
+	s := typeSchema(reflect.TypeOf((*T)(nil)).Elem())
+	s["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	s["title"] = name
+
+	return s
+}
+
+// Document the arguments of a function from the fields of `T`.
+func argsDoc[T any]() []FuncArg {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	args := make([]FuncArg, 0)
+	for _, f := range structFields(t) {
+		args = append(args, FuncArg{
+			Name:        f.name,
+			Type:        schemaType(f.typ),
+			Description: f.description,
+			Required:    f.required,
+		})
+	}
+
+	return args
+}
@@ -0,0 +1,123 @@
+package agscheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type argsBase struct {
+	Queue string `json:"queue,omitempty"`
+}
+
+type argsTest struct {
+	argsBase
+	Url     string            `json:"url" description:"Target URL"`
+	Times   int               `json:"times"`
+	Rate    float64           `json:"rate,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Ids     []int             `json:"ids,omitempty"`
+	At      *time.Time        `json:"at"`
+	Ignored string            `json:"-"`
+}
+
+type argsValidated struct {
+	Times int `json:"times"`
+}
+
+func (a argsValidated) Validate() error {
+	if a.Times <= 0 {
+		return &ArgsFieldError{Field: "times", Description: "must be positive"}
+	}
+	return nil
+}
+
+type argsValidatedPtr struct {
+	Times int `json:"times"`
+}
+
+func (a *argsValidatedPtr) Validate() error {
+	if a.Times <= 0 {
+		return errors.New("times must be positive")
+	}
+	return nil
+}
+
+func TestDecodeArgs(t *testing.T) {
+	args, fields := decodeArgs[argsTest](map[string]any{
+		"url":     "http://127.0.0.1",
+		"times":   float64(3),
+		"queue":   "default",
+		"headers": map[string]any{"a": "1"},
+		"ids":     []any{float64(1), float64(2)},
+	})
+	assert.Empty(t, fields)
+	assert.Equal(t, "http://127.0.0.1", args.Url)
+	assert.Equal(t, 3, args.Times)
+	assert.Equal(t, "default", args.Queue)
+	assert.Equal(t, map[string]string{"a": "1"}, args.Headers)
+	assert.Equal(t, []int{1, 2}, args.Ids)
+	assert.Nil(t, args.At)
+}
+
+func TestDecodeArgsError(t *testing.T) {
+	_, fields := decodeArgs[argsTest](map[string]any{"times": 1.5, "other": 1})
+	assert.Equal(t, []ArgsFieldError{
+		{Field: "other", Description: "is unknown"},
+		{Field: "url", Description: "is required"},
+	}, fields)
+
+	_, fields = decodeArgs[argsTest](map[string]any{"url": "", "times": 1.5})
+	assert.Equal(t, []ArgsFieldError{{Field: "times", Description: "must be integer, not number 1.5"}}, fields)
+
+	_, fields = decodeArgs[argsTest](nil)
+	assert.Len(t, fields, 2)
+}
+
+func TestDecodeArgsValidate(t *testing.T) {
+	_, fields := decodeArgs[argsValidated](map[string]any{"times": 1})
+	assert.Empty(t, fields)
+	_, fields = decodeArgs[argsValidated](map[string]any{"times": 0})
+	assert.Equal(t, []ArgsFieldError{{Field: "times", Description: "must be positive"}}, fields)
+
+	_, fields = decodeArgs[argsValidatedPtr](map[string]any{"times": 0})
+	assert.Equal(t, []ArgsFieldError{{Description: "times must be positive"}}, fields)
+}
+
+func TestArgsSchema(t *testing.T) {
+	s := argsSchema[argsTest]("func")
+
+	assert.Equal(t, "func", s["title"])
+	assert.Equal(t, "object", s["type"])
+	assert.Equal(t, false, s["additionalProperties"])
+	assert.Equal(t, []any{"url", "times"}, s["required"])
+	assert.Equal(t, map[string]any{
+		"queue":   map[string]any{"type": "string"},
+		"url":     map[string]any{"type": "string", "description": "Target URL"},
+		"times":   map[string]any{"type": "integer"},
+		"rate":    map[string]any{"type": "number"},
+		"headers": map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+		"ids":     map[string]any{"type": "array", "items": map[string]any{"type": "integer"}},
+		"at":      map[string]any{"type": "string", "format": "date-time"},
+	}, s["properties"])
+}
+
+func TestArgsSchemaMap(t *testing.T) {
+	s := argsSchema[map[string]any]("func")
+
+	assert.Equal(t, "object", s["type"])
+	assert.Equal(t, map[string]any{}, s["additionalProperties"])
+}
+
+func TestArgsDoc(t *testing.T) {
+	args := argsDoc[*argsTest]()
+
+	assert.Len(t, args, 7)
+	assert.Equal(t, FuncArg{Name: "queue", Type: "string"}, args[0])
+	assert.Equal(t, FuncArg{Name: "url", Type: "string", Description: "Target URL", Required: true}, args[1])
+	assert.Equal(t, FuncArg{Name: "at", Type: "string"}, args[6])
+
+	assert.Nil(t, argsDoc[map[string]any]())
+}
@@ -0,0 +1,642 @@
+package agscheduler
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/kurtloong/agscheduler/services/proto"
+)
+
+// The most excluded run times skipped in a row, before giving up the next run time.
+const CALENDAR_MAX_SKIPS = 1000
+
+// The days matching all the non-empty fields are excluded,
+// such as `{Months: [12], Days: [25]}` for Christmas or `{Weekdays: [0, 6]}` for weekends.
+type CalendarRule struct {
+	// 1 ~ 12
+	Months []int `json:"months"`
+	// 1 ~ 31, -1 is the last day of the month.
+	Days []int `json:"days"`
+	// 0 ~ 6, from Sunday.
+	Weekdays []int `json:"weekdays"`
+}
+
+// A time-of-day window excluded on the given weekdays, or every day.
+// When `End` is not after `Start`, the window ends on the next day.
+type CalendarWindow struct {
+	// 0 ~ 6, from Sunday, of the day the window starts.
+	Weekdays []int `json:"weekdays"`
+	// `15:04` or `15:04:05`
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// An excluded period, such as a timed event imported from an ICS file.
+type CalendarPeriod struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Named exclusion rules, jobs do not run at the times they exclude.
+// The next run time of a job referencing calendars skips over them.
+type Calendar struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Refer to `time.LoadLocation`, the dates, rules and windows are in it.
+	// Default: the timezone of the job
+	Timezone string `json:"timezone"`
+	// Excluded dates, `2006-01-02`.
+	Dates   []string         `json:"dates"`
+	Rules   []CalendarRule   `json:"rules"`
+	Windows []CalendarWindow `json:"windows"`
+	Periods []CalendarPeriod `json:"periods"`
+}
+
+func parseTimeOfDay(s string) (time.Duration, error) {
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second, nil
+		}
+	}
+
+	return 0, fmt.Errorf("time of day `%s` must be `15:04` or `15:04:05`", s)
+}
+
+func checkRange(name string, vs []int, min int, max int, extra ...int) error {
+	for _, v := range vs {
+		if (v < min || v > max) && !slices.Contains(extra, v) {
+			return fmt.Errorf("%s `%d` out of range %d ~ %d", name, v, min, max)
+		}
+	}
+
+	return nil
+}
+
+// Called when the scheduler run `SetCalendar`.
+func (c *Calendar) check() error {
+	if c.Name == "" {
+		return errors.New("calendar Name is required")
+	}
+
+	if c.Timezone != "" {
+		if _, err := loadLocation(c.Timezone); err != nil {
+			return fmt.Errorf("calendar `%s` Timezone `%s` error: %s", c.Name, c.Timezone, err)
+		}
+	}
+
+	for _, d := range c.Dates {
+		if _, err := time.Parse(time.DateOnly, d); err != nil {
+			return fmt.Errorf("calendar `%s` Dates `%s` error: %s", c.Name, d, err)
+		}
+	}
+
+	for _, r := range c.Rules {
+		if len(r.Months) == 0 && len(r.Days) == 0 && len(r.Weekdays) == 0 {
+			return fmt.Errorf("calendar `%s` Rules error: a rule must not be empty", c.Name)
+		}
+		err := errors.Join(
+			checkRange("month", r.Months, 1, 12),
+			checkRange("day", r.Days, 1, 31, -1),
+			checkRange("weekday", r.Weekdays, 0, 6),
+		)
+		if err != nil {
+			return fmt.Errorf("calendar `%s` Rules error: %s", c.Name, err)
+		}
+	}
+
+	for _, w := range c.Windows {
+		_, errStart := parseTimeOfDay(w.Start)
+		_, errEnd := parseTimeOfDay(w.End)
+		if err := errors.Join(errStart, errEnd, checkRange("weekday", w.Weekdays, 0, 6)); err != nil {
+			return fmt.Errorf("calendar `%s` Windows error: %s", c.Name, err)
+		}
+	}
+
+	for _, p := range c.Periods {
+		if !p.End.After(p.Start) {
+			return fmt.Errorf("calendar `%s` Periods error: End `%s` must be after Start `%s`", c.Name, p.End, p.Start)
+		}
+	}
+
+	return nil
+}
+
+func (r CalendarRule) matches(t time.Time) bool {
+	if len(r.Months) > 0 && !slices.Contains(r.Months, int(t.Month())) {
+		return false
+	}
+	if len(r.Weekdays) > 0 && !slices.Contains(r.Weekdays, int(t.Weekday())) {
+		return false
+	}
+	if len(r.Days) > 0 {
+		isLastDay := t.AddDate(0, 0, 1).Day() == 1
+		if !slices.Contains(r.Days, t.Day()) && !(isLastDay && slices.Contains(r.Days, -1)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Returns the end of the exclusion `t` is in, when `t` is excluded.
+// `loc` is used when the calendar has no timezone.
+func (c Calendar) excludedUntil(t time.Time, loc *time.Location) (time.Time, bool) {
+	if c.Timezone != "" {
+		loc, _ = loadLocation(c.Timezone)
+	}
+	t = t.In(loc)
+	y, m, d := t.Date()
+	nextDayStart := time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+
+	if slices.Contains(c.Dates, t.Format(time.DateOnly)) {
+		return nextDayStart, true
+	}
+	for _, r := range c.Rules {
+		if r.matches(t) {
+			return nextDayStart, true
+		}
+	}
+
+	if end, ok := windowsUntil(c.Windows, t, loc); ok {
+		return end, true
+	}
+
+	for _, p := range c.Periods {
+		if !t.Before(p.Start) && t.Before(p.End) {
+			return p.End, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// Returns the end of the exclusion `t` is in, when any of the calendars excludes `t`.
+func calendarsExcludedUntil(t time.Time, loc *time.Location, calendars []Calendar) (time.Time, bool) {
+	for _, c := range calendars {
+		if until, ok := c.excludedUntil(t, loc); ok {
+			return until, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// Unfold the content lines of an ICS file.
+func icsLines(r io.Reader) ([]string, error) {
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines, scanner.Err()
+}
+
+// Parse an ICS date or date-time value, such as `20240101`, `20240101T090000Z`,
+// or `20240101T090000` with the `TZID` parameter or in `loc`.
+func parseICSTime(params map[string]string, value string, loc *time.Location) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == 8 {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+	if tzid, ok := params["TZID"]; ok {
+		tzLoc, err := loadLocation(tzid)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		loc = tzLoc
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+
+	return t, false, err
+}
+
+var icsWeekdays = map[string]int{"SU": 0, "MO": 1, "TU": 2, "WE": 3, "TH": 4, "FR": 5, "SA": 6}
+
+// Import the events of an ICS file as the exclusions of a calendar.
+//
+// All-day events exclude their dates, timed events exclude their periods.
+// Recurring events are supported for `RRULE:FREQ=YEARLY` all-day events,
+// `RRULE:FREQ=WEEKLY` events with optional `BYDAY`, and `RRULE:FREQ=DAILY` timed events.
+func ParseICS(name string, r io.Reader) (Calendar, error) {
+	lines, err := icsLines(r)
+	if err != nil {
+		return Calendar{}, err
+	}
+
+	c := Calendar{Name: name}
+	loc := time.UTC
+	var event map[string]string
+	var eventParams map[string]map[string]string
+	for i, line := range lines {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return Calendar{}, fmt.Errorf("ICS line %d `%s` error: missing `:`", i+1, line)
+		}
+		parts := strings.Split(key, ";")
+		prop := strings.ToUpper(parts[0])
+		params := make(map[string]string)
+		for _, p := range parts[1:] {
+			k, v, _ := strings.Cut(p, "=")
+			params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+
+		switch {
+		case prop == "X-WR-CALNAME" && event == nil:
+			c.Description = value
+		case prop == "X-WR-TIMEZONE" && event == nil:
+			if loc, err = loadLocation(value); err != nil {
+				return Calendar{}, fmt.Errorf("ICS line %d `%s` error: %s", i+1, line, err)
+			}
+			c.Timezone = value
+		case prop == "BEGIN" && value == "VEVENT":
+			event = make(map[string]string)
+			eventParams = make(map[string]map[string]string)
+		case prop == "END" && value == "VEVENT" && event != nil:
+			if err := c.addICSEvent(event, eventParams, loc); err != nil {
+				return Calendar{}, fmt.Errorf("ICS event ending at line %d error: %s", i+1, err)
+			}
+			event = nil
+		case event != nil:
+			event[prop] = value
+			eventParams[prop] = params
+		}
+	}
+
+	if err := c.check(); err != nil {
+		return Calendar{}, err
+	}
+
+	return c, nil
+}
+
+func (c *Calendar) addICSEvent(event map[string]string, params map[string]map[string]string, loc *time.Location) error {
+	start, isDate, err := parseICSTime(params["DTSTART"], event["DTSTART"], loc)
+	if err != nil {
+		return fmt.Errorf("DTSTART `%s` error: %s", event["DTSTART"], err)
+	}
+	end := start
+	if isDate {
+		end = start.AddDate(0, 0, 1)
+	}
+	if event["DTEND"] != "" {
+		if end, _, err = parseICSTime(params["DTEND"], event["DTEND"], loc); err != nil {
+			return fmt.Errorf("DTEND `%s` error: %s", event["DTEND"], err)
+		}
+	}
+	if !end.After(start) {
+		return nil
+	}
+
+	if rrule := event["RRULE"]; rrule != "" {
+		return c.addICSRRule(rrule, start, end, isDate)
+	}
+
+	if !isDate {
+		c.Periods = append(c.Periods, CalendarPeriod{Start: start.UTC(), End: end.UTC()})
+		return nil
+	}
+	// `DTEND` of all-day events is exclusive.
+	for d := start; d.Before(end) && len(c.Dates) < 10000; d = d.AddDate(0, 0, 1) {
+		c.Dates = append(c.Dates, d.Format(time.DateOnly))
+	}
+
+	return nil
+}
+
+func (c *Calendar) addICSRRule(rrule string, start time.Time, end time.Time, isDate bool) error {
+	rule := make(map[string]string)
+	for _, part := range strings.Split(rrule, ";") {
+		k, v, _ := strings.Cut(part, "=")
+		rule[strings.ToUpper(k)] = strings.ToUpper(v)
+	}
+	for k, v := range rule {
+		if k != "FREQ" && k != "BYDAY" && !(k == "INTERVAL" && v == "1") {
+			return fmt.Errorf("RRULE `%s` error: `%s` unsupported", rrule, k)
+		}
+	}
+
+	weekdays := []int{int(start.Weekday())}
+	if rule["BYDAY"] != "" {
+		weekdays = nil
+		for _, day := range strings.Split(rule["BYDAY"], ",") {
+			wd, ok := icsWeekdays[day]
+			if !ok {
+				return fmt.Errorf("RRULE `%s` error: BYDAY `%s` unsupported", rrule, day)
+			}
+			weekdays = append(weekdays, wd)
+		}
+	}
+
+	switch {
+	case rule["FREQ"] == "YEARLY" && isDate && rule["BYDAY"] == "":
+		c.Rules = append(c.Rules, CalendarRule{Months: []int{int(start.Month())}, Days: []int{start.Day()}})
+	case rule["FREQ"] == "WEEKLY" && isDate:
+		c.Rules = append(c.Rules, CalendarRule{Weekdays: weekdays})
+	case (rule["FREQ"] == "WEEKLY" || rule["FREQ"] == "DAILY") && !isDate && end.Sub(start) < 24*time.Hour:
+		w := CalendarWindow{Start: start.Format(time.TimeOnly), End: end.In(start.Location()).Format(time.TimeOnly)}
+		if rule["FREQ"] == "WEEKLY" {
+			w.Weekdays = weekdays
+		}
+		c.Windows = append(c.Windows, w)
+	default:
+		return fmt.Errorf("RRULE `%s` error: unsupported for this event", rrule)
+	}
+
+	return nil
+}
+
+// Keeps the calendars of a scheduler whose store does not implement `CalendarStore`.
+type memoryCalendars struct {
+	mu sync.Mutex
+
+	calendars []Calendar
+}
+
+func (mc *memoryCalendars) SetCalendar(c Calendar) error {
+	defer mc.mu.Unlock()
+
+	mc.mu.Lock()
+
+	for i, sc := range mc.calendars {
+		if sc.Name == c.Name {
+			mc.calendars[i] = c
+			return nil
+		}
+	}
+
+	mc.calendars = append(mc.calendars, c)
+	return nil
+}
+
+func (mc *memoryCalendars) GetCalendar(name string) (Calendar, error) {
+	defer mc.mu.Unlock()
+
+	mc.mu.Lock()
+
+	for _, c := range mc.calendars {
+		if c.Name == name {
+			return c, nil
+		}
+	}
+	return Calendar{}, CalendarNotFoundError(name)
+}
+
+func (mc *memoryCalendars) GetAllCalendars() ([]Calendar, error) {
+	defer mc.mu.Unlock()
+
+	mc.mu.Lock()
+
+	return slices.Clone(mc.calendars), nil
+}
+
+func (mc *memoryCalendars) DeleteCalendar(name string) error {
+	defer mc.mu.Unlock()
+
+	mc.mu.Lock()
+
+	for i, c := range mc.calendars {
+		if c.Name == name {
+			mc.calendars = slices.Delete(mc.calendars, i, i+1)
+			return nil
+		}
+	}
+	return CalendarNotFoundError(name)
+}
+
+// Returns the store when it implements `CalendarStore`, otherwise the calendars in process.
+func (s *Scheduler) calendarStore() CalendarStore {
+	if cs, ok := s.store.(CalendarStore); ok {
+		return cs
+	}
+
+	return &s.calendars
+}
+
+// Returns the calendars of the names.
+func (s *Scheduler) getCalendars(names []string) ([]Calendar, error) {
+	calendars := make([]Calendar, 0, len(names))
+	for _, name := range names {
+		c, err := s.calendarStore().GetCalendar(name)
+		if err != nil {
+			return nil, err
+		}
+		calendars = append(calendars, c)
+	}
+
+	return calendars, nil
+}
+
+// Calculate the next run time of the job, skipping over its calendars.
+func (s *Scheduler) nextRunTime(j Job) (time.Time, error) {
+	calendars, err := s.getCalendars(j.Calendars)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("job `%s` Calendars error: %w", j.FullName(), err)
+	}
+
+	return CalcNextRunTime(j, calendars...)
+}
+
+// Add a calendar, or replace the one with the same name,
+// the next run times of the jobs referencing it are calculated again.
+func (s *Scheduler) SetCalendar(c Calendar) (Calendar, error) {
+	if err := c.check(); err != nil {
+		return Calendar{}, err
+	}
+
+	slog.Info(fmt.Sprintf("Scheduler set calendar `%s`.\n", c.Name))
+
+	if err := s.calendarStore().SetCalendar(c); err != nil {
+		return Calendar{}, err
+	}
+
+	js, err := s.GetAllJobs()
+	if err != nil {
+		return Calendar{}, err
+	}
+	for _, j := range js {
+		if slices.Contains(j.Calendars, c.Name) {
+			if _, err := s.UpdateJob(j); err != nil {
+				return Calendar{}, err
+			}
+		}
+	}
+
+	return c, nil
+}
+
+func (s *Scheduler) GetCalendar(name string) (Calendar, error) {
+	return s.calendarStore().GetCalendar(name)
+}
+
+func (s *Scheduler) GetAllCalendars() ([]Calendar, error) {
+	return s.calendarStore().GetAllCalendars()
+}
+
+// Calendars referenced by jobs can not be deleted.
+func (s *Scheduler) DeleteCalendar(name string) error {
+	slog.Info(fmt.Sprintf("Scheduler delete calendar `%s`.\n", name))
+
+	if _, err := s.GetCalendar(name); err != nil {
+		return err
+	}
+
+	js, err := s.GetAllJobs()
+	if err != nil {
+		return err
+	}
+	for _, j := range js {
+		if slices.Contains(j.Calendars, name) {
+			return fmt.Errorf("calendar `%s` is used by job `%s`", name, j.FullName())
+		}
+	}
+
+	return s.calendarStore().DeleteCalendar(name)
+}
+
+// Parse an ICS file by `ParseICS` and set it as the calendar `name`.
+func (s *Scheduler) ImportCalendarICS(name string, r io.Reader) (Calendar, error) {
+	c, err := ParseICS(name, r)
+	if err != nil {
+		return Calendar{}, err
+	}
+
+	return s.SetCalendar(c)
+}
+
+func intsToInt32s(vs []int) []int32 {
+	var vs32 []int32
+	for _, v := range vs {
+		vs32 = append(vs32, int32(v))
+	}
+
+	return vs32
+}
+
+func int32sToInts(vs32 []int32) []int {
+	var vs []int
+	for _, v := range vs32 {
+		vs = append(vs, int(v))
+	}
+
+	return vs
+}
+
+func calendarWindowsToPbCalendarWindowsPtr(ws []CalendarWindow) []*pb.CalendarWindow {
+	var pbWs []*pb.CalendarWindow
+	for _, w := range ws {
+		pbWs = append(pbWs, &pb.CalendarWindow{
+			Weekdays: intsToInt32s(w.Weekdays),
+			Start:    w.Start,
+			End:      w.End,
+		})
+	}
+
+	return pbWs
+}
+
+func pbCalendarWindowsPtrToCalendarWindows(pbWs []*pb.CalendarWindow) []CalendarWindow {
+	var ws []CalendarWindow
+	for _, pbW := range pbWs {
+		ws = append(ws, CalendarWindow{
+			Weekdays: int32sToInts(pbW.GetWeekdays()),
+			Start:    pbW.GetStart(),
+			End:      pbW.GetEnd(),
+		})
+	}
+
+	return ws
+}
+
+// Used to gRPC Protobuf
+func CalendarToPbCalendarPtr(c Calendar) *pb.Calendar {
+	pbC := &pb.Calendar{
+		Name:        c.Name,
+		Description: c.Description,
+		Timezone:    c.Timezone,
+		Dates:       c.Dates,
+	}
+	for _, r := range c.Rules {
+		pbC.Rules = append(pbC.Rules, &pb.CalendarRule{
+			Months:   intsToInt32s(r.Months),
+			Days:     intsToInt32s(r.Days),
+			Weekdays: intsToInt32s(r.Weekdays),
+		})
+	}
+	pbC.Windows = calendarWindowsToPbCalendarWindowsPtr(c.Windows)
+	for _, p := range c.Periods {
+		pbC.Periods = append(pbC.Periods, &pb.CalendarPeriod{
+			Start: timestamppb.New(p.Start),
+			End:   timestamppb.New(p.End),
+		})
+	}
+
+	return pbC
+}
+
+// Used to gRPC Protobuf
+func PbCalendarPtrToCalendar(pbC *pb.Calendar) Calendar {
+	c := Calendar{
+		Name:        pbC.GetName(),
+		Description: pbC.GetDescription(),
+		Timezone:    pbC.GetTimezone(),
+		Dates:       pbC.GetDates(),
+	}
+	for _, r := range pbC.GetRules() {
+		c.Rules = append(c.Rules, CalendarRule{
+			Months:   int32sToInts(r.GetMonths()),
+			Days:     int32sToInts(r.GetDays()),
+			Weekdays: int32sToInts(r.GetWeekdays()),
+		})
+	}
+	c.Windows = pbCalendarWindowsPtrToCalendarWindows(pbC.GetWindows())
+	for _, p := range pbC.GetPeriods() {
+		c.Periods = append(c.Periods, CalendarPeriod{
+			Start: p.GetStart().AsTime(),
+			End:   p.GetEnd().AsTime(),
+		})
+	}
+
+	return c
+}
+
+// Used to gRPC Protobuf
+func CalendarsToPbCalendarsPtr(cs []Calendar) *pb.Calendars {
+	pbCs := &pb.Calendars{}
+	for _, c := range cs {
+		pbCs.Calendars = append(pbCs.Calendars, CalendarToPbCalendarPtr(c))
+	}
+
+	return pbCs
+}
+
+// Used to gRPC Protobuf
+func PbCalendarsPtrToCalendars(pbCs *pb.Calendars) []Calendar {
+	cs := make([]Calendar, 0, len(pbCs.GetCalendars()))
+	for _, pbC := range pbCs.GetCalendars() {
+		cs = append(cs, PbCalendarPtrToCalendar(pbC))
+	}
+
+	return cs
+}
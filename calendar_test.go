@@ -0,0 +1,190 @@
+package agscheduler
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getCalendar() Calendar {
+	return Calendar{
+		Name:     "finance",
+		Timezone: "Asia/Shanghai",
+		Dates:    []string{"2024-01-01"},
+		Rules: []CalendarRule{
+			{Months: []int{12}, Days: []int{25}},
+			{Months: []int{2}, Days: []int{-1}},
+		},
+		Windows: []CalendarWindow{
+			{Weekdays: []int{6}, Start: "22:00", End: "02:00"},
+			{Start: "12:00", End: "13:00"},
+		},
+		Periods: []CalendarPeriod{
+			{Start: time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC), End: time.Date(2024, 3, 5, 6, 0, 0, 0, time.UTC)},
+		},
+	}
+}
+
+func TestCalendarCheck(t *testing.T) {
+	c := getCalendar()
+	assert.NoError(t, c.check())
+
+	for _, f := range []func(c *Calendar){
+		func(c *Calendar) { c.Name = "" },
+		func(c *Calendar) { c.Timezone = "unknown" },
+		func(c *Calendar) { c.Dates = []string{"2024-13-01"} },
+		func(c *Calendar) { c.Rules = []CalendarRule{{}} },
+		func(c *Calendar) { c.Rules = []CalendarRule{{Months: []int{13}}} },
+		func(c *Calendar) { c.Rules = []CalendarRule{{Days: []int{0}}} },
+		func(c *Calendar) { c.Windows = []CalendarWindow{{Start: "25:00", End: "01:00"}} },
+		func(c *Calendar) { c.Windows = []CalendarWindow{{Weekdays: []int{7}, Start: "01:00", End: "02:00"}} },
+		func(c *Calendar) { c.Periods = []CalendarPeriod{{Start: time.Now(), End: time.Now().Add(-time.Hour)}} },
+	} {
+		c := getCalendar()
+		f(&c)
+		assert.Error(t, c.check())
+	}
+}
+
+func TestCalendarExcludedUntil(t *testing.T) {
+	c := getCalendar()
+	loc, _ := time.LoadLocation("Asia/Shanghai")
+	date := func(y int, m time.Month, d int, h int, min int) time.Time {
+		return time.Date(y, m, d, h, min, 0, 0, loc)
+	}
+
+	for at, until := range map[time.Time]time.Time{
+		date(2024, 1, 1, 9, 0):    date(2024, 1, 2, 0, 0),
+		date(2024, 12, 25, 0, 0):  date(2024, 12, 26, 0, 0),
+		date(2024, 2, 29, 23, 59): date(2024, 3, 1, 0, 0),
+		// Saturday
+		date(2024, 1, 6, 23, 0): date(2024, 1, 7, 2, 0),
+		date(2024, 1, 7, 1, 0):  date(2024, 1, 7, 2, 0),
+		date(2024, 1, 8, 12, 0): date(2024, 1, 8, 13, 0),
+		date(2024, 3, 5, 9, 0):  date(2024, 3, 5, 14, 0),
+	} {
+		u, ok := c.excludedUntil(at, time.UTC)
+		assert.True(t, ok, at)
+		assert.True(t, until.Equal(u), at)
+	}
+
+	for _, at := range []time.Time{
+		date(2024, 1, 2, 9, 0),
+		date(2024, 2, 28, 9, 0),
+		// Sunday
+		date(2024, 1, 7, 23, 0),
+		date(2024, 1, 8, 13, 0),
+	} {
+		_, ok := c.excludedUntil(at, time.UTC)
+		assert.False(t, ok, at)
+	}
+
+	// The timezone of the job is used when the calendar has none.
+	c = Calendar{Name: "c", Dates: []string{"2024-01-01"}}
+	_, ok := c.excludedUntil(time.Date(2023, 12, 31, 20, 0, 0, 0, time.UTC), loc)
+	assert.True(t, ok)
+	_, ok = c.excludedUntil(time.Date(2023, 12, 31, 20, 0, 0, 0, time.UTC), time.UTC)
+	assert.False(t, ok)
+}
+
+func TestCalcNextRunTimeCalendars(t *testing.T) {
+	c := Calendar{
+		Name:    "c",
+		Dates:   []string{"2024-01-01", "2024-01-02"},
+		Windows: []CalendarWindow{{Start: "00:00", End: "08:30"}},
+	}
+	from := time.Date(2023, 12, 31, 12, 0, 0, 0, time.UTC)
+
+	j := Job{Type: TYPE_CRON, CronExpr: "0 * * * *", Timezone: "UTC"}
+	nextRunTime, err := calcNextRunTime(j, from, []Calendar{c})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 12, 31, 13, 0, 0, 0, time.UTC), nextRunTime)
+	nextRunTime, err = calcNextRunTime(j, time.Date(2023, 12, 31, 23, 30, 0, 0, time.UTC), []Calendar{c})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC), nextRunTime)
+
+	j = Job{Type: TYPE_INTERVAL, Interval: "20m", Timezone: "UTC"}
+	nextRunTime, err = calcNextRunTime(j, time.Date(2024, 1, 2, 23, 50, 0, 0, time.UTC), []Calendar{c})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 3, 8, 30, 0, 0, time.UTC), nextRunTime)
+
+	j = Job{Type: TYPE_DATETIME, StartAt: "2024-01-01 09:00:00", Timezone: "UTC"}
+	nextRunTime, err = calcNextRunTime(j, from, []Calendar{c})
+	assert.NoError(t, err)
+	assert.Equal(t, 9999, nextRunTime.Year())
+
+	c.Windows = []CalendarWindow{{Start: "00:00", End: "00:00"}}
+	j = Job{Name: "Job", Type: TYPE_CRON, CronExpr: "0 * * * *", Timezone: "UTC"}
+	_, err = calcNextRunTime(j, from, []Calendar{c})
+	scheduleErr := &JobScheduleError{}
+	assert.ErrorAs(t, err, &scheduleErr)
+	assert.Equal(t, "calendars", scheduleErr.Field)
+}
+
+func TestParseICS(t *testing.T) {
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"X-WR-CALNAME:Holidays",
+		"X-WR-TIMEZONE:Asia/Shanghai",
+		"BEGIN:VEVENT",
+		"SUMMARY:New Year",
+		"DTSTART;VALUE=DATE:20240101",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"SUMMARY:Spring Festival",
+		"DTSTART;VALUE=DATE:20240210",
+		"DTEND;VALUE=DATE:20240213",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"SUMMARY:Christmas",
+		"DTSTART;VALUE=DATE:20241225",
+		"RRULE:FREQ=YEARLY",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"SUMMARY:Maintenance",
+		"DTSTART;TZID=Europe/Berlin:20240106T220000",
+		"DTEND;TZID=Europe/Berlin:20240106T235959",
+		"RRULE:FREQ=WEEKLY;BYDAY=SA,",
+		" SU",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"SUMMARY:Release",
+		"DTSTART:20240301T010000Z",
+		"DTEND:20240301T030000Z",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	c, err := ParseICS("holidays", strings.NewReader(ics))
+	assert.NoError(t, err)
+	assert.Equal(t, "holidays", c.Name)
+	assert.Equal(t, "Holidays", c.Description)
+	assert.Equal(t, "Asia/Shanghai", c.Timezone)
+	assert.Equal(t, []string{"2024-01-01", "2024-02-10", "2024-02-11", "2024-02-12"}, c.Dates)
+	assert.Equal(t, []CalendarRule{{Months: []int{12}, Days: []int{25}}}, c.Rules)
+	assert.Equal(t, []CalendarWindow{{Weekdays: []int{6, 0}, Start: "22:00:00", End: "23:59:59"}}, c.Windows)
+	assert.Equal(t, []CalendarPeriod{{
+		Start: time.Date(2024, 3, 1, 1, 0, 0, 0, time.UTC),
+		End:   time.Date(2024, 3, 1, 3, 0, 0, 0, time.UTC),
+	}}, c.Periods)
+
+	for _, ics := range []string{
+		"BEGIN:VEVENT\nDTSTART;VALUE=DATE:2024\nEND:VEVENT",
+		"BEGIN:VEVENT\nDTSTART;VALUE=DATE:20240101\nRRULE:FREQ=MONTHLY\nEND:VEVENT",
+		"BEGIN:VEVENT\nDTSTART;VALUE=DATE:20240101\nRRULE:FREQ=YEARLY;COUNT=3\nEND:VEVENT",
+		"X-WR-TIMEZONE:unknown",
+		"no colon",
+	} {
+		_, err := ParseICS("c", strings.NewReader(ics))
+		assert.Error(t, err, ics)
+	}
+}
+
+func TestCalendarToPbCalendarPtr(t *testing.T) {
+	c := getCalendar()
+
+	assert.Equal(t, c, PbCalendarPtrToCalendar(CalendarToPbCalendarPtr(c)))
+	assert.Equal(t, []Calendar{c}, PbCalendarsPtrToCalendars(CalendarsToPbCalendarsPtr([]Calendar{c})))
+}
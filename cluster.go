@@ -14,8 +14,6 @@ import (
 	"github.com/google/uuid"
 )
 
-var mutexC sync.Mutex
-
 type Node struct {
 	Id                string
 	MainEndpoint      string
@@ -24,6 +22,22 @@ type Node struct {
 	SchedulerEndpoint string
 	Queue             string
 	NodeMap           map[string]map[string]map[string]any
+	// Whether the scheduler of the cluster is paused,
+	// synchronized from the main node.
+	Paused bool
+	// Names of the functions registered on this node.
+	Funcs []string
+	// The capacity of the pool executing the runs of this node.
+	Pool PoolStats
+}
+
+// Reported by a worker node to the main node, when a run of a job
+// in a workflow run or with chained jobs finishes.
+type JobFinished struct {
+	Job Job
+	// Optional: `RECORD_SUCCEEDED` | `RECORD_FAILED` | `RECORD_TIMEOUT` | `RECORD_CANCELED`
+	Status string
+	Result map[string]any
 }
 
 func (n *Node) toClusterNode() *ClusterNode {
@@ -36,6 +50,8 @@ func (n *Node) toClusterNode() *ClusterNode {
 		Queue:             n.Queue,
 
 		nodeMap: n.NodeMap,
+		funcs:   n.Funcs,
+		pool:    n.Pool,
 	}
 }
 
@@ -75,6 +91,23 @@ type ClusterNode struct {
 
 	// Bind to each other and the scheduler.
 	Scheduler *Scheduler
+
+	// Stop the background goroutines of this node, called by `Shutdown`.
+	cancels []context.CancelFunc
+	// The pings to the main node in flight, `Shutdown` deregisters after them,
+	// so that a late ping does not register this node again.
+	pings sync.WaitGroup
+
+	// Names of the functions registered on a remote node.
+	// The local node uses the function registry of its scheduler.
+	funcs []string
+	// The pool of a remote node.
+	// The local node uses the pool of its scheduler.
+	pool PoolStats
+
+	// Guard the state of this node,
+	// so that nodes in the same process do not block each other.
+	mu sync.Mutex
 }
 
 func (cn *ClusterNode) toNode() *Node {
@@ -86,20 +119,78 @@ func (cn *ClusterNode) toNode() *Node {
 		SchedulerEndpoint: cn.SchedulerEndpoint,
 		Queue:             cn.Queue,
 		NodeMap:           cn.NodeMap(),
+		Paused:            cn.isPaused(),
+		Funcs:             cn.funcNames(),
+		Pool:              cn.poolStats(),
+	}
+}
+
+// Returns a context that is canceled when the node shuts down.
+func (cn *ClusterNode) withShutdown(ctx context.Context) context.Context {
+	defer cn.mu.Unlock()
+
+	cn.mu.Lock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	cn.cancels = append(cn.cancels, cancel)
+
+	return ctx
+}
+
+func (cn *ClusterNode) funcNames() []string {
+	if cn.Scheduler == nil {
+		return cn.funcs
+	}
+
+	return cn.Scheduler.funcNames()
+}
+
+func (cn *ClusterNode) poolStats() PoolStats {
+	if cn.Scheduler == nil {
+		return cn.pool
+	}
+
+	return cn.Scheduler.PoolStats()
+}
+
+func (cn *ClusterNode) isMain() bool {
+	return cn.MainEndpoint == cn.Endpoint
+}
+
+func (cn *ClusterNode) isPaused() bool {
+	if cn.Scheduler == nil {
+		return false
+	}
+
+	return cn.Scheduler.IsPaused()
+}
+
+// Used for worker node
+//
+// Keep the scheduler paused state consistent with the main node.
+func (cn *ClusterNode) syncPaused(paused bool) {
+	if cn.Scheduler == nil || cn.isPaused() == paused {
+		return
+	}
+
+	if paused {
+		cn.Scheduler.Pause()
+	} else {
+		cn.Scheduler.Resume()
 	}
 }
 
 func (cn *ClusterNode) setNodeMap(nmap map[string]map[string]map[string]any) {
-	defer mutexC.Unlock()
+	defer cn.mu.Unlock()
 
-	mutexC.Lock()
+	cn.mu.Lock()
 	cn.nodeMap = nmap
 }
 
 func (cn *ClusterNode) NodeMap() map[string]map[string]map[string]any {
-	defer mutexC.Unlock()
+	defer cn.mu.Unlock()
 
-	mutexC.Lock()
+	cn.mu.Lock()
 	return cn.nodeMap
 }
 
@@ -129,8 +220,8 @@ func (cn *ClusterNode) init(ctx context.Context) error {
 	cn.setId()
 	cn.registerNode(cn)
 
-	if cn.MainEndpoint == cn.Endpoint {
-		go cn.checkNode(ctx)
+	if cn.isMain() {
+		go cn.checkNode(cn.withShutdown(ctx))
 	}
 
 	return nil
@@ -138,9 +229,9 @@ func (cn *ClusterNode) init(ctx context.Context) error {
 
 // Register node with the cluster.
 func (cn *ClusterNode) registerNode(n *ClusterNode) {
-	defer mutexC.Unlock()
+	defer cn.mu.Unlock()
 
-	mutexC.Lock()
+	cn.mu.Lock()
 
 	if cn.nodeMap == nil {
 		cn.nodeMap = make(map[string]map[string]map[string]any)
@@ -153,7 +244,7 @@ func (cn *ClusterNode) registerNode(n *ClusterNode) {
 	if register_time == nil {
 		register_time = now
 	}
-	cn.nodeMap[n.Queue][n.Id] = map[string]any{
+	node := map[string]any{
 		"id":                  n.Id,
 		"main_endpoint":       n.MainEndpoint,
 		"endpoint":            n.Endpoint,
@@ -163,12 +254,17 @@ func (cn *ClusterNode) registerNode(n *ClusterNode) {
 		"health":              true,
 		"register_time":       register_time,
 		"last_heartbeat_time": now,
+		"funcs":               n.funcNames(),
 	}
+	n.poolStats().setTo(node)
+	cn.nodeMap[n.Queue][n.Id] = node
 }
 
 // Randomly select a healthy node from the cluster,
 // if you specify a queue, filter by queue.
-func (cn *ClusterNode) choiceNode(queues []string) (*ClusterNode, error) {
+// The nodes registering the function `funcName` are preferred,
+// then the nodes whose pools have free workers.
+func (cn *ClusterNode) choiceNode(queues []string, funcName string) (*ClusterNode, error) {
 	cns := make([]*ClusterNode, 0)
 	for q, v := range cn.NodeMap() {
 		if len(queues) != 0 && !slices.Contains(queues, q) {
@@ -178,17 +274,36 @@ func (cn *ClusterNode) choiceNode(queues []string) (*ClusterNode, error) {
 			if !v2["health"].(bool) {
 				continue
 			}
-			cns = append(cns, &ClusterNode{
+			fs, _ := v2["funcs"].([]string)
+			if id == cn.Id {
+				fs = cn.funcNames()
+			}
+			n := &ClusterNode{
 				Id:                id,
 				MainEndpoint:      v2["main_endpoint"].(string),
 				Endpoint:          v2["endpoint"].(string),
 				EndpointHTTP:      v2["endpoint_http"].(string),
 				SchedulerEndpoint: v2["scheduler_endpoint"].(string),
 				Queue:             v2["queue"].(string),
-			})
+
+				funcs: fs,
+				pool:  poolStatsFrom(v2),
+			}
+			cns = append(cns, n)
 		}
 	}
 
+	if funcName != "" {
+		withFunc := slices.DeleteFunc(slices.Clone(cns), func(n *ClusterNode) bool { return !slices.Contains(n.funcs, funcName) })
+		if len(withFunc) != 0 {
+			cns = withFunc
+		}
+	}
+	free := slices.DeleteFunc(slices.Clone(cns), func(n *ClusterNode) bool { return !n.pool.hasCapacity() })
+	if len(free) != 0 {
+		cns = free
+	}
+
 	cns_count := len(cns)
 	if cns_count != 0 {
 		rand.New(rand.NewSource(time.Now().UnixNano()))
@@ -219,14 +334,14 @@ func (cn *ClusterNode) checkNode(ctx context.Context) {
 					endpoint := v2["endpoint"].(string)
 					lastHeartbeatTime := v2["last_heartbeat_time"].(time.Time)
 					if now.Sub(lastHeartbeatTime) > 5*time.Minute {
-						mutexC.Lock()
+						cn.mu.Lock()
 						delete(v, id)
-						mutexC.Unlock()
+						cn.mu.Unlock()
 						slog.Warn(fmt.Sprintf("Cluster node `%s:%s` have been deleted because unhealthy", id, endpoint))
 					} else if now.Sub(lastHeartbeatTime) > 400*time.Millisecond {
-						mutexC.Lock()
+						cn.mu.Lock()
 						v2["health"] = false
-						mutexC.Unlock()
+						cn.mu.Unlock()
 					}
 				}
 			}
@@ -252,6 +367,7 @@ func (cn *ClusterNode) RPCRegister(args *Node, reply *Node) {
 	reply.Queue = cn.Queue
 
 	reply.NodeMap = cn.NodeMap()
+	reply.Paused = cn.isPaused()
 }
 
 // RPC API
@@ -259,6 +375,117 @@ func (cn *ClusterNode) RPCPing(args *Node, reply *Node) {
 	cn.registerNode(args.toClusterNode())
 
 	reply.NodeMap = cn.NodeMap()
+	reply.Paused = cn.isPaused()
+}
+
+// RPC API
+func (cn *ClusterNode) RPCDeregister(args *Node, reply *Node) {
+	slog.Info(fmt.Sprintf("Deregister from Cluster Node: `%s:%s`", args.Id, args.Endpoint))
+
+	cn.mu.Lock()
+	delete(cn.nodeMap[args.Queue], args.Id)
+	if len(cn.nodeMap[args.Queue]) == 0 {
+		delete(cn.nodeMap, args.Queue)
+	}
+	cn.mu.Unlock()
+
+	reply.NodeMap = cn.NodeMap()
+}
+
+// RPC API
+func (cn *ClusterNode) RPCPause(args *Node, reply *Node) {
+	slog.Info(fmt.Sprintf("Pause from Cluster Node: `%s:%s`", args.Id, args.Endpoint))
+
+	cn.Scheduler.Pause()
+
+	reply.Paused = cn.isPaused()
+}
+
+// RPC API
+func (cn *ClusterNode) RPCResume(args *Node, reply *Node) {
+	slog.Info(fmt.Sprintf("Resume from Cluster Node: `%s:%s`", args.Id, args.Endpoint))
+
+	cn.Scheduler.Resume()
+
+	reply.Paused = cn.isPaused()
+}
+
+// RPC API
+func (cn *ClusterNode) RPCJobFinished(args *JobFinished, reply *Node) {
+	cn.Scheduler.handleJobFinished(*args)
+}
+
+// Pause the scheduler of the entire cluster with one call.
+// A worker node forwards the call to the main node,
+// the other nodes synchronize the paused state through heartbeat.
+func (cn *ClusterNode) Pause() error {
+	if cn.isMain() {
+		cn.Scheduler.Pause()
+		return nil
+	}
+
+	return cn.callMainPaused("CRPCService.Pause")
+}
+
+// Resume the scheduler of the entire cluster with one call.
+func (cn *ClusterNode) Resume() error {
+	if cn.isMain() {
+		cn.Scheduler.Resume()
+		return nil
+	}
+
+	return cn.callMainPaused("CRPCService.Resume")
+}
+
+// Used for worker node
+//
+// Call the paused state API of the main node and synchronize the result.
+func (cn *ClusterNode) callMainPaused(serviceMethod string) error {
+	rClient, err := rpc.DialHTTP("tcp", cn.MainEndpoint)
+	if err != nil {
+		return fmt.Errorf("failed to connect to cluster main node: `%s`, error: %s", cn.MainEndpoint, err)
+	}
+	defer rClient.Close()
+
+	var main Node
+	ch := make(chan error, 1)
+	go func() { ch <- rClient.Call(serviceMethod, cn.toNode(), &main) }()
+	select {
+	case err := <-ch:
+		if err != nil {
+			return fmt.Errorf("failed to call `%s` to cluster main node, error: %s", serviceMethod, err)
+		}
+	case <-time.After(3 * time.Second):
+		return fmt.Errorf("call `%s` to cluster main node `%s` timeout", serviceMethod, cn.MainEndpoint)
+	}
+	cn.syncPaused(main.Paused)
+
+	return nil
+}
+
+// Used for worker node
+//
+// Report a finished run to the main node, which tracks the workflow runs.
+func (cn *ClusterNode) reportJobFinished(f JobFinished) error {
+	rClient, err := rpc.DialHTTP("tcp", cn.MainEndpoint)
+	if err != nil {
+		return fmt.Errorf("failed to connect to cluster main node: `%s`, error: %s", cn.MainEndpoint, err)
+	}
+	defer rClient.Close()
+
+	var main Node
+	ch := make(chan error, 1)
+	go func() { ch <- rClient.Call("CRPCService.JobFinished", f, &main) }()
+	select {
+	case err := <-ch:
+		if err != nil {
+			return fmt.Errorf("failed to report finished job `%s` to cluster main node, error: %s", f.Job.FullName(), err)
+		}
+	case <-time.After(3 * time.Second):
+		return fmt.Errorf("report finished job `%s` to cluster main node `%s` timeout", f.Job.FullName(), cn.MainEndpoint)
+	}
+
+	return nil
 }
 
 // Used for worker node
@@ -284,12 +511,13 @@ func (cn *ClusterNode) RegisterNodeRemote(ctx context.Context) error {
 		return fmt.Errorf("register to cluster main node `%s` timeout", cn.MainEndpoint)
 	}
 	cn.setNodeMap(main.NodeMap)
+	cn.syncPaused(main.Paused)
 
 	slog.Info(fmt.Sprintf("Cluster Main Node Scheduler RPC Service listening at: %s", main.SchedulerEndpoint))
 	slog.Info(fmt.Sprintf("Cluster Main Node Scheduler HTTP Service listening at: %s", main.EndpointHTTP))
 	slog.Info(fmt.Sprintf("Cluster Main Node Queue: `%s`", main.Queue))
 
-	go cn.heartbeatRemote(ctx)
+	go cn.heartbeatRemote(cn.withShutdown(ctx))
 
 	return nil
 }
@@ -320,14 +548,29 @@ func (cn *ClusterNode) heartbeatRemote(ctx context.Context) {
 //
 // Update and synchronize cluster node information.
 func (cn *ClusterNode) pingRemote(ctx context.Context) error {
+	// Checked with the lock held, as `Shutdown` cancels the context, so that no ping starts afterwards.
+	cn.mu.Lock()
+	if ctx.Err() != nil {
+		cn.mu.Unlock()
+		return ctx.Err()
+	}
+	cn.pings.Add(1)
+	cn.mu.Unlock()
+
 	rClient, err := rpc.DialHTTP("tcp", cn.MainEndpoint)
 	if err != nil {
+		cn.pings.Done()
 		return fmt.Errorf("failed to connect to cluster main node: `%s`, error: %s", cn.MainEndpoint, err)
 	}
 
 	var main Node
 	ch := make(chan error, 1)
-	go func() { ch <- rClient.Call("CRPCService.Ping", cn.toNode(), &main) }()
+	go func() {
+		defer cn.pings.Done()
+		defer rClient.Close()
+
+		ch <- rClient.Call("CRPCService.Ping", cn.toNode(), &main)
+	}()
 	select {
 	case err := <-ch:
 		if err != nil {
@@ -337,6 +580,70 @@ func (cn *ClusterNode) pingRemote(ctx context.Context) error {
 		return fmt.Errorf("ping to cluster main node `%s` timeout", cn.MainEndpoint)
 	}
 	cn.setNodeMap(main.NodeMap)
+	cn.syncPaused(main.Paused)
+
+	return nil
+}
+
+// Used for worker node
+//
+// Remove this node from the cluster, so that the main node no longer sends jobs to it.
+func (cn *ClusterNode) deregisterRemote(ctx context.Context) error {
+	slog.Info(fmt.Sprintf("Deregister from Cluster Main Node: `%s`", cn.MainEndpoint))
+
+	rClient, err := rpc.DialHTTP("tcp", cn.MainEndpoint)
+	if err != nil {
+		return fmt.Errorf("failed to connect to cluster main node: `%s`, error: %s", cn.MainEndpoint, err)
+	}
+	defer rClient.Close()
+
+	var main Node
+	ch := make(chan error, 1)
+	go func() { ch <- rClient.Call("CRPCService.Deregister", cn.toNode(), &main) }()
+	select {
+	case err := <-ch:
+		if err != nil {
+			return fmt.Errorf("failed to deregister from cluster main node, error: %s", err)
+		}
+	case <-ctx.Done():
+		return fmt.Errorf("deregister from cluster main node `%s` error: %s", cn.MainEndpoint, ctx.Err())
+	}
 
 	return nil
 }
+
+// Shutdown the node gracefully.
+// The background goroutines stop first, then a worker node deregisters from the main node
+// once its pings in flight are answered, and the scheduler waits for the running jobs.
+func (cn *ClusterNode) Shutdown(ctx context.Context) error {
+	slog.Info(fmt.Sprintf("Cluster Node `%s:%s` shutdown", cn.Id, cn.Endpoint))
+
+	cn.mu.Lock()
+	for _, cancel := range cn.cancels {
+		cancel()
+	}
+	cn.cancels = nil
+	cn.mu.Unlock()
+
+	if !cn.isMain() {
+		pinged := make(chan struct{})
+		go func() {
+			cn.pings.Wait()
+			close(pinged)
+		}()
+		select {
+		case <-pinged:
+		case <-ctx.Done():
+		}
+
+		if err := cn.deregisterRemote(ctx); err != nil {
+			slog.Warn(err.Error())
+		}
+	}
+
+	if cn.Scheduler == nil {
+		return nil
+	}
+
+	return cn.Scheduler.Shutdown(ctx)
+}
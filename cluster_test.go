@@ -3,7 +3,11 @@ package agscheduler
 import (
 	"context"
 	"encoding/gob"
+	"net"
+	"net/http"
+	"net/rpc"
 	"reflect"
+	"sync"
 	"testing"
 	"time"
 
@@ -31,25 +35,43 @@ func TestClusterToClusterNode(t *testing.T) {
 	cn := n.toClusterNode()
 
 	valueOfN := reflect.ValueOf(n)
-	valueOfCN := reflect.ValueOf(*cn)
+	typeOfN := reflect.TypeOf(n)
+	valueOfCN := reflect.ValueOf(cn).Elem()
 	for i := 0; i < valueOfN.NumField(); i++ {
-		assert.Equal(t, valueOfN.Field(i).String(), valueOfCN.Field(i).String())
+		fieldType := typeOfN.Field(i)
+		if fieldType.Name == "Paused" {
+			continue
+		}
+		assert.Equal(t, valueOfN.Field(i).String(), valueOfCN.FieldByName(clusterNodeFieldName(fieldType.Name)).String())
+	}
+}
+
+// `Node.NodeMap` and `Node.Funcs` correspond to the unexported fields of `ClusterNode`.
+func clusterNodeFieldName(name string) string {
+	switch name {
+	case "NodeMap":
+		return "nodeMap"
+	case "Funcs":
+		return "funcs"
+	case "Pool":
+		return "pool"
 	}
+	return name
 }
 
 func TestClusterNodeToNode(t *testing.T) {
 	cn := getClusterNode()
 	n := cn.toNode()
 
-	valueOfCN := reflect.ValueOf(*cn)
-	typeOfCN := reflect.TypeOf(*cn)
+	valueOfCN := reflect.ValueOf(cn).Elem()
 	valueOfN := reflect.ValueOf(*n)
-	for i := 0; i < valueOfCN.NumField(); i++ {
-		fieldType := typeOfCN.Field(i)
-		if fieldType.Name == "Scheduler" {
+	typeOfN := reflect.TypeOf(*n)
+	for i := 0; i < valueOfN.NumField(); i++ {
+		fieldType := typeOfN.Field(i)
+		if fieldType.Name == "Paused" {
 			continue
 		}
-		assert.Equal(t, valueOfCN.Field(i).String(), valueOfN.Field(i).String())
+		assert.Equal(t, valueOfCN.FieldByName(clusterNodeFieldName(fieldType.Name)).String(), valueOfN.Field(i).String())
 	}
 }
 
@@ -86,11 +108,86 @@ func TestClusterRegisterNode(t *testing.T) {
 	assert.Len(t, cn.NodeMap(), 1)
 }
 
+func TestClusterListFuncs(t *testing.T) {
+	r := NewFuncRegistry()
+	r.Register(dryRunRegistry)
+	s := &Scheduler{}
+	s.SetFuncRegistry(r)
+	cn := getClusterNode()
+	cn.Scheduler = s
+	s.clusterNode = cn
+	cn.registerNode(cn)
+	n := &Node{Id: "2", Endpoint: "127.0.0.1:36381", Queue: "node", Funcs: []string{getFuncName(dryRunRegistry), "remote"}}
+	cn.registerNode(n.toClusterNode())
+
+	infos := s.ListFuncs()
+
+	assert.Len(t, infos, 2)
+	assert.Equal(t, getFuncName(dryRunRegistry), infos[0].Name)
+	assert.Equal(t, []FuncNode{
+		{Id: "1", Queue: "default", Endpoint: "127.0.0.1:36380"},
+		{Id: "2", Queue: "node", Endpoint: "127.0.0.1:36381"},
+	}, infos[0].Nodes)
+	assert.Equal(t, "remote", infos[1].Name)
+	assert.Equal(t, []FuncNode{{Id: "2", Queue: "node", Endpoint: "127.0.0.1:36381"}}, infos[1].Nodes)
+}
+
 func TestClusterChoiceNode(t *testing.T) {
 	cn := getClusterNode()
 	cn.registerNode(cn)
 
-	_, err := cn.choiceNode([]string{})
+	_, err := cn.choiceNode([]string{}, "")
+	assert.NoError(t, err)
+}
+
+func TestClusterChoiceNodeCapacity(t *testing.T) {
+	cn := getClusterNode()
+	cn.pool = PoolStats{Size: 2, Running: 2}
+	cn.registerNode(cn)
+	assert.Equal(t, 2, cn.NodeMap()[cn.Queue][cn.Id]["pool_running"])
+
+	free := getClusterNode()
+	free.Id = "2"
+	free.pool = PoolStats{Size: 2, Running: 1}
+	cn.registerNode(free)
+
+	for i := 0; i < 10; i++ {
+		n, err := cn.choiceNode([]string{}, "")
+		assert.NoError(t, err)
+		assert.Equal(t, free.Id, n.Id)
+	}
+
+	// The full nodes are still picked when no node has free workers.
+	cn.nodeMap[cn.Queue][free.Id]["health"] = false
+	n, err := cn.choiceNode([]string{}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, cn.Id, n.Id)
+}
+
+func TestClusterChoiceNodeFunc(t *testing.T) {
+	cn := getClusterNode()
+	cn.funcs = []string{"local"}
+	cn.registerNode(cn)
+
+	remote := getClusterNode()
+	remote.Id = "2"
+	remote.funcs = []string{"remote"}
+	remote.pool = PoolStats{Size: 1, Running: 1}
+	cn.registerNode(remote)
+
+	// Preferred over the free workers of the other nodes.
+	for i := 0; i < 10; i++ {
+		n, err := cn.choiceNode([]string{}, "remote")
+		assert.NoError(t, err)
+		assert.Equal(t, remote.Id, n.Id)
+
+		n, err = cn.choiceNode([]string{}, "local")
+		assert.NoError(t, err)
+		assert.Equal(t, cn.Id, n.Id)
+	}
+
+	// Any node is picked when no node registers the function.
+	_, err := cn.choiceNode([]string{}, "unknown")
 	assert.NoError(t, err)
 }
 
@@ -99,7 +196,7 @@ func TestClusterChoiceNodeUnhealthy(t *testing.T) {
 	cn.registerNode(cn)
 	cn.nodeMap[cn.Queue][cn.Id]["health"] = false
 
-	_, err := cn.choiceNode([]string{})
+	_, err := cn.choiceNode([]string{}, "")
 	assert.Error(t, err)
 }
 
@@ -107,7 +204,7 @@ func TestClusterChoiceNodeQueueNotExist(t *testing.T) {
 	cn := getClusterNode()
 	cn.registerNode(cn)
 
-	_, err := cn.choiceNode([]string{"other"})
+	_, err := cn.choiceNode([]string{"other"}, "")
 	assert.Error(t, err)
 }
 
@@ -154,6 +251,122 @@ func TestClusterRPCPing(t *testing.T) {
 	assert.Len(t, cn.NodeMap(), 1)
 }
 
+func TestClusterRPCPauseAndResume(t *testing.T) {
+	cn := getClusterNode()
+	cn.Scheduler = &Scheduler{}
+
+	reply := &Node{}
+	cn.RPCPause(cn.toNode(), reply)
+	assert.True(t, reply.Paused)
+	assert.True(t, cn.Scheduler.IsPaused())
+
+	reply = &Node{}
+	cn.RPCResume(cn.toNode(), reply)
+	assert.False(t, reply.Paused)
+	assert.False(t, cn.Scheduler.IsPaused())
+}
+
+func TestClusterPauseAndResume(t *testing.T) {
+	cn := getClusterNode()
+	cn.Scheduler = &Scheduler{}
+
+	err := cn.Pause()
+	assert.NoError(t, err)
+	assert.True(t, cn.toNode().Paused)
+
+	err = cn.Resume()
+	assert.NoError(t, err)
+	assert.False(t, cn.toNode().Paused)
+}
+
+func TestClusterSyncPaused(t *testing.T) {
+	cn := getClusterNode()
+	cn.syncPaused(true)
+
+	cn.Scheduler = &Scheduler{}
+	cn.syncPaused(true)
+	assert.True(t, cn.Scheduler.IsPaused())
+
+	cn.syncPaused(false)
+	assert.False(t, cn.Scheduler.IsPaused())
+}
+
+func TestClusterRPCDeregister(t *testing.T) {
+	cn := getClusterNode()
+	n := &ClusterNode{Id: "2", Queue: "node"}
+	cn.registerNode(cn)
+	cn.registerNode(n)
+
+	assert.Len(t, cn.NodeMap(), 2)
+
+	reply := &Node{}
+	cn.RPCDeregister(n.toNode(), reply)
+
+	assert.Len(t, cn.NodeMap(), 1)
+	assert.Len(t, reply.NodeMap, 1)
+}
+
+func TestClusterShutdown(t *testing.T) {
+	cn := getClusterNode()
+	s := &Scheduler{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := s.SetClusterNode(ctx, cn)
+	assert.NoError(t, err)
+	assert.Len(t, cn.cancels, 1)
+
+	err = cn.Shutdown(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, cn.cancels, 0)
+}
+
+// A main node answering pings slowly, recording the calls of the worker nodes.
+type slowMainRPC struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (m *slowMainRPC) Ping(args *Node, reply *Node) error {
+	time.Sleep(200 * time.Millisecond)
+	m.mu.Lock()
+	m.calls = append(m.calls, "ping")
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *slowMainRPC) Deregister(args *Node, reply *Node) error {
+	m.mu.Lock()
+	m.calls = append(m.calls, "deregister")
+	m.mu.Unlock()
+	return nil
+}
+
+func TestClusterShutdownAfterPings(t *testing.T) {
+	main := &slowMainRPC{}
+	srv := rpc.NewServer()
+	err := srv.RegisterName("CRPCService", main)
+	assert.NoError(t, err)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+	go http.Serve(ln, srv)
+
+	cn := &ClusterNode{Id: "2", MainEndpoint: ln.Addr().String(), Endpoint: "127.0.0.1:1", Queue: "node"}
+	pingCtx := cn.withShutdown(context.Background())
+	go cn.pingRemote(pingCtx)
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	err = cn.Shutdown(ctx)
+	assert.NoError(t, err)
+
+	// The ping in flight is answered before the node deregisters, no ping starts afterwards.
+	assert.ErrorIs(t, cn.pingRemote(pingCtx), context.Canceled)
+	assert.Equal(t, []string{"ping", "deregister"}, main.calls)
+}
+
 func TestClusterRegisterNodeRemote(t *testing.T) {
 	gob.Register(time.Time{})
 
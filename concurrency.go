@@ -0,0 +1,167 @@
+package agscheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// How long the slot of a concurrency key is kept without being refreshed,
+// so that the slots of a crashed node are freed. The running runs refresh them at a third of it.
+const SEMAPHORE_TTL = 30 * time.Second
+
+// How often a blocked run tries again to take its concurrency keys.
+const SEMAPHORE_RETRY_INTERVAL = 100 * time.Millisecond
+
+// Keeps the semaphores of a scheduler whose store does not implement `SemaphoreStore`.
+type memorySemaphores struct {
+	mu sync.Mutex
+
+	// def: map[<semaphore name>]map[<holder>]<expiration>
+	semaphores map[string]map[string]time.Time
+}
+
+func (ms *memorySemaphores) AcquireSemaphore(name string, holder string, limit int, ttl time.Duration) (bool, error) {
+	defer ms.mu.Unlock()
+
+	ms.mu.Lock()
+
+	if ms.semaphores == nil {
+		ms.semaphores = make(map[string]map[string]time.Time)
+	}
+	slots, ok := ms.semaphores[name]
+	if !ok {
+		slots = make(map[string]time.Time)
+		ms.semaphores[name] = slots
+	}
+
+	now := time.Now()
+	for h, expireAt := range slots {
+		if !expireAt.After(now) {
+			delete(slots, h)
+		}
+	}
+	if _, ok := slots[holder]; !ok && len(slots) >= limit {
+		return false, nil
+	}
+
+	slots[holder] = now.Add(ttl)
+	return true, nil
+}
+
+func (ms *memorySemaphores) ReleaseSemaphore(name string, holder string) error {
+	defer ms.mu.Unlock()
+
+	ms.mu.Lock()
+
+	delete(ms.semaphores[name], holder)
+	return nil
+}
+
+// Returns the store when it implements `SemaphoreStore`, otherwise the semaphores in process.
+func (s *Scheduler) semaphoreStore() SemaphoreStore {
+	if ss, ok := s.store.(SemaphoreStore); ok {
+		return ss
+	}
+
+	return &s.semaphores
+}
+
+// Keys without a limit are mutexes.
+func (s *Scheduler) semaphoreLimit(key string) int {
+	if limit, ok := s.Semaphores[key]; ok && limit > 0 {
+		return limit
+	}
+
+	return 1
+}
+
+// Take the slots of all keys or none of them.
+func (s *Scheduler) tryAcquireKeys(keys []string, holder string) (bool, error) {
+	for i, key := range keys {
+		ok, err := s.semaphoreStore().AcquireSemaphore(key, holder, s.semaphoreLimit(key), SEMAPHORE_TTL)
+		if err != nil || !ok {
+			s.releaseKeys(keys[:i], holder)
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func (s *Scheduler) releaseKeys(keys []string, holder string) {
+	for _, key := range keys {
+		if err := s.semaphoreStore().ReleaseSemaphore(key, holder); err != nil {
+			slog.Error(fmt.Sprintf("Release concurrency key `%s` error: %s\n", key, err))
+		}
+	}
+}
+
+// Wait until the run holds all the `ConcurrencyKeys` of the job, at most `SemaphoreTimeout`.
+// The slots are taken through the store, so that the runs on other nodes are counted,
+// unless the store does not implement `SemaphoreStore`.
+// The returned function releases the keys, they are refreshed until then.
+func (s *Scheduler) acquireConcurrencyKeys(ctx context.Context, j Job) (func(), error) {
+	keys := slices.Clone(j.ConcurrencyKeys)
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+	holder := j.Id + ":" + strings.Replace(uuid.New().String(), "-", "", -1)[:16]
+
+	timeout := s.SemaphoreTimeout
+	if timeout <= 0 {
+		timeout = time.Minute
+	}
+	deadline := time.Now().Add(timeout)
+	for blocked := false; ; blocked = true {
+		ok, err := s.tryAcquireKeys(keys, holder)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			break
+		}
+
+		if !blocked {
+			slog.Info(fmt.Sprintf("Job `%s` is blocked, waiting for its concurrency keys `%s`.\n", j.FullName(), keys))
+			s.records.event(j, RECORD_BLOCKED, map[string]any{"concurrency_keys": keys}, s.MaxRecords)
+		}
+		if time.Now().After(deadline) {
+			return nil, &ConcurrencyKeysTimeoutError{FullName: j.FullName(), Keys: keys, Timeout: timeout}
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(SEMAPHORE_RETRY_INTERVAL):
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(SEMAPHORE_TTL / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				for _, key := range keys {
+					if _, err := s.semaphoreStore().AcquireSemaphore(key, holder, s.semaphoreLimit(key), SEMAPHORE_TTL); err != nil {
+						slog.Error(fmt.Sprintf("Refresh concurrency key `%s` error: %s\n", key, err))
+					}
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		s.releaseKeys(keys, holder)
+	}, nil
+}
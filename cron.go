@@ -0,0 +1,205 @@
+package agscheduler
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorhill/cronexpr"
+)
+
+type cronSchedule interface {
+	Next(time.Time) time.Time
+}
+
+// `@every <duration>`, the next run time is always `d` after the last one.
+type everySchedule struct {
+	d time.Duration
+}
+
+func (es everySchedule) Next(t time.Time) time.Time {
+	return t.Add(es.d)
+}
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 0 1 1 * *",
+	"@annually": "0 0 0 1 1 * *",
+	"@monthly":  "0 0 0 1 * * *",
+	"@weekly":   "0 0 0 * * 0 *",
+	"@daily":    "0 0 0 * * * *",
+	"@midnight": "0 0 0 * * * *",
+	"@hourly":   "0 0 * * * * *",
+}
+
+// A field of a cron expression normalized to seven fields.
+type cronField struct {
+	name     string
+	min, max int
+	value    string
+	// Byte offset in the expression, -1 when the field is filled in.
+	pos int
+}
+
+func newCronFields() []cronField {
+	return []cronField{
+		{name: "second", min: 0, max: 59, value: "0", pos: -1},
+		{name: "minute", min: 0, max: 59, value: "*", pos: -1},
+		{name: "hour", min: 0, max: 23, value: "*", pos: -1},
+		// `H` never picks the days missing in some months.
+		{name: "day-of-month", min: 1, max: 28, value: "*", pos: -1},
+		{name: "month", min: 1, max: 12, value: "*", pos: -1},
+		{name: "day-of-week", min: 0, max: 6, value: "*", pos: -1},
+		{name: "year", min: 1970, max: 2099, value: "*", pos: -1},
+	}
+}
+
+var (
+	cronFieldRegexp = regexp.MustCompile(`\S+`)
+	cronHashRegexp  = regexp.MustCompile(`^H(?:\((\d+)-(\d+)\))?(?:/(\d+))?$`)
+)
+
+// Replace the `H` items of a field with values hashed from `seed`,
+// so that jobs with the same expression do not all run at the same time.
+//
+// `H`: a value in the field's range
+// `H(a-b)`: a value in `a-b`
+// `H/n`: every `n` starting from a value in `0 ~ n-1` of the field's range
+// `H(a-b)/n`: every `n` in `a-b` starting from a value in `0 ~ n-1` of it
+func (cf cronField) resolveHash(index int, seed string) (string, error) {
+	if !strings.Contains(cf.value, "H") {
+		return cf.value, nil
+	}
+	if cf.name == "year" {
+		return "", fmt.Errorf("`H` is not supported in the year field")
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(fmt.Sprintf("%s:%d", seed, index)))
+	hash := int(h.Sum32() & 0x7fffffff)
+
+	items := strings.Split(cf.value, ",")
+	for i, item := range items {
+		if !strings.Contains(item, "H") {
+			continue
+		}
+
+		m := cronHashRegexp.FindStringSubmatch(item)
+		if m == nil {
+			return "", fmt.Errorf("invalid hash item `%s`", item)
+		}
+		lo, hi := cf.min, cf.max
+		if m[1] != "" {
+			lo, _ = strconv.Atoi(m[1])
+			hi, _ = strconv.Atoi(m[2])
+			if lo > hi || lo < cf.min || hi > cf.max {
+				return "", fmt.Errorf("hash range `%d-%d` out of `%d-%d`", lo, hi, cf.min, cf.max)
+			}
+		}
+		if m[3] == "" {
+			items[i] = strconv.Itoa(lo + hash%(hi-lo+1))
+			continue
+		}
+
+		step, _ := strconv.Atoi(m[3])
+		if step <= 0 {
+			return "", fmt.Errorf("invalid hash step `%s`", m[3])
+		}
+		items[i] = fmt.Sprintf("%d-%d/%d", lo+hash%min(step, hi-lo+1), hi, step)
+	}
+
+	return strings.Join(items, ","), nil
+}
+
+// Parse a cron expression, the supported forms:
+//
+// 5 fields: `minute hour day-of-month month day-of-week`
+// 6 fields: `minute hour day-of-month month day-of-week year`
+// 7 fields: `second minute hour day-of-month month day-of-week year`
+// Macros: `@yearly` | `@annually` | `@monthly` | `@weekly` | `@daily` | `@midnight` | `@hourly`
+// `@every <duration>`: refer to `time.ParseDuration`, at least `1s`
+//
+// `H` items are hashed from `seed`, which is the job id, see `cronField.resolveHash`.
+func parseCronExpr(expr string, seed string) (cronSchedule, error) {
+	newErr := func(pos int, field string, reason string) error {
+		return &InvalidCronExprError{Expr: expr, Position: pos, Field: field, Reason: reason}
+	}
+
+	trimmed := strings.TrimSpace(expr)
+	if trimmed == "" {
+		return nil, newErr(0, "", "empty expression")
+	}
+	offset := strings.Index(expr, trimmed)
+
+	if strings.HasPrefix(trimmed, "@every") {
+		arg := strings.TrimSpace(strings.TrimPrefix(trimmed, "@every"))
+		argPos := offset + len("@every")
+		if arg != "" {
+			argPos = offset + strings.LastIndex(trimmed, arg)
+		}
+		d, err := time.ParseDuration(arg)
+		if err != nil {
+			return nil, newErr(argPos, "", err.Error())
+		}
+		if d < time.Second {
+			return nil, newErr(argPos, "", "duration must be at least `1s`")
+		}
+		return everySchedule{d: d}, nil
+	}
+
+	fields := newCronFields()
+	if strings.HasPrefix(trimmed, "@") {
+		macro, ok := cronMacros[strings.ToLower(trimmed)]
+		if !ok {
+			return nil, newErr(offset, "", fmt.Sprintf("unknown macro `%s`", trimmed))
+		}
+		for i, v := range strings.Fields(macro) {
+			fields[i].value = v
+		}
+	} else {
+		indices := cronFieldRegexp.FindAllStringIndex(expr, -1)
+		switch {
+		case len(indices) < 5:
+			return nil, newErr(len(expr), "", fmt.Sprintf("%d fields found, 5 ~ 7 expected", len(indices)))
+		case len(indices) > 7:
+			return nil, newErr(indices[7][0], "", fmt.Sprintf("%d fields found, 5 ~ 7 expected", len(indices)))
+		}
+
+		// Only seven fields have seconds, six fields end with the year as before.
+		start := 0
+		if len(indices) < 7 {
+			start = 1
+		}
+		for i, idx := range indices {
+			fields[start+i].value = expr[idx[0]:idx[1]]
+			fields[start+i].pos = idx[0]
+		}
+	}
+
+	values := make([]string, 0, len(fields))
+	for i, cf := range fields {
+		v, err := cf.resolveHash(i, seed)
+		if err != nil {
+			return nil, newErr(max(cf.pos, 0), cf.name, err.Error())
+		}
+		values = append(values, v)
+	}
+
+	// Check the fields one by one, so that the position of the problem is known.
+	for i, cf := range fields {
+		single := []string{"0", "*", "*", "*", "*", "*", "*"}
+		single[i] = values[i]
+		if _, err := cronexpr.Parse(strings.Join(single, " ")); err != nil {
+			return nil, newErr(max(cf.pos, 0), cf.name, err.Error())
+		}
+	}
+
+	schedule, err := cronexpr.Parse(strings.Join(values, " "))
+	if err != nil {
+		return nil, newErr(offset, "", err.Error())
+	}
+
+	return schedule, nil
+}
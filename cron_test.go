@@ -0,0 +1,94 @@
+package agscheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCronExpr(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for expr, next := range map[string]time.Time{
+		"30 9 * * *":        time.Date(2024, 1, 1, 9, 30, 0, 0, time.UTC),
+		"15 30 9 * * * *":   time.Date(2024, 1, 1, 9, 30, 15, 0, time.UTC),
+		"30 9 * * * 2026":   time.Date(2026, 1, 1, 9, 30, 0, 0, time.UTC),
+		"0 0 0 1 1 * 2026":  time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		"*/10 * * * * ? *":  time.Date(2024, 1, 1, 0, 0, 10, 0, time.UTC),
+		" 0 12 * * MON ":    time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		"@hourly":           time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC),
+		"@daily":            time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		"@every 90s":        time.Date(2024, 1, 1, 0, 1, 30, 0, time.UTC),
+		"@every 1h30m":      time.Date(2024, 1, 1, 1, 30, 0, 0, time.UTC),
+		"0 H(10-10) * * *":  time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+		"H(0-0)/15 9 * * *": time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+	} {
+		schedule, err := parseCronExpr(expr, "seed")
+		assert.NoError(t, err, expr)
+		assert.Equal(t, next, schedule.Next(from), expr)
+	}
+}
+
+func TestParseCronExprHash(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	a, err := parseCronExpr("H H * * *", "a")
+	assert.NoError(t, err)
+	a2, err := parseCronExpr("H H * * *", "a")
+	assert.NoError(t, err)
+	assert.Equal(t, a.Next(from), a2.Next(from))
+
+	// Spread in the same day.
+	nexts := make(map[time.Time]bool)
+	for _, seed := range []string{"a", "b", "c", "d", "e", "f"} {
+		s, err := parseCronExpr("H H * * *", seed)
+		assert.NoError(t, err)
+		next := s.Next(from)
+		assert.Equal(t, 1, next.Day())
+		nexts[next] = true
+	}
+	assert.Greater(t, len(nexts), 1)
+
+	s, err := parseCronExpr("H/15 * * * *", "a")
+	assert.NoError(t, err)
+	next := s.Next(from)
+	assert.Less(t, next.Minute(), 15)
+	assert.Equal(t, 15*time.Minute, s.Next(next).Sub(next))
+
+	s, err = parseCronExpr("0 H(9-17) * * H", "a")
+	assert.NoError(t, err)
+	next = s.Next(from)
+	assert.GreaterOrEqual(t, next.Hour(), 9)
+	assert.LessOrEqual(t, next.Hour(), 17)
+}
+
+func TestParseCronExprError(t *testing.T) {
+	for expr, want := range map[string]InvalidCronExprError{
+		"":                {Position: 0},
+		"* * * *":         {Position: 7},
+		"1 2 3 4 5 6 7 8": {Position: 14},
+		"61 * * * *":      {Position: 0, Field: "minute"},
+		"* * 25 * * * *":  {Position: 4, Field: "hour"},
+		"0 0 * * 13 * *":  {Position: 8, Field: "month"},
+		"0 0 * * * 1969":  {Position: 10, Field: "year"},
+		"*/0 * * * *":     {Position: 0, Field: "minute"},
+		"0 0 0 * * * H":   {Position: 12, Field: "year"},
+		"0 H(5-30) * * *": {Position: 2, Field: "hour"},
+		"0 0 Hx * *":      {Position: 4, Field: "day-of-month"},
+		"0 0 * * H/0":     {Position: 8, Field: "day-of-week"},
+		"@foo":            {Position: 0},
+		"@every 500ms":    {Position: 7},
+		"@every":          {Position: 6},
+		"  @every x":      {Position: 9},
+	} {
+		_, err := parseCronExpr(expr, "seed")
+		cronErr := &InvalidCronExprError{}
+		if assert.ErrorAs(t, err, &cronErr, expr) {
+			assert.Equal(t, expr, cronErr.Expr)
+			assert.Equal(t, want.Position, cronErr.Position, expr)
+			assert.Equal(t, want.Field, cronErr.Field, expr)
+			assert.NotEmpty(t, cronErr.Reason, expr)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package agscheduler
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gorhill/cronexpr"
+)
+
+// constant indicating how a job handles the times skipped when clocks go forward
+const (
+	// The skipped times do not run.
+	DST_SKIP = "skip"
+	// The skipped times run shifted forward by the change, such as `02:30` at `03:30`.
+	DST_SHIFT = "shift"
+)
+
+// constant indicating how a job handles the times repeated when clocks go back
+const (
+	// The repeated times run at their first occurrence.
+	DST_ONCE = "once"
+	// The repeated times run at both occurrences.
+	DST_TWICE = "twice"
+)
+
+// The most a timezone offset changes at once, larger than any known change.
+const dstMaxChange = 3 * time.Hour
+
+// The most candidate times checked by a cron expression near a DST change, before giving up.
+const DST_MAX_CANDIDATES = 100000
+
+var locations sync.Map
+
+// Same as `time.LoadLocation`, the locations are cached.
+func loadLocation(name string) (*time.Location, error) {
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, loc)
+
+	return loc, nil
+}
+
+func checkDSTPolicies(gap string, overlap string) (string, error) {
+	switch gap {
+	case "", DST_SKIP, DST_SHIFT:
+	default:
+		return "dst_gap", errors.New("unknown")
+	}
+	switch overlap {
+	case "", DST_ONCE, DST_TWICE:
+	default:
+		return "dst_overlap", errors.New("unknown")
+	}
+
+	return "", nil
+}
+
+// The wall clock time of `t` in `loc`, as a time in UTC.
+func wallTime(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// A cron expression evaluated on the wall clock of `loc`,
+// so that the times skipped or repeated by DST changes follow the policies.
+type dstSchedule struct {
+	expr    *cronexpr.Expression
+	loc     *time.Location
+	gap     string
+	overlap string
+}
+
+// Wrap cron expressions parsed by `parseCronExpr`, `@every` runs by duration and is kept.
+func newDSTSchedule(s cronSchedule, loc *time.Location, gap string, overlap string) cronSchedule {
+	expr, ok := s.(*cronexpr.Expression)
+	if !ok {
+		return s
+	}
+
+	return dstSchedule{expr: expr, loc: loc, gap: gap, overlap: overlap}
+}
+
+// The times at the wall clock time `w` in order, following the policies.
+func (ds dstSchedule) instants(w time.Time) []time.Time {
+	ts := make([]time.Time, 0, 2)
+	var shifted time.Time
+	// `w` read as UTC is off by the offset, so the time is approximated first.
+	_, offset := w.In(ds.loc).Zone()
+	approx := w.Add(-time.Duration(offset) * time.Second)
+	for _, probe := range []time.Duration{-dstMaxChange, dstMaxChange} {
+		_, offset := approx.Add(probe).In(ds.loc).Zone()
+		t := w.Add(-time.Duration(offset) * time.Second)
+		switch tw := wallTime(t, ds.loc); {
+		case tw.Equal(w):
+			if len(ts) == 0 || !ts[0].Equal(t) {
+				ts = append(ts, t)
+			}
+		case tw.After(w):
+			shifted = t
+		}
+	}
+
+	if len(ts) == 2 {
+		if ts[1].Before(ts[0]) {
+			ts[0], ts[1] = ts[1], ts[0]
+		}
+		if ds.overlap != DST_TWICE {
+			ts = ts[:1]
+		}
+	}
+	if len(ts) == 0 && ds.gap != DST_SKIP && !shifted.IsZero() {
+		ts = append(ts, shifted)
+	}
+
+	return ts
+}
+
+func (ds dstSchedule) Next(from time.Time) time.Time {
+	w := ds.expr.Next(wallTime(from, ds.loc))
+	if w.IsZero() {
+		return time.Time{}
+	}
+
+	// Without offset changes, the wall clock maps to the times one to one.
+	ts := ds.instants(w)
+	_, offsetFrom := from.Add(-dstMaxChange).In(ds.loc).Zone()
+	if len(ts) == 1 {
+		if _, offset := ts[0].Add(dstMaxChange).In(ds.loc).Zone(); offset == offsetFrom {
+			return ts[0]
+		}
+	}
+
+	// Otherwise a later wall clock time may run earlier, such as the second occurrence
+	// of a repeated time, so all wall clock times close enough are compared.
+	var next, nextWall time.Time
+	cursor := wallTime(from, ds.loc).Add(-dstMaxChange)
+	for i := 0; i < DST_MAX_CANDIDATES; i++ {
+		w := ds.expr.Next(cursor)
+		if w.IsZero() || (!next.IsZero() && w.After(nextWall.Add(dstMaxChange))) {
+			break
+		}
+		for _, t := range ds.instants(w) {
+			if t.After(from) && (next.IsZero() || t.Before(next)) {
+				next, nextWall = t, w
+			}
+		}
+		cursor = w
+	}
+
+	return next
+}
@@ -0,0 +1,186 @@
+package agscheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func dstNexts(t *testing.T, cronExpr string, loc *time.Location, gap string, overlap string, from time.Time, n int) []time.Time {
+	expr, err := parseCronExpr(cronExpr, "seed")
+	assert.NoError(t, err, cronExpr)
+	s := newDSTSchedule(expr, loc, gap, overlap)
+
+	nexts := make([]time.Time, 0, n)
+	for i := 0; i < n; i++ {
+		from = s.Next(from)
+		if from.IsZero() {
+			break
+		}
+		nexts = append(nexts, from)
+	}
+
+	return nexts
+}
+
+func TestDSTSchedule(t *testing.T) {
+	ny, _ := loadLocation("America/New_York")
+	london, _ := loadLocation("Europe/London")
+	lordHowe, _ := loadLocation("Australia/Lord_Howe")
+	sydney, _ := loadLocation("Australia/Sydney")
+	utc := func(y int, m time.Month, d int, h int, min int) time.Time {
+		return time.Date(y, m, d, h, min, 0, 0, time.UTC)
+	}
+
+	for _, c := range []struct {
+		name     string
+		cronExpr string
+		loc      *time.Location
+		gap      string
+		overlap  string
+		from     time.Time
+		nexts    []time.Time
+	}{
+		{
+			"new york gap skip", "30 2 * * *", ny, DST_SKIP, "",
+			utc(2024, 3, 9, 12, 0),
+			// 02:30 EST, 02:30 EDT on the next day.
+			[]time.Time{utc(2024, 3, 11, 6, 30), utc(2024, 3, 12, 6, 30)},
+		},
+		{
+			"new york gap shift", "30 2 * * *", ny, DST_SHIFT, "",
+			utc(2024, 3, 9, 12, 0),
+			// 03:30 EDT, then 02:30 EDT.
+			[]time.Time{utc(2024, 3, 10, 7, 30), utc(2024, 3, 11, 6, 30)},
+		},
+		{
+			"new york gap default", "30 2 * * *", ny, "", "",
+			utc(2024, 3, 9, 12, 0),
+			[]time.Time{utc(2024, 3, 10, 7, 30), utc(2024, 3, 11, 6, 30)},
+		},
+		{
+			"new york gap every 30 minutes", "*/30 * * * *", ny, "", "",
+			utc(2024, 3, 10, 6, 0),
+			// 01:30 EST, 03:00 EDT, the shifted 02:00 and 02:30 run at 03:00 and 03:30 EDT.
+			[]time.Time{utc(2024, 3, 10, 6, 30), utc(2024, 3, 10, 7, 0), utc(2024, 3, 10, 7, 30), utc(2024, 3, 10, 8, 0)},
+		},
+		{
+			"new york overlap once", "30 1 * * *", ny, "", DST_ONCE,
+			utc(2024, 11, 2, 12, 0),
+			// 01:30 EDT, then 01:30 EST on the next day.
+			[]time.Time{utc(2024, 11, 3, 5, 30), utc(2024, 11, 4, 6, 30)},
+		},
+		{
+			"new york overlap twice", "30 1 * * *", ny, "", DST_TWICE,
+			utc(2024, 11, 2, 12, 0),
+			// 01:30 EDT, 01:30 EST.
+			[]time.Time{utc(2024, 11, 3, 5, 30), utc(2024, 11, 3, 6, 30), utc(2024, 11, 4, 6, 30)},
+		},
+		{
+			"new york overlap twice every 30 minutes", "*/30 * * * *", ny, "", DST_TWICE,
+			utc(2024, 11, 3, 4, 45),
+			// 01:00 EDT, 01:30 EDT, 01:00 EST, 01:30 EST, 02:00 EST.
+			[]time.Time{
+				utc(2024, 11, 3, 5, 0), utc(2024, 11, 3, 5, 30), utc(2024, 11, 3, 6, 0),
+				utc(2024, 11, 3, 6, 30), utc(2024, 11, 3, 7, 0),
+			},
+		},
+		{
+			"new york overlap once every 30 minutes", "*/30 * * * *", ny, "", DST_ONCE,
+			utc(2024, 11, 3, 4, 45),
+			// 01:00 EDT, 01:30 EDT, 02:00 EST.
+			[]time.Time{utc(2024, 11, 3, 5, 0), utc(2024, 11, 3, 5, 30), utc(2024, 11, 3, 7, 0)},
+		},
+		{
+			"london gap skip", "30 1 * * *", london, DST_SKIP, "",
+			utc(2024, 3, 30, 12, 0),
+			// 01:30 BST on the next day.
+			[]time.Time{utc(2024, 4, 1, 0, 30)},
+		},
+		{
+			"london overlap twice", "30 1 * * *", london, "", DST_TWICE,
+			utc(2024, 10, 26, 12, 0),
+			// 01:30 BST, 01:30 GMT.
+			[]time.Time{utc(2024, 10, 27, 0, 30), utc(2024, 10, 27, 1, 30)},
+		},
+		{
+			"lord howe gap shift by 30 minutes", "15 2 * * *", lordHowe, DST_SHIFT, "",
+			utc(2024, 10, 5, 0, 0),
+			// 02:45 +11, then 02:15 +11.
+			[]time.Time{utc(2024, 10, 5, 15, 45), utc(2024, 10, 6, 15, 15)},
+		},
+		{
+			"lord howe overlap twice by 30 minutes", "45 1 * * *", lordHowe, "", DST_TWICE,
+			utc(2024, 4, 6, 0, 0),
+			// 01:45 +11, 01:45 +10:30.
+			[]time.Time{utc(2024, 4, 6, 14, 45), utc(2024, 4, 6, 15, 15)},
+		},
+		{
+			"sydney overlap twice", "30 2 * * *", sydney, "", DST_TWICE,
+			utc(2024, 4, 6, 0, 0),
+			// 02:30 AEDT, 02:30 AEST.
+			[]time.Time{utc(2024, 4, 6, 15, 30), utc(2024, 4, 6, 16, 30)},
+		},
+		{
+			"sydney gap skip", "30 2 * * *", sydney, DST_SKIP, "",
+			utc(2024, 10, 5, 0, 0),
+			// 02:30 AEDT on the next day.
+			[]time.Time{utc(2024, 10, 6, 15, 30)},
+		},
+	} {
+		nexts := dstNexts(t, c.cronExpr, c.loc, c.gap, c.overlap, c.from, len(c.nexts))
+		assert.Len(t, nexts, len(c.nexts), c.name)
+		for i := range c.nexts {
+			if i < len(nexts) {
+				assert.True(t, c.nexts[i].Equal(nexts[i]), "%s: %s != %s", c.name, c.nexts[i], nexts[i])
+			}
+		}
+	}
+}
+
+func TestCalcNextRunTimeDST(t *testing.T) {
+	j := Job{Name: "Job", Type: TYPE_CRON, CronExpr: "30 2 * * *", Timezone: "America/New_York", DSTGap: DST_SKIP}
+
+	from := time.Date(2024, 3, 9, 12, 0, 0, 0, time.UTC)
+	nextRunTime, err := calcNextRunTime(j, from, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 11, 6, 30, 0, 0, time.UTC), nextRunTime)
+
+	j.Type = TYPE_COMBINED
+	j.Operator = OPERATOR_OR
+	j.Triggers = []Trigger{{Type: TYPE_CRON, CronExpr: j.CronExpr}}
+	j.DSTGap = DST_SHIFT
+	nextRunTime, err = calcNextRunTime(j, from, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 10, 7, 30, 0, 0, time.UTC), nextRunTime)
+}
+
+func TestJobCheckDST(t *testing.T) {
+	for field, j := range map[string]Job{
+		"dst_gap":     {Name: "Job", Type: TYPE_CRON, CronExpr: "* * * * *", Timeout: "1h", DSTGap: "later"},
+		"dst_overlap": {Name: "Job", Type: TYPE_CRON, CronExpr: "* * * * *", Timeout: "1h", DSTOverlap: "thrice"},
+		"timezone":    {Name: "Job", Type: TYPE_CRON, CronExpr: "* * * * *", Timeout: "1h", Timezone: "Mars/Olympus_Mons"},
+	} {
+		err := j.check(func(name string) (registeredFunc, bool) {
+			return registeredFunc{info: FuncInfo{Name: name}}, true
+		})
+		scheduleErr := &JobScheduleError{}
+		assert.ErrorAs(t, err, &scheduleErr, field)
+		assert.Equal(t, field, scheduleErr.Field)
+	}
+}
+
+func TestLoadLocation(t *testing.T) {
+	loc, err := loadLocation("Asia/Shanghai")
+	assert.NoError(t, err)
+	cached, err := loadLocation("Asia/Shanghai")
+	assert.NoError(t, err)
+	assert.Same(t, loc, cached)
+
+	_, err = loadLocation("Mars/Olympus_Mons")
+	assert.Error(t, err)
+
+	j := Job{Timezone: "Mars/Olympus_Mons", NextRunTime: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	assert.Equal(t, time.UTC, j.NextRunTimeWithTimezone().Location())
+}
@@ -1,9 +1,19 @@
 package agscheduler
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrSchedulerPaused = errors.New("scheduler is paused!")
+var ErrEmptyJobSelector = errors.New("job selector is empty, set `All` to select all jobs!")
 
 type JobNotFoundError string
 type FuncUnregisteredError string
+type WorkflowRunNotFoundError string
+type CalendarNotFoundError string
 
 type JobTimeoutError struct {
 	FullName string
@@ -11,6 +21,53 @@ type JobTimeoutError struct {
 	Err      error
 }
 
+// Returned when a run waits for the `ConcurrencyKeys` of its job longer than `Scheduler.SemaphoreTimeout`.
+type ConcurrencyKeysTimeoutError struct {
+	FullName string
+	Keys     []string
+	Timeout  time.Duration
+}
+
+// Returned when the `Args` of a job do not match the function registered by `RegisterTyped`.
+type JobArgsError struct {
+	FullName string
+	FuncName string
+	Fields   []ArgsFieldError
+}
+
+// Returned when the `Upstreams` of a job lead back to itself.
+type WorkflowCycleError struct {
+	FullName string
+	// Job ids, starting and ending with the job.
+	Cycle []string
+}
+
+// Returned when a field deciding when a job runs is invalid.
+type JobScheduleError struct {
+	FullName string
+	// The JSON name of the field, such as `cron_expr`.
+	Field string
+	Value string
+	Err   error
+}
+
+// Returned when a cron expression can not be parsed.
+type InvalidCronExprError struct {
+	Expr string
+	// Byte offset of the problem in `Expr`, starting from 0.
+	Position int
+	// Such as `minute`, empty when the problem is not about one field.
+	Field  string
+	Reason string
+}
+
+// An invalid field of the `Args`, `Field` is empty when the problem is not about one field.
+// It can also be returned by `ArgsValidator.Validate`.
+type ArgsFieldError struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
 func (e JobNotFoundError) Error() string {
 	return fmt.Sprintf("jobId `%s` not found!", string(e))
 }
@@ -19,6 +76,55 @@ func (e FuncUnregisteredError) Error() string {
 	return fmt.Sprintf("function `%s` unregistered!", string(e))
 }
 
+func (e WorkflowRunNotFoundError) Error() string {
+	return fmt.Sprintf("workflow run `%s` not found!", string(e))
+}
+
+func (e CalendarNotFoundError) Error() string {
+	return fmt.Sprintf("calendar `%s` not found!", string(e))
+}
+
 func (e *JobTimeoutError) Error() string {
 	return fmt.Sprintf("job `%s` Timeout `%s` error: %s!", e.FullName, e.Timeout, e.Err)
 }
+
+func (e *ConcurrencyKeysTimeoutError) Error() string {
+	return fmt.Sprintf("job `%s` ConcurrencyKeys `%s` wait timeout after %s!", e.FullName, strings.Join(e.Keys, ", "), e.Timeout)
+}
+
+func (e *JobArgsError) Error() string {
+	fs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		fs = append(fs, f.Error())
+	}
+
+	return fmt.Sprintf("job `%s` Args of Func `%s` error: %s!", e.FullName, e.FuncName, strings.Join(fs, "; "))
+}
+
+func (e *WorkflowCycleError) Error() string {
+	return fmt.Sprintf("job `%s` Upstreams form a cycle: `%s`!", e.FullName, strings.Join(e.Cycle, " -> "))
+}
+
+func (e *JobScheduleError) Error() string {
+	return fmt.Sprintf("job `%s` %s `%s` error: %s!", e.FullName, e.Field, e.Value, e.Err)
+}
+
+func (e *JobScheduleError) Unwrap() error {
+	return e.Err
+}
+
+func (e *InvalidCronExprError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("cron expression `%s` error at position %d: %s!", e.Expr, e.Position, e.Reason)
+	}
+
+	return fmt.Sprintf("cron expression `%s` %s field error at position %d: %s!", e.Expr, e.Field, e.Position, e.Reason)
+}
+
+func (e *ArgsFieldError) Error() string {
+	if e.Field == "" {
+		return e.Description
+	}
+
+	return fmt.Sprintf("`%s` %s", e.Field, e.Description)
+}
@@ -19,8 +19,54 @@ func TestFuncUnregisteredError(t *testing.T) {
 	assert.Equal(t, "function `func` unregistered!", err.Error())
 }
 
+func TestCalendarNotFoundError(t *testing.T) {
+	err := CalendarNotFoundError("1")
+
+	assert.Equal(t, "calendar `1` not found!", err.Error())
+}
+
 func TestJobTimeoutError(t *testing.T) {
 	err := &JobTimeoutError{FullName: "1:job", Timeout: "1s", Err: errors.New("err")}
 
 	assert.Equal(t, "job `1:job` Timeout `1s` error: err!", err.Error())
 }
+
+func TestJobArgsError(t *testing.T) {
+	err := &JobArgsError{FullName: "1:job", FuncName: "func", Fields: []ArgsFieldError{
+		{Field: "url", Description: "is required"},
+		{Description: "invalid"},
+	}}
+
+	assert.Equal(t, "job `1:job` Args of Func `func` error: `url` is required; invalid!", err.Error())
+}
+
+func TestWorkflowRunNotFoundError(t *testing.T) {
+	err := WorkflowRunNotFoundError("1")
+
+	assert.Equal(t, "workflow run `1` not found!", err.Error())
+}
+
+func TestWorkflowCycleError(t *testing.T) {
+	err := &WorkflowCycleError{FullName: "1:job", Cycle: []string{"1", "2", "1"}}
+
+	assert.Equal(t, "job `1:job` Upstreams form a cycle: `1 -> 2 -> 1`!", err.Error())
+}
+
+func TestJobScheduleError(t *testing.T) {
+	err := &JobScheduleError{FullName: "1:job", Field: "interval", Value: "2", Err: errors.New("err")}
+
+	assert.Equal(t, "job `1:job` interval `2` error: err!", err.Error())
+	assert.Equal(t, "err", errors.Unwrap(err).Error())
+}
+
+func TestInvalidCronExprError(t *testing.T) {
+	err := &InvalidCronExprError{Expr: "61 * * * *", Position: 0, Field: "minute", Reason: "err"}
+	assert.Equal(t, "cron expression `61 * * * *` minute field error at position 0: err!", err.Error())
+
+	err = &InvalidCronExprError{Expr: "* * * *", Position: 7, Reason: "err"}
+	assert.Equal(t, "cron expression `* * * *` error at position 7: err!", err.Error())
+}
+
+func TestErrSchedulerPaused(t *testing.T) {
+	assert.Equal(t, "scheduler is paused!", ErrSchedulerPaused.Error())
+}
@@ -16,7 +16,7 @@ from google.protobuf import struct_pb2 as google_dot_protobuf_dot_struct__pb2
 from google.protobuf import timestamp_pb2 as google_dot_protobuf_dot_timestamp__pb2
 
 
-DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x0fscheduler.proto\x12\tscheduler\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x13\n\x05JobId\x12\n\n\x02id\x18\x01 \x01(\t\"\xea\x02\n\x03Job\x12\n\n\x02id\x18\x01 \x01(\t\x12\x0c\n\x04name\x18\x02 \x01(\t\x12\x0c\n\x04type\x18\x03 \x01(\t\x12\x10\n\x08start_at\x18\x04 \x01(\t\x12\x0e\n\x06\x65nd_at\x18\x05 \x01(\t\x12\x10\n\x08interval\x18\x06 \x01(\t\x12\x11\n\tcron_expr\x18\x07 \x01(\t\x12\x10\n\x08timezone\x18\x08 \x01(\t\x12\x11\n\tfunc_name\x18\t \x01(\t\x12%\n\x04\x61rgs\x18\n \x01(\x0b\x32\x17.google.protobuf.Struct\x12\x0f\n\x07timeout\x18\x0b \x01(\t\x12\x0e\n\x06queues\x18\x0c \x03(\t\x12\x31\n\rlast_run_time\x18\r \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x31\n\rnext_run_time\x18\x0e \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x0e\n\x06status\x18\x0f \x01(\t\x12\x11\n\tscheduled\x18\x10 \x01(\x08\"$\n\x04Jobs\x12\x1c\n\x04Jobs\x18\x01 \x03(\x0b\x32\x0e.scheduler.Job2\xd3\x04\n\tScheduler\x12*\n\x06\x41\x64\x64Job\x12\x0e.scheduler.Job\x1a\x0e.scheduler.Job\"\x00\x12,\n\x06GetJob\x12\x10.scheduler.JobId\x1a\x0e.scheduler.Job\"\x00\x12\x37\n\nGetAllJobs\x12\x16.google.protobuf.Empty\x1a\x0f.scheduler.Jobs\"\x00\x12-\n\tUpdateJob\x12\x0e.scheduler.Job\x1a\x0e.scheduler.Job\"\x00\x12\x37\n\tDeleteJob\x12\x10.scheduler.JobId\x1a\x16.google.protobuf.Empty\"\x00\x12\x41\n\rDeleteAllJobs\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\"\x00\x12.\n\x08PauseJob\x12\x10.scheduler.JobId\x1a\x0e.scheduler.Job\"\x00\x12/\n\tResumeJob\x12\x10.scheduler.JobId\x1a\x0e.scheduler.Job\"\x00\x12\x32\n\x06RunJob\x12\x0e.scheduler.Job\x1a\x16.google.protobuf.Empty\"\x00\x12\x39\n\x05Start\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\"\x00\x12\x38\n\x04Stop\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\"\x00\x42\x0eZ\x0c./;schedulerb\x06proto3')
+DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x0fscheduler.proto\x12\tscheduler\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x13\n\x05JobId\x12\n\n\x02id\x18\x01 \x01(\t\"\x18\n\x08\x46uncName\x12\x0c\n\x04name\x18\x01 \x01(\t\"L\n\x07\x46uncArg\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\x0c\n\x04type\x18\x02 \x01(\t\x12\x13\n\x0b\x64\x65scription\x18\x03 \x01(\t\x12\x10\n\x08required\x18\x04 \x01(\x08\"7\n\x08\x46uncNode\x12\n\n\x02id\x18\x01 \x01(\t\x12\r\n\x05queue\x18\x02 \x01(\t\x12\x10\n\x08\x65ndpoint\x18\x03 \x01(\t\"\xa9\x01\n\x04\x46unc\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\x0f\n\x07\x61liases\x18\x02 \x03(\t\x12\x13\n\x0b\x64\x65scription\x18\x03 \x01(\t\x12 \n\x04\x61rgs\x18\x04 \x03(\x0b\x32\x12.scheduler.FuncArg\x12\'\n\x06schema\x18\x05 \x01(\x0b\x32\x17.google.protobuf.Struct\x12\"\n\x05nodes\x18\x06 \x03(\x0b\x32\x13.scheduler.FuncNode\"\'\n\x05\x46uncs\x12\x1e\n\x05\x66uncs\x18\x01 \x03(\x0b\x32\x0f.scheduler.Func\"\x9c\x02\n\x06Record\x12\n\n\x02id\x18\x01 \x01(\t\x12\x0e\n\x06job_id\x18\x02 \x01(\t\x12\x10\n\x08job_name\x18\x03 \x01(\t\x12\x11\n\tfunc_name\x18\x04 \x01(\t\x12\x0e\n\x06status\x18\x05 \x01(\t\x12,\n\x08start_at\x18\x06 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12*\n\x06\x65nd_at\x18\x07 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\'\n\x06result\x18\x08 \x01(\x0b\x32\x17.google.protobuf.Struct\x12\r\n\x05\x65rror\x18\t \x01(\t\x12\x17\n\x0fworkflow_run_id\x18\n \x01(\t\x12\x16\n\x0ethrottle_delay\x18\x0b \x01(\x01\"-\n\x07Records\x12\"\n\x07records\x18\x01 \x03(\x0b\x32\x11.scheduler.Record\"\x1b\n\rWorkflowRunId\x12\n\n\x02id\x18\x01 \x01(\t\"`\n\x0bWorkflowJob\x12\n\n\x02id\x18\x01 \x01(\t\x12\x0c\n\x04name\x18\x02 \x01(\t\x12\x11\n\tupstreams\x18\x03 \x03(\t\x12\x14\n\x0ctrigger_rule\x18\x04 \x01(\t\x12\x0e\n\x06status\x18\x05 \x01(\t\"\xbe\x01\n\x0bWorkflowRun\x12\n\n\x02id\x18\x01 \x01(\t\x12\x13\n\x0broot_job_id\x18\x02 \x01(\t\x12\x0e\n\x06status\x18\x03 \x01(\t\x12,\n\x08start_at\x18\x04 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12*\n\x06\x65nd_at\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12$\n\x04jobs\x18\x06 \x03(\x0b\x32\x16.scheduler.WorkflowJob\"\xa5\x01\n\x07Trigger\x12\x0c\n\x04type\x18\x01 \x01(\t\x12\x10\n\x08start_at\x18\x02 \x01(\t\x12\x0e\n\x06\x65nd_at\x18\x03 \x01(\t\x12\x10\n\x08interval\x18\x04 \x01(\t\x12\x11\n\tcron_expr\x18\x05 \x01(\t\x12\r\n\x05rrule\x18\x06 \x01(\t\x12\x10\n\x08operator\x18\x07 \x01(\t\x12$\n\x08triggers\x18\x08 \x03(\x0b\x32\x12.scheduler.Trigger\"\xb0\x06\n\x03Job\x12\n\n\x02id\x18\x01 \x01(\t\x12\x0c\n\x04name\x18\x02 \x01(\t\x12\x0c\n\x04type\x18\x03 \x01(\t\x12\x10\n\x08start_at\x18\x04 \x01(\t\x12\x0e\n\x06\x65nd_at\x18\x05 \x01(\t\x12\x10\n\x08interval\x18\x06 \x01(\t\x12\x11\n\tcron_expr\x18\x07 \x01(\t\x12\x10\n\x08timezone\x18\x08 \x01(\t\x12\x11\n\tfunc_name\x18\t \x01(\t\x12%\n\x04\x61rgs\x18\n \x01(\x0b\x32\x17.google.protobuf.Struct\x12\x0f\n\x07timeout\x18\x0b \x01(\t\x12\x0e\n\x06queues\x18\x0c \x03(\t\x12\x31\n\rlast_run_time\x18\r \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x31\n\rnext_run_time\x18\x0e \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x0e\n\x06status\x18\x0f \x01(\t\x12\x11\n\tscheduled\x18\x10 \x01(\x08\x12\x0c\n\x04tags\x18\x11 \x03(\t\x12\x11\n\tupstreams\x18\x12 \x03(\t\x12\x14\n\x0ctrigger_rule\x18\x13 \x01(\t\x12\x17\n\x0fworkflow_run_id\x18\x14 \x01(\t\x12\x12\n\non_success\x18\x15 \x03(\t\x12\x12\n\non_failure\x18\x16 \x03(\t\x12\x14\n\x0ctriggered_by\x18\x17 \x01(\t\x12/\n\x0etrigger_result\x18\x18 \x01(\x0b\x32\x17.google.protobuf.Struct\x12\x11\n\tcalendars\x18\x19 \x03(\t\x12\r\n\x05rrule\x18\x1a \x01(\t\x12\x10\n\x08operator\x18\x1b \x01(\t\x12$\n\x08triggers\x18\x1c \x03(\x0b\x32\x12.scheduler.Trigger\x12\x0e\n\x06jitter\x18\x1d \x01(\t\x12\x0f\n\x07\x64st_gap\x18\x1e \x01(\t\x12\x13\n\x0b\x64st_overlap\x18\x1f \x01(\t\x12\x32\n\x0f\x61llowed_windows\x18  \x03(\x0b\x32\x19.scheduler.CalendarWindow\x12\x15\n\rwindow_policy\x18! \x01(\t\x12\x18\n\x10\x63oncurrency_keys\x18\" \x03(\t\x12\x10\n\x08priority\x18# \x01(\x05\"$\n\x04Jobs\x12\x1c\n\x04Jobs\x18\x01 \x03(\x0b\x32\x0e.scheduler.Job\"v\n\x0bJobSelector\x12\x0b\n\x03ids\x18\x01 \x03(\t\x12\r\n\x05names\x18\x02 \x03(\t\x12\r\n\x05queue\x18\x03 \x01(\t\x12\x11\n\tfunc_name\x18\x04 \x01(\t\x12\x0e\n\x06status\x18\x05 \x01(\t\x12\x0c\n\x04tags\x18\x06 \x03(\t\x12\x0b\n\x03\x61ll\x18\x07 \x01(\x08\"\x1c\n\x0c\x43\x61lendarName\x12\x0c\n\x04name\x18\x01 \x01(\t\">\n\x0c\x43\x61lendarRule\x12\x0e\n\x06months\x18\x01 \x03(\x05\x12\x0c\n\x04\x64\x61ys\x18\x02 \x03(\x05\x12\x10\n\x08weekdays\x18\x03 \x03(\x05\">\n\x0e\x43\x61lendarWindow\x12\x10\n\x08weekdays\x18\x01 \x03(\x05\x12\r\n\x05start\x18\x02 \x01(\t\x12\x0b\n\x03\x65nd\x18\x03 \x01(\t\"d\n\x0e\x43\x61lendarPeriod\x12)\n\x05start\x18\x01 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\'\n\x03\x65nd\x18\x02 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"\xce\x01\n\x08\x43\x61lendar\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\x13\n\x0b\x64\x65scription\x18\x02 \x01(\t\x12\x10\n\x08timezone\x18\x03 \x01(\t\x12\r\n\x05\x64\x61tes\x18\x04 \x03(\t\x12&\n\x05rules\x18\x05 \x03(\x0b\x32\x17.scheduler.CalendarRule\x12*\n\x07windows\x18\x06 \x03(\x0b\x32\x19.scheduler.CalendarWindow\x12*\n\x07periods\x18\x07 \x03(\x0b\x32\x19.scheduler.CalendarPeriod\"3\n\tCalendars\x12&\n\tcalendars\x18\x01 \x03(\x0b\x32\x13.scheduler.Calendar\"(\n\x0b\x43\x61lendarICS\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\x0b\n\x03ics\x18\x02 \x01(\t\"b\n\x0ePreviewRequest\x12\x1b\n\x03job\x18\x01 \x01(\x0b\x32\x0e.scheduler.Job\x12\t\n\x01n\x18\x02 \x01(\x05\x12(\n\x04\x66rom\x18\x03 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"A\n\x07RunTime\x12\'\n\x03utc\x18\x01 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\r\n\x05local\x18\x02 \x01(\t\"C\n\x08RunTimes\x12\x10\n\x08timezone\x18\x01 \x01(\t\x12%\n\trun_times\x18\x02 \x03(\x0b\x32\x12.scheduler.RunTime\"5\n\nBulkResult\x12\n\n\x02id\x18\x01 \x01(\t\x12\x0c\n\x04name\x18\x02 \x01(\t\x12\r\n\x05\x65rror\x18\x03 \x01(\t\"5\n\x0b\x42ulkResults\x12&\n\x07results\x18\x01 \x03(\x0b\x32\x15.scheduler.BulkResult2\xcf\r\n\tScheduler\x12*\n\x06\x41\x64\x64Job\x12\x0e.scheduler.Job\x1a\x0e.scheduler.Job\"\x00\x12,\n\x06GetJob\x12\x10.scheduler.JobId\x1a\x0e.scheduler.Job\"\x00\x12\x37\n\nGetAllJobs\x12\x16.google.protobuf.Empty\x1a\x0f.scheduler.Jobs\"\x00\x12-\n\tUpdateJob\x12\x0e.scheduler.Job\x1a\x0e.scheduler.Job\"\x00\x12\x37\n\tDeleteJob\x12\x10.scheduler.JobId\x1a\x16.google.protobuf.Empty\"\x00\x12\x41\n\rDeleteAllJobs\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\"\x00\x12.\n\x08PauseJob\x12\x10.scheduler.JobId\x1a\x0e.scheduler.Job\"\x00\x12/\n\tResumeJob\x12\x10.scheduler.JobId\x1a\x0e.scheduler.Job\"\x00\x12\x32\n\x06RunJob\x12\x0e.scheduler.Job\x1a\x16.google.protobuf.Empty\"\x00\x12\x34\n\nGetRecords\x12\x10.scheduler.JobId\x1a\x12.scheduler.Records\"\x00\x12\x44\n\x0eGetWorkflowRun\x12\x18.scheduler.WorkflowRunId\x1a\x16.scheduler.WorkflowRun\"\x00\x12\x43\n\x0fPreviewRunTimes\x12\x19.scheduler.PreviewRequest\x1a\x13.scheduler.RunTimes\"\x00\x12\x39\n\x0bSetCalendar\x12\x13.scheduler.Calendar\x1a\x13.scheduler.Calendar\"\x00\x12=\n\x0bGetCalendar\x12\x17.scheduler.CalendarName\x1a\x13.scheduler.Calendar\"\x00\x12\x41\n\x0fGetAllCalendars\x12\x16.google.protobuf.Empty\x1a\x14.scheduler.Calendars\"\x00\x12\x43\n\x0e\x44\x65leteCalendar\x12\x17.scheduler.CalendarName\x1a\x16.google.protobuf.Empty\"\x00\x12\x42\n\x11ImportCalendarICS\x12\x16.scheduler.CalendarICS\x1a\x13.scheduler.Calendar\"\x00\x12=\n\tPauseJobs\x12\x16.scheduler.JobSelector\x1a\x16.scheduler.BulkResults\"\x00\x12>\n\nResumeJobs\x12\x16.scheduler.JobSelector\x1a\x16.scheduler.BulkResults\"\x00\x12>\n\nDeleteJobs\x12\x16.scheduler.JobSelector\x1a\x16.scheduler.BulkResults\"\x00\x12>\n\nRunJobsNow\x12\x16.scheduler.JobSelector\x1a\x16.scheduler.BulkResults\"\x00\x12?\n\rGetFuncSchema\x12\x13.scheduler.FuncName\x1a\x17.google.protobuf.Struct\"\x00\x12\x37\n\tListFuncs\x12\x16.google.protobuf.Empty\x1a\x10.scheduler.Funcs\"\x00\x12\x39\n\x05Start\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\"\x00\x12\x38\n\x04Stop\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\"\x00\x12\x39\n\x05Pause\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\"\x00\x12:\n\x06Resume\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\"\x00\x12@\n\x0cPauseCluster\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\"\x00\x12\x41\n\rResumeCluster\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\"\x00\x42\x0eZ\x0c./;schedulerb\x06proto3')
 
 _globals = globals()
 _builder.BuildMessageAndEnumDescriptors(DESCRIPTOR, _globals)
@@ -26,10 +26,58 @@ if _descriptor._USE_C_DESCRIPTORS == False:
   DESCRIPTOR._serialized_options = b'Z\014./;scheduler'
   _globals['_JOBID']._serialized_start=122
   _globals['_JOBID']._serialized_end=141
-  _globals['_JOB']._serialized_start=144
-  _globals['_JOB']._serialized_end=506
-  _globals['_JOBS']._serialized_start=508
-  _globals['_JOBS']._serialized_end=544
-  _globals['_SCHEDULER']._serialized_start=547
-  _globals['_SCHEDULER']._serialized_end=1142
+  _globals['_FUNCNAME']._serialized_start=143
+  _globals['_FUNCNAME']._serialized_end=167
+  _globals['_FUNCARG']._serialized_start=169
+  _globals['_FUNCARG']._serialized_end=245
+  _globals['_FUNCNODE']._serialized_start=247
+  _globals['_FUNCNODE']._serialized_end=302
+  _globals['_FUNC']._serialized_start=305
+  _globals['_FUNC']._serialized_end=474
+  _globals['_FUNCS']._serialized_start=476
+  _globals['_FUNCS']._serialized_end=515
+  _globals['_RECORD']._serialized_start=518
+  _globals['_RECORD']._serialized_end=802
+  _globals['_RECORDS']._serialized_start=804
+  _globals['_RECORDS']._serialized_end=849
+  _globals['_WORKFLOWRUNID']._serialized_start=851
+  _globals['_WORKFLOWRUNID']._serialized_end=878
+  _globals['_WORKFLOWJOB']._serialized_start=880
+  _globals['_WORKFLOWJOB']._serialized_end=976
+  _globals['_WORKFLOWRUN']._serialized_start=979
+  _globals['_WORKFLOWRUN']._serialized_end=1169
+  _globals['_TRIGGER']._serialized_start=1172
+  _globals['_TRIGGER']._serialized_end=1337
+  _globals['_JOB']._serialized_start=1340
+  _globals['_JOB']._serialized_end=2156
+  _globals['_JOBS']._serialized_start=2158
+  _globals['_JOBS']._serialized_end=2194
+  _globals['_JOBSELECTOR']._serialized_start=2196
+  _globals['_JOBSELECTOR']._serialized_end=2314
+  _globals['_CALENDARNAME']._serialized_start=2316
+  _globals['_CALENDARNAME']._serialized_end=2344
+  _globals['_CALENDARRULE']._serialized_start=2346
+  _globals['_CALENDARRULE']._serialized_end=2408
+  _globals['_CALENDARWINDOW']._serialized_start=2410
+  _globals['_CALENDARWINDOW']._serialized_end=2472
+  _globals['_CALENDARPERIOD']._serialized_start=2474
+  _globals['_CALENDARPERIOD']._serialized_end=2574
+  _globals['_CALENDAR']._serialized_start=2577
+  _globals['_CALENDAR']._serialized_end=2783
+  _globals['_CALENDARS']._serialized_start=2785
+  _globals['_CALENDARS']._serialized_end=2836
+  _globals['_CALENDARICS']._serialized_start=2838
+  _globals['_CALENDARICS']._serialized_end=2878
+  _globals['_PREVIEWREQUEST']._serialized_start=2880
+  _globals['_PREVIEWREQUEST']._serialized_end=2978
+  _globals['_RUNTIME']._serialized_start=2980
+  _globals['_RUNTIME']._serialized_end=3045
+  _globals['_RUNTIMES']._serialized_start=3047
+  _globals['_RUNTIMES']._serialized_end=3114
+  _globals['_BULKRESULT']._serialized_start=3116
+  _globals['_BULKRESULT']._serialized_end=3169
+  _globals['_BULKRESULTS']._serialized_start=3171
+  _globals['_BULKRESULTS']._serialized_end=3224
+  _globals['_SCHEDULER']._serialized_start=3227
+  _globals['_SCHEDULER']._serialized_end=4970
 # @@protoc_insertion_point(module_scope)
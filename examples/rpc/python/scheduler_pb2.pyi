@@ -14,8 +14,146 @@ class JobId(_message.Message):
     id: str
     def __init__(self, id: _Optional[str] = ...) -> None: ...
 
+class FuncName(_message.Message):
+    __slots__ = ["name"]
+    NAME_FIELD_NUMBER: _ClassVar[int]
+    name: str
+    def __init__(self, name: _Optional[str] = ...) -> None: ...
+
+class FuncArg(_message.Message):
+    __slots__ = ["name", "type", "description", "required"]
+    NAME_FIELD_NUMBER: _ClassVar[int]
+    TYPE_FIELD_NUMBER: _ClassVar[int]
+    DESCRIPTION_FIELD_NUMBER: _ClassVar[int]
+    REQUIRED_FIELD_NUMBER: _ClassVar[int]
+    name: str
+    type: str
+    description: str
+    required: bool
+    def __init__(self, name: _Optional[str] = ..., type: _Optional[str] = ..., description: _Optional[str] = ..., required: bool = ...) -> None: ...
+
+class FuncNode(_message.Message):
+    __slots__ = ["id", "queue", "endpoint"]
+    ID_FIELD_NUMBER: _ClassVar[int]
+    QUEUE_FIELD_NUMBER: _ClassVar[int]
+    ENDPOINT_FIELD_NUMBER: _ClassVar[int]
+    id: str
+    queue: str
+    endpoint: str
+    def __init__(self, id: _Optional[str] = ..., queue: _Optional[str] = ..., endpoint: _Optional[str] = ...) -> None: ...
+
+class Func(_message.Message):
+    __slots__ = ["name", "aliases", "description", "args", "schema", "nodes"]
+    NAME_FIELD_NUMBER: _ClassVar[int]
+    ALIASES_FIELD_NUMBER: _ClassVar[int]
+    DESCRIPTION_FIELD_NUMBER: _ClassVar[int]
+    ARGS_FIELD_NUMBER: _ClassVar[int]
+    SCHEMA_FIELD_NUMBER: _ClassVar[int]
+    NODES_FIELD_NUMBER: _ClassVar[int]
+    name: str
+    aliases: _containers.RepeatedScalarFieldContainer[str]
+    description: str
+    args: _containers.RepeatedCompositeFieldContainer[FuncArg]
+    schema: _struct_pb2.Struct
+    nodes: _containers.RepeatedCompositeFieldContainer[FuncNode]
+    def __init__(self, name: _Optional[str] = ..., aliases: _Optional[_Iterable[str]] = ..., description: _Optional[str] = ..., args: _Optional[_Iterable[_Union[FuncArg, _Mapping]]] = ..., schema: _Optional[_Union[_struct_pb2.Struct, _Mapping]] = ..., nodes: _Optional[_Iterable[_Union[FuncNode, _Mapping]]] = ...) -> None: ...
+
+class Funcs(_message.Message):
+    __slots__ = ["funcs"]
+    FUNCS_FIELD_NUMBER: _ClassVar[int]
+    funcs: _containers.RepeatedCompositeFieldContainer[Func]
+    def __init__(self, funcs: _Optional[_Iterable[_Union[Func, _Mapping]]] = ...) -> None: ...
+
+class Record(_message.Message):
+    __slots__ = ["id", "job_id", "job_name", "func_name", "status", "start_at", "end_at", "result", "error", "workflow_run_id", "throttle_delay"]
+    ID_FIELD_NUMBER: _ClassVar[int]
+    JOB_ID_FIELD_NUMBER: _ClassVar[int]
+    JOB_NAME_FIELD_NUMBER: _ClassVar[int]
+    FUNC_NAME_FIELD_NUMBER: _ClassVar[int]
+    STATUS_FIELD_NUMBER: _ClassVar[int]
+    START_AT_FIELD_NUMBER: _ClassVar[int]
+    END_AT_FIELD_NUMBER: _ClassVar[int]
+    RESULT_FIELD_NUMBER: _ClassVar[int]
+    ERROR_FIELD_NUMBER: _ClassVar[int]
+    WORKFLOW_RUN_ID_FIELD_NUMBER: _ClassVar[int]
+    THROTTLE_DELAY_FIELD_NUMBER: _ClassVar[int]
+    id: str
+    job_id: str
+    job_name: str
+    func_name: str
+    status: str
+    start_at: _timestamp_pb2.Timestamp
+    end_at: _timestamp_pb2.Timestamp
+    result: _struct_pb2.Struct
+    error: str
+    workflow_run_id: str
+    throttle_delay: float
+    def __init__(self, id: _Optional[str] = ..., job_id: _Optional[str] = ..., job_name: _Optional[str] = ..., func_name: _Optional[str] = ..., status: _Optional[str] = ..., start_at: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ..., end_at: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ..., result: _Optional[_Union[_struct_pb2.Struct, _Mapping]] = ..., error: _Optional[str] = ..., workflow_run_id: _Optional[str] = ..., throttle_delay: _Optional[float] = ...) -> None: ...
+
+class Records(_message.Message):
+    __slots__ = ["records"]
+    RECORDS_FIELD_NUMBER: _ClassVar[int]
+    records: _containers.RepeatedCompositeFieldContainer[Record]
+    def __init__(self, records: _Optional[_Iterable[_Union[Record, _Mapping]]] = ...) -> None: ...
+
+class WorkflowRunId(_message.Message):
+    __slots__ = ["id"]
+    ID_FIELD_NUMBER: _ClassVar[int]
+    id: str
+    def __init__(self, id: _Optional[str] = ...) -> None: ...
+
+class WorkflowJob(_message.Message):
+    __slots__ = ["id", "name", "upstreams", "trigger_rule", "status"]
+    ID_FIELD_NUMBER: _ClassVar[int]
+    NAME_FIELD_NUMBER: _ClassVar[int]
+    UPSTREAMS_FIELD_NUMBER: _ClassVar[int]
+    TRIGGER_RULE_FIELD_NUMBER: _ClassVar[int]
+    STATUS_FIELD_NUMBER: _ClassVar[int]
+    id: str
+    name: str
+    upstreams: _containers.RepeatedScalarFieldContainer[str]
+    trigger_rule: str
+    status: str
+    def __init__(self, id: _Optional[str] = ..., name: _Optional[str] = ..., upstreams: _Optional[_Iterable[str]] = ..., trigger_rule: _Optional[str] = ..., status: _Optional[str] = ...) -> None: ...
+
+class WorkflowRun(_message.Message):
+    __slots__ = ["id", "root_job_id", "status", "start_at", "end_at", "jobs"]
+    ID_FIELD_NUMBER: _ClassVar[int]
+    ROOT_JOB_ID_FIELD_NUMBER: _ClassVar[int]
+    STATUS_FIELD_NUMBER: _ClassVar[int]
+    START_AT_FIELD_NUMBER: _ClassVar[int]
+    END_AT_FIELD_NUMBER: _ClassVar[int]
+    JOBS_FIELD_NUMBER: _ClassVar[int]
+    id: str
+    root_job_id: str
+    status: str
+    start_at: _timestamp_pb2.Timestamp
+    end_at: _timestamp_pb2.Timestamp
+    jobs: _containers.RepeatedCompositeFieldContainer[WorkflowJob]
+    def __init__(self, id: _Optional[str] = ..., root_job_id: _Optional[str] = ..., status: _Optional[str] = ..., start_at: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ..., end_at: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ..., jobs: _Optional[_Iterable[_Union[WorkflowJob, _Mapping]]] = ...) -> None: ...
+
+class Trigger(_message.Message):
+    __slots__ = ["type", "start_at", "end_at", "interval", "cron_expr", "rrule", "operator", "triggers"]
+    TYPE_FIELD_NUMBER: _ClassVar[int]
+    START_AT_FIELD_NUMBER: _ClassVar[int]
+    END_AT_FIELD_NUMBER: _ClassVar[int]
+    INTERVAL_FIELD_NUMBER: _ClassVar[int]
+    CRON_EXPR_FIELD_NUMBER: _ClassVar[int]
+    RRULE_FIELD_NUMBER: _ClassVar[int]
+    OPERATOR_FIELD_NUMBER: _ClassVar[int]
+    TRIGGERS_FIELD_NUMBER: _ClassVar[int]
+    type: str
+    start_at: str
+    end_at: str
+    interval: str
+    cron_expr: str
+    rrule: str
+    operator: str
+    triggers: _containers.RepeatedCompositeFieldContainer[Trigger]
+    def __init__(self, type: _Optional[str] = ..., start_at: _Optional[str] = ..., end_at: _Optional[str] = ..., interval: _Optional[str] = ..., cron_expr: _Optional[str] = ..., rrule: _Optional[str] = ..., operator: _Optional[str] = ..., triggers: _Optional[_Iterable[_Union[Trigger, _Mapping]]] = ...) -> None: ...
+
 class Job(_message.Message):
-    __slots__ = ["id", "name", "type", "start_at", "end_at", "interval", "cron_expr", "timezone", "func_name", "args", "timeout", "queues", "last_run_time", "next_run_time", "status", "scheduled"]
+    __slots__ = ["id", "name", "type", "start_at", "end_at", "interval", "cron_expr", "timezone", "func_name", "args", "timeout", "queues", "last_run_time", "next_run_time", "status", "scheduled", "tags", "upstreams", "trigger_rule", "workflow_run_id", "on_success", "on_failure", "triggered_by", "trigger_result", "calendars", "rrule", "operator", "triggers", "jitter", "dst_gap", "dst_overlap", "allowed_windows", "window_policy", "concurrency_keys", "priority"]
     ID_FIELD_NUMBER: _ClassVar[int]
     NAME_FIELD_NUMBER: _ClassVar[int]
     TYPE_FIELD_NUMBER: _ClassVar[int]
@@ -32,6 +170,25 @@ class Job(_message.Message):
     NEXT_RUN_TIME_FIELD_NUMBER: _ClassVar[int]
     STATUS_FIELD_NUMBER: _ClassVar[int]
     SCHEDULED_FIELD_NUMBER: _ClassVar[int]
+    TAGS_FIELD_NUMBER: _ClassVar[int]
+    UPSTREAMS_FIELD_NUMBER: _ClassVar[int]
+    TRIGGER_RULE_FIELD_NUMBER: _ClassVar[int]
+    WORKFLOW_RUN_ID_FIELD_NUMBER: _ClassVar[int]
+    ON_SUCCESS_FIELD_NUMBER: _ClassVar[int]
+    ON_FAILURE_FIELD_NUMBER: _ClassVar[int]
+    TRIGGERED_BY_FIELD_NUMBER: _ClassVar[int]
+    TRIGGER_RESULT_FIELD_NUMBER: _ClassVar[int]
+    CALENDARS_FIELD_NUMBER: _ClassVar[int]
+    RRULE_FIELD_NUMBER: _ClassVar[int]
+    OPERATOR_FIELD_NUMBER: _ClassVar[int]
+    TRIGGERS_FIELD_NUMBER: _ClassVar[int]
+    JITTER_FIELD_NUMBER: _ClassVar[int]
+    DST_GAP_FIELD_NUMBER: _ClassVar[int]
+    DST_OVERLAP_FIELD_NUMBER: _ClassVar[int]
+    ALLOWED_WINDOWS_FIELD_NUMBER: _ClassVar[int]
+    WINDOW_POLICY_FIELD_NUMBER: _ClassVar[int]
+    CONCURRENCY_KEYS_FIELD_NUMBER: _ClassVar[int]
+    PRIORITY_FIELD_NUMBER: _ClassVar[int]
     id: str
     name: str
     type: str
@@ -48,10 +205,155 @@ class Job(_message.Message):
     next_run_time: _timestamp_pb2.Timestamp
     status: str
     scheduled: bool
-    def __init__(self, id: _Optional[str] = ..., name: _Optional[str] = ..., type: _Optional[str] = ..., start_at: _Optional[str] = ..., end_at: _Optional[str] = ..., interval: _Optional[str] = ..., cron_expr: _Optional[str] = ..., timezone: _Optional[str] = ..., func_name: _Optional[str] = ..., args: _Optional[_Union[_struct_pb2.Struct, _Mapping]] = ..., timeout: _Optional[str] = ..., queues: _Optional[_Iterable[str]] = ..., last_run_time: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ..., next_run_time: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ..., status: _Optional[str] = ..., scheduled: bool = ...) -> None: ...
+    tags: _containers.RepeatedScalarFieldContainer[str]
+    upstreams: _containers.RepeatedScalarFieldContainer[str]
+    trigger_rule: str
+    workflow_run_id: str
+    on_success: _containers.RepeatedScalarFieldContainer[str]
+    on_failure: _containers.RepeatedScalarFieldContainer[str]
+    triggered_by: str
+    trigger_result: _struct_pb2.Struct
+    calendars: _containers.RepeatedScalarFieldContainer[str]
+    rrule: str
+    operator: str
+    triggers: _containers.RepeatedCompositeFieldContainer[Trigger]
+    jitter: str
+    dst_gap: str
+    dst_overlap: str
+    allowed_windows: _containers.RepeatedCompositeFieldContainer[CalendarWindow]
+    window_policy: str
+    concurrency_keys: _containers.RepeatedScalarFieldContainer[str]
+    priority: int
+    def __init__(self, id: _Optional[str] = ..., name: _Optional[str] = ..., type: _Optional[str] = ..., start_at: _Optional[str] = ..., end_at: _Optional[str] = ..., interval: _Optional[str] = ..., cron_expr: _Optional[str] = ..., timezone: _Optional[str] = ..., func_name: _Optional[str] = ..., args: _Optional[_Union[_struct_pb2.Struct, _Mapping]] = ..., timeout: _Optional[str] = ..., queues: _Optional[_Iterable[str]] = ..., last_run_time: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ..., next_run_time: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ..., status: _Optional[str] = ..., scheduled: bool = ..., tags: _Optional[_Iterable[str]] = ..., upstreams: _Optional[_Iterable[str]] = ..., trigger_rule: _Optional[str] = ..., workflow_run_id: _Optional[str] = ..., on_success: _Optional[_Iterable[str]] = ..., on_failure: _Optional[_Iterable[str]] = ..., triggered_by: _Optional[str] = ..., trigger_result: _Optional[_Union[_struct_pb2.Struct, _Mapping]] = ..., calendars: _Optional[_Iterable[str]] = ..., rrule: _Optional[str] = ..., operator: _Optional[str] = ..., triggers: _Optional[_Iterable[_Union[Trigger, _Mapping]]] = ..., jitter: _Optional[str] = ..., dst_gap: _Optional[str] = ..., dst_overlap: _Optional[str] = ..., allowed_windows: _Optional[_Iterable[_Union[CalendarWindow, _Mapping]]] = ..., window_policy: _Optional[str] = ..., concurrency_keys: _Optional[_Iterable[str]] = ..., priority: _Optional[int] = ...) -> None: ...
 
 class Jobs(_message.Message):
     __slots__ = ["Jobs"]
     JOBS_FIELD_NUMBER: _ClassVar[int]
     Jobs: _containers.RepeatedCompositeFieldContainer[Job]
     def __init__(self, Jobs: _Optional[_Iterable[_Union[Job, _Mapping]]] = ...) -> None: ...
+
+class JobSelector(_message.Message):
+    __slots__ = ["ids", "names", "queue", "func_name", "status", "tags", "all"]
+    IDS_FIELD_NUMBER: _ClassVar[int]
+    NAMES_FIELD_NUMBER: _ClassVar[int]
+    QUEUE_FIELD_NUMBER: _ClassVar[int]
+    FUNC_NAME_FIELD_NUMBER: _ClassVar[int]
+    STATUS_FIELD_NUMBER: _ClassVar[int]
+    TAGS_FIELD_NUMBER: _ClassVar[int]
+    ALL_FIELD_NUMBER: _ClassVar[int]
+    ids: _containers.RepeatedScalarFieldContainer[str]
+    names: _containers.RepeatedScalarFieldContainer[str]
+    queue: str
+    func_name: str
+    status: str
+    tags: _containers.RepeatedScalarFieldContainer[str]
+    all: bool
+    def __init__(self, ids: _Optional[_Iterable[str]] = ..., names: _Optional[_Iterable[str]] = ..., queue: _Optional[str] = ..., func_name: _Optional[str] = ..., status: _Optional[str] = ..., tags: _Optional[_Iterable[str]] = ..., all: bool = ...) -> None: ...
+
+class CalendarName(_message.Message):
+    __slots__ = ["name"]
+    NAME_FIELD_NUMBER: _ClassVar[int]
+    name: str
+    def __init__(self, name: _Optional[str] = ...) -> None: ...
+
+class CalendarRule(_message.Message):
+    __slots__ = ["months", "days", "weekdays"]
+    MONTHS_FIELD_NUMBER: _ClassVar[int]
+    DAYS_FIELD_NUMBER: _ClassVar[int]
+    WEEKDAYS_FIELD_NUMBER: _ClassVar[int]
+    months: _containers.RepeatedScalarFieldContainer[int]
+    days: _containers.RepeatedScalarFieldContainer[int]
+    weekdays: _containers.RepeatedScalarFieldContainer[int]
+    def __init__(self, months: _Optional[_Iterable[int]] = ..., days: _Optional[_Iterable[int]] = ..., weekdays: _Optional[_Iterable[int]] = ...) -> None: ...
+
+class CalendarWindow(_message.Message):
+    __slots__ = ["weekdays", "start", "end"]
+    WEEKDAYS_FIELD_NUMBER: _ClassVar[int]
+    START_FIELD_NUMBER: _ClassVar[int]
+    END_FIELD_NUMBER: _ClassVar[int]
+    weekdays: _containers.RepeatedScalarFieldContainer[int]
+    start: str
+    end: str
+    def __init__(self, weekdays: _Optional[_Iterable[int]] = ..., start: _Optional[str] = ..., end: _Optional[str] = ...) -> None: ...
+
+class CalendarPeriod(_message.Message):
+    __slots__ = ["start", "end"]
+    START_FIELD_NUMBER: _ClassVar[int]
+    END_FIELD_NUMBER: _ClassVar[int]
+    start: _timestamp_pb2.Timestamp
+    end: _timestamp_pb2.Timestamp
+    def __init__(self, start: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ..., end: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ...) -> None: ...
+
+class Calendar(_message.Message):
+    __slots__ = ["name", "description", "timezone", "dates", "rules", "windows", "periods"]
+    NAME_FIELD_NUMBER: _ClassVar[int]
+    DESCRIPTION_FIELD_NUMBER: _ClassVar[int]
+    TIMEZONE_FIELD_NUMBER: _ClassVar[int]
+    DATES_FIELD_NUMBER: _ClassVar[int]
+    RULES_FIELD_NUMBER: _ClassVar[int]
+    WINDOWS_FIELD_NUMBER: _ClassVar[int]
+    PERIODS_FIELD_NUMBER: _ClassVar[int]
+    name: str
+    description: str
+    timezone: str
+    dates: _containers.RepeatedScalarFieldContainer[str]
+    rules: _containers.RepeatedCompositeFieldContainer[CalendarRule]
+    windows: _containers.RepeatedCompositeFieldContainer[CalendarWindow]
+    periods: _containers.RepeatedCompositeFieldContainer[CalendarPeriod]
+    def __init__(self, name: _Optional[str] = ..., description: _Optional[str] = ..., timezone: _Optional[str] = ..., dates: _Optional[_Iterable[str]] = ..., rules: _Optional[_Iterable[_Union[CalendarRule, _Mapping]]] = ..., windows: _Optional[_Iterable[_Union[CalendarWindow, _Mapping]]] = ..., periods: _Optional[_Iterable[_Union[CalendarPeriod, _Mapping]]] = ...) -> None: ...
+
+class Calendars(_message.Message):
+    __slots__ = ["calendars"]
+    CALENDARS_FIELD_NUMBER: _ClassVar[int]
+    calendars: _containers.RepeatedCompositeFieldContainer[Calendar]
+    def __init__(self, calendars: _Optional[_Iterable[_Union[Calendar, _Mapping]]] = ...) -> None: ...
+
+class CalendarICS(_message.Message):
+    __slots__ = ["name", "ics"]
+    NAME_FIELD_NUMBER: _ClassVar[int]
+    ICS_FIELD_NUMBER: _ClassVar[int]
+    name: str
+    ics: str
+    def __init__(self, name: _Optional[str] = ..., ics: _Optional[str] = ...) -> None: ...
+
+class PreviewRequest(_message.Message):
+    __slots__ = ["job", "n", "from"]
+    JOB_FIELD_NUMBER: _ClassVar[int]
+    N_FIELD_NUMBER: _ClassVar[int]
+    FROM_FIELD_NUMBER: _ClassVar[int]
+    job: Job
+    n: int
+    from: _timestamp_pb2.Timestamp
+    def __init__(self, job: _Optional[_Union[Job, _Mapping]] = ..., n: _Optional[int] = ..., from: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ...) -> None: ...
+
+class RunTime(_message.Message):
+    __slots__ = ["utc", "local"]
+    UTC_FIELD_NUMBER: _ClassVar[int]
+    LOCAL_FIELD_NUMBER: _ClassVar[int]
+    utc: _timestamp_pb2.Timestamp
+    local: str
+    def __init__(self, utc: _Optional[_Union[_timestamp_pb2.Timestamp, _Mapping]] = ..., local: _Optional[str] = ...) -> None: ...
+
+class RunTimes(_message.Message):
+    __slots__ = ["timezone", "run_times"]
+    TIMEZONE_FIELD_NUMBER: _ClassVar[int]
+    RUN_TIMES_FIELD_NUMBER: _ClassVar[int]
+    timezone: str
+    run_times: _containers.RepeatedCompositeFieldContainer[RunTime]
+    def __init__(self, timezone: _Optional[str] = ..., run_times: _Optional[_Iterable[_Union[RunTime, _Mapping]]] = ...) -> None: ...
+
+class BulkResult(_message.Message):
+    __slots__ = ["id", "name", "error"]
+    ID_FIELD_NUMBER: _ClassVar[int]
+    NAME_FIELD_NUMBER: _ClassVar[int]
+    ERROR_FIELD_NUMBER: _ClassVar[int]
+    id: str
+    name: str
+    error: str
+    def __init__(self, id: _Optional[str] = ..., name: _Optional[str] = ..., error: _Optional[str] = ...) -> None: ...
+
+class BulkResults(_message.Message):
+    __slots__ = ["results"]
+    RESULTS_FIELD_NUMBER: _ClassVar[int]
+    results: _containers.RepeatedCompositeFieldContainer[BulkResult]
+    def __init__(self, results: _Optional[_Iterable[_Union[BulkResult, _Mapping]]] = ...) -> None: ...
@@ -0,0 +1,62 @@
+# 1. go run examples/rpc/rpc_server.go
+# 2. python3 examples/rpc/python/worker_client.py
+# 3. python3 examples/rpc/python/rpc_client.py, with func_name="python.print_msg"
+
+import queue
+import threading
+
+import grpc
+from google.protobuf.struct_pb2 import Struct
+
+import worker_pb2
+import worker_pb2_grpc
+
+FUNCS = {}
+
+
+def register(name):
+    def wrap(f):
+        FUNCS[name] = f
+        return f
+
+    return wrap
+
+
+@register("python.print_msg")
+def print_msg(job):
+    print(f"Run job `{job.id}:{job.name}` {dict(job.args)}")
+    return {"printed": True}
+
+
+def messages(q):
+    yield worker_pb2.WorkerMessage(hello=worker_pb2.WorkerHello(funcs=list(FUNCS)))
+    while True:
+        try:
+            yield q.get(timeout=10)
+        except queue.Empty:
+            yield worker_pb2.WorkerMessage(heartbeat=worker_pb2.WorkerHeartbeat())
+
+
+def execute(q, run):
+    result, error = Struct(), ""
+    try:
+        result.update(FUNCS[run.job.func_name](run.job) or {})
+    except Exception as e:
+        error = str(e)
+    q.put(
+        worker_pb2.WorkerMessage(
+            result=worker_pb2.WorkerResult(run_id=run.run_id, result=result, error=error)
+        )
+    )
+
+
+def run():
+    q = queue.Queue()
+    with grpc.insecure_channel("127.0.0.1:36360") as channel:
+        stub = worker_pb2_grpc.WorkerStub(channel)
+        for r in stub.Connect(messages(q)):
+            threading.Thread(target=execute, args=(q, r), daemon=True).start()
+
+
+if __name__ == "__main__":
+    run()
@@ -0,0 +1,38 @@
+# -*- coding: utf-8 -*-
+# Generated by the protocol buffer compiler.  DO NOT EDIT!
+# source: worker.proto
+"""Generated protocol buffer code."""
+from google.protobuf import descriptor as _descriptor
+from google.protobuf import descriptor_pool as _descriptor_pool
+from google.protobuf import symbol_database as _symbol_database
+from google.protobuf.internal import builder as _builder
+# @@protoc_insertion_point(imports)
+
+_sym_db = _symbol_database.Default()
+
+
+from google.protobuf import struct_pb2 as google_dot_protobuf_dot_struct__pb2
+import scheduler_pb2 as scheduler__pb2
+
+
+DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x0cworker.proto\x12\tscheduler\x1a\x1cgoogle/protobuf/struct.proto\x1a\x0fscheduler.proto\"(\n\x0bWorkerHello\x12\n\n\x02id\x18\x01 \x01(\t\x12\r\n\x05\x66uncs\x18\x02 \x03(\t\"V\n\x0cWorkerResult\x12\x0e\n\x06run_id\x18\x01 \x01(\t\x12\'\n\x06result\x18\x02 \x01(\x0b\x32\x17.google.protobuf.Struct\x12\r\n\x05\x65rror\x18\x03 \x01(\t\"\x11\n\x0fWorkerHeartbeat\"\x9f\x01\n\rWorkerMessage\x12\'\n\x05hello\x18\x01 \x01(\x0b\x32\x16.scheduler.WorkerHelloH\x00\x12)\n\x06result\x18\x02 \x01(\x0b\x32\x17.scheduler.WorkerResultH\x00\x12/\n\theartbeat\x18\x03 \x01(\x0b\x32\x1a.scheduler.WorkerHeartbeatH\x00\x42\t\n\x07message\"8\n\tWorkerRun\x12\x0e\n\x06run_id\x18\x01 \x01(\t\x12\x1b\n\x03job\x18\x02 \x01(\x0b\x32\x0e.scheduler.Job2I\n\x06Worker\x12?\n\x07\x43onnect\x12\x18.scheduler.WorkerMessage\x1a\x14.scheduler.WorkerRun\"\x00(\x01\x30\x01\x42\x0eZ\x0c./;schedulerb\x06proto3')
+
+_globals = globals()
+_builder.BuildMessageAndEnumDescriptors(DESCRIPTOR, _globals)
+_builder.BuildTopDescriptorsAndMessages(DESCRIPTOR, 'worker_pb2', _globals)
+if _descriptor._USE_C_DESCRIPTORS == False:
+  DESCRIPTOR._options = None
+  DESCRIPTOR._serialized_options = b'Z\014./;scheduler'
+  _globals['_WORKERHELLO']._serialized_start=74
+  _globals['_WORKERHELLO']._serialized_end=114
+  _globals['_WORKERRESULT']._serialized_start=116
+  _globals['_WORKERRESULT']._serialized_end=202
+  _globals['_WORKERHEARTBEAT']._serialized_start=204
+  _globals['_WORKERHEARTBEAT']._serialized_end=221
+  _globals['_WORKERMESSAGE']._serialized_start=224
+  _globals['_WORKERMESSAGE']._serialized_end=383
+  _globals['_WORKERRUN']._serialized_start=385
+  _globals['_WORKERRUN']._serialized_end=441
+  _globals['_WORKER']._serialized_start=443
+  _globals['_WORKER']._serialized_end=516
+# @@protoc_insertion_point(module_scope)
@@ -0,0 +1,48 @@
+from google.protobuf import struct_pb2 as _struct_pb2
+import scheduler_pb2 as _scheduler_pb2
+from google.protobuf.internal import containers as _containers
+from google.protobuf import descriptor as _descriptor
+from google.protobuf import message as _message
+from typing import ClassVar as _ClassVar, Iterable as _Iterable, Mapping as _Mapping, Optional as _Optional, Union as _Union
+
+DESCRIPTOR: _descriptor.FileDescriptor
+
+class WorkerHello(_message.Message):
+    __slots__ = ["id", "funcs"]
+    ID_FIELD_NUMBER: _ClassVar[int]
+    FUNCS_FIELD_NUMBER: _ClassVar[int]
+    id: str
+    funcs: _containers.RepeatedScalarFieldContainer[str]
+    def __init__(self, id: _Optional[str] = ..., funcs: _Optional[_Iterable[str]] = ...) -> None: ...
+
+class WorkerResult(_message.Message):
+    __slots__ = ["run_id", "result", "error"]
+    RUN_ID_FIELD_NUMBER: _ClassVar[int]
+    RESULT_FIELD_NUMBER: _ClassVar[int]
+    ERROR_FIELD_NUMBER: _ClassVar[int]
+    run_id: str
+    result: _struct_pb2.Struct
+    error: str
+    def __init__(self, run_id: _Optional[str] = ..., result: _Optional[_Union[_struct_pb2.Struct, _Mapping]] = ..., error: _Optional[str] = ...) -> None: ...
+
+class WorkerHeartbeat(_message.Message):
+    __slots__ = []
+    def __init__(self) -> None: ...
+
+class WorkerMessage(_message.Message):
+    __slots__ = ["hello", "result", "heartbeat"]
+    HELLO_FIELD_NUMBER: _ClassVar[int]
+    RESULT_FIELD_NUMBER: _ClassVar[int]
+    HEARTBEAT_FIELD_NUMBER: _ClassVar[int]
+    hello: WorkerHello
+    result: WorkerResult
+    heartbeat: WorkerHeartbeat
+    def __init__(self, hello: _Optional[_Union[WorkerHello, _Mapping]] = ..., result: _Optional[_Union[WorkerResult, _Mapping]] = ..., heartbeat: _Optional[_Union[WorkerHeartbeat, _Mapping]] = ...) -> None: ...
+
+class WorkerRun(_message.Message):
+    __slots__ = ["run_id", "job"]
+    RUN_ID_FIELD_NUMBER: _ClassVar[int]
+    JOB_FIELD_NUMBER: _ClassVar[int]
+    run_id: str
+    job: _scheduler_pb2.Job
+    def __init__(self, run_id: _Optional[str] = ..., job: _Optional[_Union[_scheduler_pb2.Job, _Mapping]] = ...) -> None: ...
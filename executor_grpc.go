@@ -0,0 +1,293 @@
+package agscheduler
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// The `FuncName` of the built-in gRPC function, registered by `RegisterGRPC`.
+const FUNC_GRPC = "builtin.grpc"
+
+// `Args` of the built-in gRPC function.
+type GRPCArgs struct {
+	Target string `json:"target" description:"Address of the gRPC server, such as 127.0.0.1:36360"`
+	// Such as `services.Scheduler/GetJob`.
+	Method  string         `json:"method" description:"Full method name, <service>/<method>"`
+	Request map[string]any `json:"request,omitempty" description:"Request message in protobuf JSON"`
+	// Sent as the outgoing metadata.
+	Metadata map[string]string `json:"metadata,omitempty" description:"Request metadata"`
+	// Default: the `Timeout` of the job
+	Timeout string `json:"timeout,omitempty" description:"Call timeout, such as 10s"`
+
+	TLS                bool   `json:"tls,omitempty" description:"Connect with TLS"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty" description:"Skip verifying the server certificate"`
+	CACert             string `json:"ca_cert,omitempty" description:"PEM encoded CA certificates used to verify the server"`
+}
+
+func (a GRPCArgs) Validate() error {
+	if a.Target == "" {
+		return &ArgsFieldError{Field: "target", Description: "must not be empty"}
+	}
+	if _, _, err := splitGRPCMethod(a.Method); err != nil {
+		return &ArgsFieldError{Field: "method", Description: err.Error()}
+	}
+	if a.Timeout != "" {
+		if _, err := time.ParseDuration(a.Timeout); err != nil {
+			return &ArgsFieldError{Field: "timeout", Description: err.Error()}
+		}
+	}
+	if _, err := a.credentials(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (a GRPCArgs) credentials() (credentials.TransportCredentials, error) {
+	if !a.TLS {
+		return insecure.NewCredentials(), nil
+	}
+
+	c := &tls.Config{InsecureSkipVerify: a.InsecureSkipVerify}
+	if a.CACert != "" {
+		c.RootCAs = x509.NewCertPool()
+		if !c.RootCAs.AppendCertsFromPEM([]byte(a.CACert)) {
+			return nil, &ArgsFieldError{Field: "ca_cert", Description: "has no valid PEM certificate"}
+		}
+	}
+
+	return credentials.NewTLS(c), nil
+}
+
+// Returns the service and method names of `<service>/<method>`,
+// a leading slash is allowed.
+func splitGRPCMethod(fullMethod string) (string, string, error) {
+	service, method, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok || service == "" || method == "" || strings.Contains(method, "/") {
+		return "", "", fmt.Errorf("`%s` is not <service>/<method>", fullMethod)
+	}
+
+	return service, method, nil
+}
+
+type grpcExecutor struct {
+	// Descriptors registered by `RegisterGRPCTo`,
+	// services not found here are resolved by server reflection.
+	files *protoregistry.Files
+}
+
+// Call a unary method with dynamic messages,
+// a status other than `OK` is an error.
+func (e grpcExecutor) run(ctx context.Context, j Job, args GRPCArgs) (map[string]any, error) {
+	if args.Timeout != "" {
+		timeout, _ := time.ParseDuration(args.Timeout)
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	creds, err := args.credentials()
+	if err != nil {
+		return nil, fmt.Errorf("job `%s` gRPC TLS error: %s", j.FullName(), err)
+	}
+	conn, err := grpc.DialContext(ctx, args.Target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("job `%s` gRPC dial error: %s", j.FullName(), err)
+	}
+	defer conn.Close()
+
+	md, err := e.methodDescriptor(ctx, conn, args.Method)
+	if err != nil {
+		return nil, fmt.Errorf("job `%s` gRPC method `%s` error: %s", j.FullName(), args.Method, err)
+	}
+	if md.IsStreamingClient() || md.IsStreamingServer() {
+		return nil, fmt.Errorf("job `%s` gRPC method `%s` error: only unary methods are supported", j.FullName(), args.Method)
+	}
+
+	req := dynamicpb.NewMessage(md.Input())
+	if args.Request != nil {
+		b, err := json.Marshal(args.Request)
+		if err != nil {
+			return nil, fmt.Errorf("job `%s` gRPC request error: %s", j.FullName(), err)
+		}
+		if err := protojson.Unmarshal(b, req); err != nil {
+			return nil, fmt.Errorf("job `%s` gRPC request error: %s", j.FullName(), err)
+		}
+	}
+
+	if len(args.Metadata) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(args.Metadata))
+	}
+
+	resp := dynamicpb.NewMessage(md.Output())
+	method := fmt.Sprintf("/%s/%s", md.Parent().FullName(), md.Name())
+	if err := conn.Invoke(ctx, method, req, resp); err != nil {
+		st := status.Convert(err)
+		result := map[string]any{
+			"code":    st.Code().String(),
+			"message": st.Message(),
+		}
+		return result, fmt.Errorf("job `%s` gRPC call error: %s", j.FullName(), err)
+	}
+
+	b, err := protojson.Marshal(resp)
+	if err != nil {
+		return nil, fmt.Errorf("job `%s` gRPC response error: %s", j.FullName(), err)
+	}
+	response := map[string]any{}
+	if err := json.Unmarshal(b, &response); err != nil {
+		return nil, fmt.Errorf("job `%s` gRPC response error: %s", j.FullName(), err)
+	}
+
+	return map[string]any{
+		"code":     "OK",
+		"response": response,
+	}, nil
+}
+
+func (e grpcExecutor) methodDescriptor(ctx context.Context, conn *grpc.ClientConn, fullMethod string) (protoreflect.MethodDescriptor, error) {
+	serviceName, methodName, err := splitGRPCMethod(fullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	var d protoreflect.Descriptor
+	if e.files != nil {
+		d, _ = e.files.FindDescriptorByName(protoreflect.FullName(serviceName))
+	}
+	if d == nil {
+		files, err := reflectServiceFiles(ctx, conn, serviceName)
+		if err != nil {
+			return nil, fmt.Errorf("server reflection error: %s", err)
+		}
+		d, err = files.FindDescriptorByName(protoreflect.FullName(serviceName))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("`%s` is not a service", serviceName)
+	}
+	md := sd.Methods().ByName(protoreflect.Name(methodName))
+	if md == nil {
+		return nil, fmt.Errorf("service `%s` has no method `%s`", serviceName, methodName)
+	}
+
+	return md, nil
+}
+
+// Fetch the file declaring a service and all its dependencies by server reflection.
+func reflectServiceFiles(ctx context.Context, conn *grpc.ClientConn, serviceName string) (*protoregistry.Files, error) {
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.CloseSend()
+
+	fdps := map[string]*descriptorpb.FileDescriptorProto{}
+	request := func(req *rpb.ServerReflectionRequest) error {
+		if err := stream.Send(req); err != nil {
+			return err
+		}
+		resp, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		if errResp := resp.GetErrorResponse(); errResp != nil {
+			return fmt.Errorf("%s", errResp.GetErrorMessage())
+		}
+		for _, b := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			fdp := &descriptorpb.FileDescriptorProto{}
+			if err := proto.Unmarshal(b, fdp); err != nil {
+				return err
+			}
+			fdps[fdp.GetName()] = fdp
+		}
+		return nil
+	}
+
+	err = request(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: serviceName},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The server may leave out the files it has already sent on this stream,
+	// or only send the requested one, so fetch the missing dependencies.
+	for {
+		missing := ""
+		for _, fdp := range fdps {
+			for _, dep := range fdp.GetDependency() {
+				if _, ok := fdps[dep]; !ok {
+					missing = dep
+					break
+				}
+			}
+			if missing != "" {
+				break
+			}
+		}
+		if missing == "" {
+			break
+		}
+
+		err := request(&rpb.ServerReflectionRequest{
+			MessageRequest: &rpb.ServerReflectionRequest_FileByFilename{FileByFilename: missing},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := fdps[missing]; !ok {
+			return nil, fmt.Errorf("file `%s` not found", missing)
+		}
+	}
+
+	fds := &descriptorpb.FileDescriptorSet{}
+	for _, fdp := range fdps {
+		fds.File = append(fds.File, fdp)
+	}
+
+	return protodesc.NewFiles(fds)
+}
+
+// Register the built-in gRPC function to a registry as `FUNC_GRPC`.
+//
+// Services are looked up in `files` first, such as `protoregistry.GlobalFiles`
+// or the result of `protodesc.NewFiles` with a descriptor set,
+// and then by server reflection, `files` can be nil.
+//
+// It calls any method of any server reachable from the node,
+// so it is only registered explicitly.
+func RegisterGRPCTo(r *FuncRegistry, files *protoregistry.Files) {
+	registerTypedResultTo(r, FUNC_GRPC, grpcExecutor{files: files}.run)
+	r.Describe(FUNC_GRPC, FuncInfo{
+		Description: "Call a unary gRPC method, the JSON response is recorded as the result.",
+	})
+}
+
+// Register the built-in gRPC function to the default registry.
+func RegisterGRPC(files *protoregistry.Files) {
+	RegisterGRPCTo(defaultFuncRegistry, files)
+}
@@ -0,0 +1,105 @@
+package agscheduler
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+func startGRPCHealth(t *testing.T, withReflection bool) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	s := grpc.NewServer()
+	hs := health.NewServer()
+	hs.SetServingStatus("agscheduler", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	grpc_health_v1.RegisterHealthServer(s, hs)
+	if withReflection {
+		reflection.Register(s)
+	}
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	return lis.Addr().String()
+}
+
+func TestGRPCArgsValidate(t *testing.T) {
+	assert.NoError(t, GRPCArgs{Target: "127.0.0.1:36360", Method: "grpc.health.v1.Health/Check"}.Validate())
+	assert.NoError(t, GRPCArgs{Target: "127.0.0.1:36360", Method: "/grpc.health.v1.Health/Check"}.Validate())
+	assert.Error(t, GRPCArgs{Method: "grpc.health.v1.Health/Check"}.Validate())
+	assert.Error(t, GRPCArgs{Target: "127.0.0.1:36360", Method: "Check"}.Validate())
+	assert.Error(t, GRPCArgs{Target: "127.0.0.1:36360", Method: "a/b/c"}.Validate())
+	assert.Error(t, GRPCArgs{Target: "127.0.0.1:36360", Method: "a/b", Timeout: "1"}.Validate())
+	assert.Error(t, GRPCArgs{Target: "127.0.0.1:36360", Method: "a/b", TLS: true, CACert: "ca"}.Validate())
+}
+
+func TestRunGRPCReflection(t *testing.T) {
+	target := startGRPCHealth(t, true)
+	e := grpcExecutor{}
+
+	result, err := e.run(context.TODO(), Job{}, GRPCArgs{
+		Target:  target,
+		Method:  "grpc.health.v1.Health/Check",
+		Request: map[string]any{"service": "agscheduler"},
+		Timeout: "1s",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "OK", result["code"])
+	assert.Equal(t, map[string]any{"status": "NOT_SERVING"}, result["response"])
+
+	result, err = e.run(context.TODO(), Job{}, GRPCArgs{
+		Target:  target,
+		Method:  "grpc.health.v1.Health/Check",
+		Request: map[string]any{"service": "unknown"},
+		Timeout: "1s",
+	})
+	assert.Error(t, err)
+	assert.Equal(t, "NotFound", result["code"])
+
+	_, err = e.run(context.TODO(), Job{}, GRPCArgs{Target: target, Method: "grpc.health.v1.Health/Unknown", Timeout: "1s"})
+	assert.Error(t, err)
+	_, err = e.run(context.TODO(), Job{}, GRPCArgs{Target: target, Method: "grpc.health.v1.Health/Watch", Timeout: "1s"})
+	assert.Error(t, err)
+	_, err = e.run(context.TODO(), Job{}, GRPCArgs{
+		Target:  target,
+		Method:  "grpc.health.v1.Health/Check",
+		Request: map[string]any{"unknown": 1},
+		Timeout: "1s",
+	})
+	assert.Error(t, err)
+}
+
+func TestRunGRPCDescriptors(t *testing.T) {
+	target := startGRPCHealth(t, false)
+	args := GRPCArgs{
+		Target:  target,
+		Method:  "grpc.health.v1.Health/Check",
+		Request: map[string]any{"service": "agscheduler"},
+		Timeout: "1s",
+	}
+
+	_, err := grpcExecutor{}.run(context.TODO(), Job{}, args)
+	assert.Error(t, err)
+
+	result, err := grpcExecutor{files: protoregistry.GlobalFiles}.run(context.TODO(), Job{}, args)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"status": "NOT_SERVING"}, result["response"])
+}
+
+func TestRegisterGRPCTo(t *testing.T) {
+	r := NewFuncRegistry()
+	RegisterGRPCTo(r, nil)
+
+	rf, ok := r.get(FUNC_GRPC)
+	assert.True(t, ok)
+	assert.NotEmpty(t, rf.info.Description)
+	assert.Len(t, rf.validate(map[string]any{}), 2)
+	assert.Empty(t, rf.validate(map[string]any{"target": "127.0.0.1:36360", "method": "a/b"}))
+}
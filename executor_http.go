@@ -0,0 +1,204 @@
+package agscheduler
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// The `FuncName` of the built-in HTTP function, registered by `RegisterHTTP`.
+const FUNC_HTTP = "builtin.http"
+
+// `Args` of the built-in HTTP function.
+type HTTPArgs struct {
+	// Default: `GET`
+	Method  string            `json:"method,omitempty" description:"HTTP method"`
+	URL     string            `json:"url" description:"Request URL, http or https"`
+	Headers map[string]string `json:"headers,omitempty" description:"Request headers"`
+	// Executed by `text/template` with the job, such as `{"id": {{json .Id}}}`.
+	Body string `json:"body,omitempty" description:"Request body template, the job is its data"`
+	// Default: any 2xx status
+	ExpectedStatus []int `json:"expected_status,omitempty" description:"Status codes counted as success"`
+	// Default: the `Timeout` of the job
+	Timeout string `json:"timeout,omitempty" description:"Request timeout, such as 10s"`
+	// Default: `4096`
+	MaxBody int `json:"max_body,omitempty" description:"Bytes of the response body kept in the result"`
+
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty" description:"Skip verifying the server certificate"`
+	CACert             string `json:"ca_cert,omitempty" description:"PEM encoded CA certificates used to verify the server"`
+	ClientCert         string `json:"client_cert,omitempty" description:"PEM encoded client certificate"`
+	ClientKey          string `json:"client_key,omitempty" description:"PEM encoded client private key"`
+}
+
+func (a HTTPArgs) Validate() error {
+	u, err := url.Parse(a.URL)
+	if err != nil {
+		return &ArgsFieldError{Field: "url", Description: err.Error()}
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return &ArgsFieldError{Field: "url", Description: "must be an http or https URL"}
+	}
+	if strings.ContainsAny(a.Method, " \t\r\n") {
+		return &ArgsFieldError{Field: "method", Description: "is invalid"}
+	}
+	if _, err := a.bodyTemplate(); err != nil {
+		return &ArgsFieldError{Field: "body", Description: err.Error()}
+	}
+	for _, code := range a.ExpectedStatus {
+		if code < 100 || code > 599 {
+			return &ArgsFieldError{Field: "expected_status", Description: fmt.Sprintf("`%d` is not a status code", code)}
+		}
+	}
+	if a.Timeout != "" {
+		if _, err := time.ParseDuration(a.Timeout); err != nil {
+			return &ArgsFieldError{Field: "timeout", Description: err.Error()}
+		}
+	}
+	if a.MaxBody < 0 {
+		return &ArgsFieldError{Field: "max_body", Description: "must not be negative"}
+	}
+	if _, err := a.tlsConfig(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (a HTTPArgs) bodyTemplate() (*template.Template, error) {
+	return template.New("body").Funcs(template.FuncMap{
+		"json": func(v any) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}).Parse(a.Body)
+}
+
+func (a HTTPArgs) tlsConfig() (*tls.Config, error) {
+	c := &tls.Config{InsecureSkipVerify: a.InsecureSkipVerify}
+
+	if a.CACert != "" {
+		c.RootCAs = x509.NewCertPool()
+		if !c.RootCAs.AppendCertsFromPEM([]byte(a.CACert)) {
+			return nil, &ArgsFieldError{Field: "ca_cert", Description: "has no valid PEM certificate"}
+		}
+	}
+
+	if (a.ClientCert == "") != (a.ClientKey == "") {
+		return nil, errors.New("`client_cert` and `client_key` must be set together")
+	}
+	if a.ClientCert != "" {
+		cert, err := tls.X509KeyPair([]byte(a.ClientCert), []byte(a.ClientKey))
+		if err != nil {
+			return nil, &ArgsFieldError{Field: "client_cert", Description: err.Error()}
+		}
+		c.Certificates = []tls.Certificate{cert}
+	}
+
+	return c, nil
+}
+
+// Send a request, a response with an unexpected status is an error,
+// so that it is notified like other failed jobs.
+func runHTTP(ctx context.Context, j Job, args HTTPArgs) (map[string]any, error) {
+	if args.Timeout != "" {
+		timeout, _ := time.ParseDuration(args.Timeout)
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	tmpl, err := args.bodyTemplate()
+	if err != nil {
+		return nil, fmt.Errorf("job `%s` HTTP body error: %s", j.FullName(), err)
+	}
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, j); err != nil {
+		return nil, fmt.Errorf("job `%s` HTTP body error: %s", j.FullName(), err)
+	}
+
+	method := args.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(method), args.URL, &body)
+	if err != nil {
+		return nil, fmt.Errorf("job `%s` HTTP request error: %s", j.FullName(), err)
+	}
+	for k, v := range args.Headers {
+		req.Header.Set(k, v)
+	}
+	if v, ok := args.Headers["Host"]; ok {
+		req.Host = v
+	}
+
+	tlsConfig, err := args.tlsConfig()
+	if err != nil {
+		return nil, fmt.Errorf("job `%s` HTTP TLS error: %s", j.FullName(), err)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	defer transport.CloseIdleConnections()
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("job `%s` HTTP request error: %s", j.FullName(), err)
+	}
+	defer resp.Body.Close()
+
+	maxBody := args.MaxBody
+	if maxBody == 0 {
+		maxBody = 4096
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, int64(maxBody)+1))
+	truncated := len(b) > maxBody
+	if truncated {
+		b = b[:maxBody]
+	}
+
+	result := map[string]any{
+		"status_code":    resp.StatusCode,
+		"body":           string(b),
+		"body_truncated": truncated,
+	}
+	if err != nil {
+		return result, fmt.Errorf("job `%s` HTTP response error: %s", j.FullName(), err)
+	}
+
+	expected := resp.StatusCode >= 200 && resp.StatusCode < 300
+	if len(args.ExpectedStatus) > 0 {
+		expected = slices.Contains(args.ExpectedStatus, resp.StatusCode)
+	}
+	if !expected {
+		return result, fmt.Errorf("job `%s` HTTP response error: unexpected status `%d`", j.FullName(), resp.StatusCode)
+	}
+
+	return result, nil
+}
+
+// Register the built-in HTTP function to a registry as `FUNC_HTTP`.
+//
+// It sends requests from the node to any URL,
+// so it is only registered explicitly.
+func RegisterHTTPTo(r *FuncRegistry) {
+	registerTypedResultTo(r, FUNC_HTTP, runHTTP)
+	r.Describe(FUNC_HTTP, FuncInfo{
+		Description: "Send an HTTP request, the status and body of the response are recorded as the result.",
+	})
+}
+
+// Register the built-in HTTP function to the default registry.
+func RegisterHTTP() {
+	RegisterHTTPTo(defaultFuncRegistry)
+}
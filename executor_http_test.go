@@ -0,0 +1,121 @@
+package agscheduler
+
+import (
+	"context"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPArgsValidate(t *testing.T) {
+	assert.NoError(t, HTTPArgs{URL: "http://127.0.0.1"}.Validate())
+	assert.Error(t, HTTPArgs{URL: "127.0.0.1"}.Validate())
+	assert.Error(t, HTTPArgs{URL: "http://127.0.0.1", Method: "G T"}.Validate())
+	assert.Error(t, HTTPArgs{URL: "http://127.0.0.1", Body: "{{.Id"}.Validate())
+	assert.Error(t, HTTPArgs{URL: "http://127.0.0.1", ExpectedStatus: []int{99}}.Validate())
+	assert.Error(t, HTTPArgs{URL: "http://127.0.0.1", Timeout: "1"}.Validate())
+	assert.Error(t, HTTPArgs{URL: "http://127.0.0.1", MaxBody: -1}.Validate())
+	assert.Error(t, HTTPArgs{URL: "http://127.0.0.1", CACert: "ca"}.Validate())
+	assert.Error(t, HTTPArgs{URL: "http://127.0.0.1", ClientCert: "cert"}.Validate())
+}
+
+func TestRunHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, r.Method+" "+r.Header.Get("X-Token")+" "+string(b))
+	}))
+	defer srv.Close()
+
+	j := Job{Id: "1", Name: "job", Args: map[string]any{"k": "v"}}
+	result, err := runHTTP(context.TODO(), j, HTTPArgs{
+		Method:  "post",
+		URL:     srv.URL,
+		Headers: map[string]string{"X-Token": "token"},
+		Body:    `{"id":{{json .Id}},"args":{{json .Args}}}`,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 200, result["status_code"])
+	assert.Equal(t, `POST token {"id":"1","args":{"k":"v"}}`, result["body"])
+	assert.Equal(t, false, result["body_truncated"])
+}
+
+func TestRunHTTPStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, "not found")
+	}))
+	defer srv.Close()
+
+	result, err := runHTTP(context.TODO(), Job{Id: "1", Name: "job"}, HTTPArgs{URL: srv.URL})
+	assert.Error(t, err)
+	assert.Equal(t, 404, result["status_code"])
+	assert.Equal(t, "not found", result["body"])
+
+	_, err = runHTTP(context.TODO(), Job{}, HTTPArgs{URL: srv.URL, ExpectedStatus: []int{404}})
+	assert.NoError(t, err)
+}
+
+func TestRunHTTPMaxBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, strings.Repeat("a", 10))
+	}))
+	defer srv.Close()
+
+	result, err := runHTTP(context.TODO(), Job{}, HTTPArgs{URL: srv.URL, MaxBody: 4})
+	assert.NoError(t, err)
+	assert.Equal(t, "aaaa", result["body"])
+	assert.Equal(t, true, result["body_truncated"])
+}
+
+func TestRunHTTPTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	start := time.Now()
+	_, err := runHTTP(context.TODO(), Job{}, HTTPArgs{URL: srv.URL, Timeout: "100ms"})
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestRunHTTPTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "tls")
+	}))
+	defer srv.Close()
+
+	_, err := runHTTP(context.TODO(), Job{}, HTTPArgs{URL: srv.URL})
+	assert.Error(t, err)
+
+	result, err := runHTTP(context.TODO(), Job{}, HTTPArgs{URL: srv.URL, InsecureSkipVerify: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "tls", result["body"])
+
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	result, err = runHTTP(context.TODO(), Job{}, HTTPArgs{URL: srv.URL, CACert: string(caCert)})
+	assert.NoError(t, err)
+	assert.Equal(t, "tls", result["body"])
+}
+
+func TestRegisterHTTPTo(t *testing.T) {
+	r := NewFuncRegistry()
+	RegisterHTTPTo(r)
+
+	rf, ok := r.get(FUNC_HTTP)
+	assert.True(t, ok)
+	assert.NotEmpty(t, rf.info.Description)
+	assert.Len(t, rf.validate(map[string]any{}), 1)
+	assert.Len(t, rf.validate(map[string]any{"url": "ftp://127.0.0.1"}), 1)
+	assert.Empty(t, rf.validate(map[string]any{"url": "http://127.0.0.1"}))
+}
@@ -0,0 +1,122 @@
+package agscheduler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// The `FuncName` of the built-in shell function, registered by `RegisterShell`.
+const FUNC_SHELL = "builtin.shell"
+
+// `Args` of the built-in shell function.
+type ShellArgs struct {
+	Command string   `json:"command,omitempty" description:"Command line, run by the system shell"`
+	Argv    []string `json:"argv,omitempty" description:"Program and its arguments, run without a shell"`
+	Dir     string   `json:"dir,omitempty" description:"Working directory"`
+	// Added to the environment of the scheduler process.
+	Env   map[string]string `json:"env,omitempty" description:"Environment variables"`
+	Stdin string            `json:"stdin,omitempty" description:"Written to the standard input"`
+	// Default: `65536`
+	MaxOutput int `json:"max_output,omitempty" description:"Bytes of stdout and stderr kept in the result"`
+}
+
+func (a ShellArgs) Validate() error {
+	if (a.Command == "") == (len(a.Argv) == 0) {
+		return errors.New("exactly one of `command` and `argv` must be set")
+	}
+	if a.MaxOutput < 0 {
+		return &ArgsFieldError{Field: "max_output", Description: "must not be negative"}
+	}
+
+	return nil
+}
+
+// Keep the first `n` bytes written, the rest are dropped.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	n         int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if rest := b.n - b.buf.Len(); rest < len(p) {
+		b.truncated = true
+		if rest > 0 {
+			b.buf.Write(p[:rest])
+		}
+		return len(p), nil
+	}
+
+	return b.buf.Write(p)
+}
+
+// Run a command line or argv,
+// the whole process group is killed when the job times out.
+func runShell(ctx context.Context, j Job, args ShellArgs) (map[string]any, error) {
+	var cmd *exec.Cmd
+	if len(args.Argv) > 0 {
+		cmd = exec.CommandContext(ctx, args.Argv[0], args.Argv[1:]...)
+	} else {
+		cmd = shellCommand(ctx, args.Command)
+	}
+	setProcessGroup(cmd)
+	// Do not wait for the pipes forever after the process group is killed.
+	cmd.WaitDelay = time.Second
+
+	cmd.Dir = args.Dir
+	if len(args.Env) > 0 {
+		cmd.Env = os.Environ()
+		for k, v := range args.Env {
+			cmd.Env = append(cmd.Env, k+"="+v)
+		}
+	}
+	if args.Stdin != "" {
+		cmd.Stdin = strings.NewReader(args.Stdin)
+	}
+
+	maxOutput := args.MaxOutput
+	if maxOutput == 0 {
+		maxOutput = 65536
+	}
+	stdout := &limitedBuffer{n: maxOutput}
+	stderr := &limitedBuffer{n: maxOutput}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err := cmd.Run()
+
+	result := map[string]any{
+		"exit_code":        cmd.ProcessState.ExitCode(),
+		"stdout":           stdout.buf.String(),
+		"stderr":           stderr.buf.String(),
+		"stdout_truncated": stdout.truncated,
+		"stderr_truncated": stderr.truncated,
+	}
+	if err != nil {
+		return result, fmt.Errorf("job `%s` command error: %s", j.FullName(), err)
+	}
+
+	return result, nil
+}
+
+// Register the built-in shell function to a registry as `FUNC_SHELL`.
+//
+// It runs any command on the node with the permissions of the scheduler process,
+// so it is only registered explicitly.
+func RegisterShellTo(r *FuncRegistry) {
+	registerTypedResultTo(r, FUNC_SHELL, runShell)
+	r.Describe(FUNC_SHELL, FuncInfo{
+		Description: "Run a command line or argv, the exit code, stdout and stderr are recorded as the result.",
+	})
+}
+
+// Register the built-in shell function to the default registry.
+func RegisterShell() {
+	RegisterShellTo(defaultFuncRegistry)
+}
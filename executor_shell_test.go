@@ -0,0 +1,81 @@
+//go:build !windows
+
+package agscheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShellArgsValidate(t *testing.T) {
+	assert.NoError(t, ShellArgs{Command: "true"}.Validate())
+	assert.NoError(t, ShellArgs{Argv: []string{"true"}}.Validate())
+	assert.Error(t, ShellArgs{}.Validate())
+	assert.Error(t, ShellArgs{Command: "true", Argv: []string{"true"}}.Validate())
+	assert.Error(t, ShellArgs{Command: "true", MaxOutput: -1}.Validate())
+}
+
+func TestRunShell(t *testing.T) {
+	dir := t.TempDir()
+
+	result, err := runShell(context.TODO(), Job{}, ShellArgs{
+		Command: "cat; echo $AG_SHELL; pwd; echo err >&2",
+		Dir:     dir,
+		Env:     map[string]string{"AG_SHELL": "env"},
+		Stdin:   "stdin\n",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result["exit_code"])
+	assert.Equal(t, "stdin\nenv\n"+dir+"\n", result["stdout"])
+	assert.Equal(t, "err\n", result["stderr"])
+}
+
+func TestRunShellArgv(t *testing.T) {
+	result, err := runShell(context.TODO(), Job{}, ShellArgs{Argv: []string{"echo", "a b", "$HOME"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "a b $HOME\n", result["stdout"])
+}
+
+func TestRunShellExitCode(t *testing.T) {
+	result, err := runShell(context.TODO(), Job{Id: "1", Name: "job"}, ShellArgs{Command: "echo out; exit 3"})
+	assert.Error(t, err)
+	assert.Equal(t, 3, result["exit_code"])
+	assert.Equal(t, "out\n", result["stdout"])
+
+	result, err = runShell(context.TODO(), Job{}, ShellArgs{Argv: []string{"/nonexistent"}})
+	assert.Error(t, err)
+	assert.Equal(t, -1, result["exit_code"])
+}
+
+func TestRunShellMaxOutput(t *testing.T) {
+	result, err := runShell(context.TODO(), Job{}, ShellArgs{Command: "echo 0123456789", MaxOutput: 4})
+	assert.NoError(t, err)
+	assert.Equal(t, "0123", result["stdout"])
+	assert.Equal(t, true, result["stdout_truncated"])
+	assert.Equal(t, false, result["stderr_truncated"])
+}
+
+func TestRunShellTimeoutKillsProcessGroup(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	// The background child keeps stdout open, it must be killed with the shell.
+	_, err := runShell(ctx, Job{}, ShellArgs{Command: "sleep 10 & sleep 10"})
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestRegisterShellTo(t *testing.T) {
+	r := NewFuncRegistry()
+	RegisterShellTo(r)
+
+	rf, ok := r.get(FUNC_SHELL)
+	assert.True(t, ok)
+	assert.NotEmpty(t, rf.info.Description)
+	assert.Len(t, rf.validate(map[string]any{}), 1)
+	assert.Empty(t, rf.validate(map[string]any{"command": "true"}))
+}
@@ -0,0 +1,22 @@
+//go:build !windows
+
+package agscheduler
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+)
+
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	return exec.CommandContext(ctx, "/bin/sh", "-c", command)
+}
+
+// Run the command in its own process group,
+// so that its children are also killed when the context is done.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build windows
+
+package agscheduler
+
+import (
+	"context"
+	"os/exec"
+)
+
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	return exec.CommandContext(ctx, "cmd", "/C", command)
+}
+
+// The process is killed by `exec.CommandContext`.
+func setProcessGroup(cmd *exec.Cmd) {}
@@ -35,3 +35,47 @@ type Store interface {
 	// Clear all resources bound to this store.
 	Clear() error
 }
+
+// Implemented by the stores which keep calendars, checked by type assertion.
+// With other stores, the calendars are kept in the process of the scheduler.
+type CalendarStore interface {
+	// Add the calendar to this store, or replace the one with the same name.
+	SetCalendar(c Calendar) error
+
+	// Get the calendar from this store.
+	//  @return error `CalendarNotFoundError` if there are no calendar.
+	GetCalendar(name string) (Calendar, error)
+
+	// Get all calendars from this store.
+	GetAllCalendars() ([]Calendar, error)
+
+	// Delete the calendar from this store.
+	DeleteCalendar(name string) error
+}
+
+// Implemented by the stores which keep semaphores, checked by type assertion,
+// so that the concurrency keys are held across the nodes of a cluster.
+// With other stores, the semaphores are kept in the process of the scheduler.
+type SemaphoreStore interface {
+	// Take a slot of the named semaphore for `holder`, when fewer than `limit` slots are taken.
+	// Taking it again refreshes the slot, which is freed after `ttl` unless refreshed,
+	// so that the slots of a crashed node do not block the other nodes forever.
+	//  @return bool whether `holder` has a slot.
+	AcquireSemaphore(name string, holder string, limit int, ttl time.Duration) (bool, error)
+
+	// Free the slot of the named semaphore taken by `holder`.
+	ReleaseSemaphore(name string, holder string) error
+}
+
+// Implemented by the stores which keep token buckets, checked by type assertion,
+// so that the rate limits are shared across the nodes of a cluster.
+// With other stores, the token buckets are kept in the process of the scheduler.
+type TokenStore interface {
+	// Reserve a token of the named token bucket, refilled with `rate` tokens per second up to `burst`.
+	// The tokens are reserved in order, also when the bucket is empty.
+	//  @return time.Duration how long to wait until the reserved token is available.
+	ReserveToken(name string, rate float64, burst int) (time.Duration, error)
+
+	// Give back a token reserved from the named token bucket by a run which never started.
+	ReturnToken(name string, rate float64) error
+}
@@ -4,9 +4,9 @@ import (
 	"bytes"
 	"context"
 	"encoding/gob"
+	"errors"
 	"fmt"
-	"reflect"
-	"runtime"
+	"slices"
 	"strings"
 	"time"
 
@@ -22,6 +22,8 @@ const (
 	TYPE_DATETIME = "datetime"
 	TYPE_INTERVAL = "interval"
 	TYPE_CRON     = "cron"
+	TYPE_RRULE    = "rrule"
+	TYPE_COMBINED = "combined"
 )
 
 // constant indicating a job's status
@@ -37,19 +39,47 @@ type Job struct {
 	Id string `json:"id"`
 	// User defined.
 	Name string `json:"name"`
-	// Optional: `TYPE_DATETIME` | `TYPE_INTERVAL` | `TYPE_CRON`
+	// Optional: `TYPE_DATETIME` | `TYPE_INTERVAL` | `TYPE_CRON` | `TYPE_RRULE` | `TYPE_COMBINED`
 	Type string `json:"type"`
-	// It can be used when Type is `TYPE_DATETIME`.
+	// It can be used when Type is `TYPE_DATETIME`,
+	// as the first run time on the grid when Type is `TYPE_INTERVAL`,
+	// or as the default `DTSTART` when Type is `TYPE_RRULE`.
+	// Default when Type is `TYPE_INTERVAL`: the time the job is added plus `Interval`
 	StartAt string `json:"start_at"`
 	// This field is useless.
 	EndAt string `json:"end_at"`
 	// It can be used when Type is `TYPE_INTERVAL`.
 	Interval string `json:"interval"`
+	// It can be used when Type is `TYPE_INTERVAL` or `TYPE_CRON`.
+	// The run times are delayed by up to `Jitter`, which is hashed from `Id` and the run time,
+	// to spread the jobs sharing a schedule.
+	// Refer to `time.ParseDuration`, less than `Interval` when Type is `TYPE_INTERVAL`.
+	Jitter string `json:"jitter"`
 	// It can be used when Type is `TYPE_CRON`.
+	// 5 ~ 7 fields, followed by the year when 6 and also starting with seconds when 7, macros such as `@daily`,
+	// `@every <duration>`, and `H` items hashed from `Id`.
 	CronExpr string `json:"cron_expr"`
+	// It can be used when Type is `TYPE_RRULE`.
+	// RFC 5545 `RRULE`, `RDATE`, `EXDATE` and `DTSTART` lines separated by new lines,
+	// times without `Z` or `TZID` are in `Timezone`.
+	RRule string `json:"rrule"`
+	// It can be used when Type is `TYPE_COMBINED`.
+	// Optional: `OPERATOR_OR` | `OPERATOR_AND`
+	Operator string `json:"operator"`
+	// It can be used when Type is `TYPE_COMBINED`.
+	// The child triggers joined by `Operator`, which can be combined triggers themselves.
+	Triggers []Trigger `json:"triggers"`
 	// Refer to `time.LoadLocation`.
 	// Default: `UTC`
 	Timezone string `json:"timezone"`
+	// It can be used when Type is `TYPE_CRON`, how the times skipped when clocks go forward run.
+	// Optional: `DST_SKIP` | `DST_SHIFT`
+	// Default: `DST_SHIFT`
+	DSTGap string `json:"dst_gap"`
+	// It can be used when Type is `TYPE_CRON`, how the times repeated when clocks go back run.
+	// Optional: `DST_ONCE` | `DST_TWICE`
+	// Default: `DST_ONCE`
+	DSTOverlap string `json:"dst_overlap"`
 	// The job actually runs the function,
 	// and you need to register it through 'RegisterFuncs' before using it.
 	// Since it cannot be stored by serialization,
@@ -65,6 +95,46 @@ type Job struct {
 	Timeout string `json:"timeout"`
 	// Used in cluster mode, if empty, randomly pick a node to run `Func`.
 	Queues []string `json:"queues"`
+	// The jobs due at the same wakeup are dispatched in descending order of it,
+	// and so are the runs waiting in the backlog of the pool of a scheduler or node.
+	// Default: `0`
+	Priority int `json:"priority"`
+	// User defined labels, such as `team=billing`.
+	// Used to select jobs for bulk operations.
+	Tags []string `json:"tags"`
+	// Ids of the jobs this job depends on in workflows.
+	// A job with upstreams is not scheduled by time and `Type` is ignored,
+	// it runs when its upstreams finish in the same workflow run.
+	Upstreams []string `json:"upstreams"`
+	// Whether to run when the upstreams finish, otherwise the job is skipped.
+	// Optional: `TRIGGER_ALL_SUCCEEDED` | `TRIGGER_ANY_FAILED` | `TRIGGER_ALWAYS`
+	// Default: `TRIGGER_ALL_SUCCEEDED`
+	TriggerRule string `json:"trigger_rule"`
+	// Links the runs of the same workflow run.
+	// Automatic update, not manual setting.
+	WorkflowRunId string `json:"workflow_run_id"`
+	// Ids or names of the jobs to run immediately when this job succeeds.
+	OnSuccess []string `json:"on_success"`
+	// Ids or names of the jobs to run immediately when this job fails or times out.
+	OnFailure []string `json:"on_failure"`
+	// Set in chained runs, the id of the job whose run triggered this run.
+	// Automatic update, not manual setting.
+	TriggeredBy string `json:"triggered_by"`
+	// Set in chained runs, the result of the run which triggered this run.
+	// Automatic update, not manual setting.
+	TriggerResult map[string]any `json:"trigger_result"`
+	// Names of the calendars excluding the run times of this job.
+	Calendars []string `json:"calendars"`
+	// The runs are only dispatched in these windows in `Timezone`, such as `01:00` to `05:00`.
+	// If empty, the runs are dispatched at any time.
+	AllowedWindows []CalendarWindow `json:"allowed_windows"`
+	// How a run due outside `AllowedWindows` is handled.
+	// Optional: `WINDOW_DEFER` | `WINDOW_SKIP`
+	// Default: `WINDOW_DEFER`
+	WindowPolicy string `json:"window_policy"`
+	// A run only starts once it holds all these keys, across the nodes of a cluster.
+	// The number of runs holding a key at the same time is limited by `Scheduler.Semaphores`.
+	ConcurrencyKeys []string `json:"concurrency_keys"`
 
 	// Automatic update, not manual setting.
 	LastRunTime time.Time `json:"last_run_time"`
@@ -83,13 +153,25 @@ func (js JobSlice) Len() int           { return len(js) }
 func (js JobSlice) Less(i, j int) bool { return js[i].NextRunTime.Before(js[j].NextRunTime) }
 func (js JobSlice) Swap(i, j int)      { js[i], js[j] = js[j], js[i] }
 
+// Orders the jobs due together, higher `Priority` first, then earlier `NextRunTime`.
+type JobPrioritySlice []Job
+
+func (js JobPrioritySlice) Len() int { return len(js) }
+func (js JobPrioritySlice) Less(i, j int) bool {
+	if js[i].Priority != js[j].Priority {
+		return js[i].Priority > js[j].Priority
+	}
+	return js[i].NextRunTime.Before(js[j].NextRunTime)
+}
+func (js JobPrioritySlice) Swap(i, j int) { js[i], js[j] = js[j], js[i] }
+
 func (j *Job) setId() {
 	j.Id = strings.Replace(uuid.New().String(), "-", "", -1)[:16]
 }
 
 // Initialization functions for each job,
 // called when the scheduler run `AddJob`.
-func (j *Job) init() error {
+func (j *Job) init(getFunc func(name string) (registeredFunc, bool)) error {
 	j.setId()
 
 	j.Status = STATUS_RUNNING
@@ -106,45 +188,166 @@ func (j *Job) init() error {
 		j.Timeout = "1h"
 	}
 
+	j.anchorInterval()
+
+	if len(j.Upstreams) > 0 && j.TriggerRule == "" {
+		j.TriggerRule = TRIGGER_ALL_SUCCEEDED
+	}
+
 	nextRunTime, err := CalcNextRunTime(*j)
 	if err != nil {
 		return err
 	}
 	j.NextRunTime = nextRunTime
 
-	if err := j.check(); err != nil {
+	if err := j.check(getFunc); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// Interval jobs without `StartAt` are anchored to the first run,
+// so that updates and resumes keep the grid.
+func (j *Job) anchorInterval() {
+	if strings.ToLower(j.Type) != TYPE_INTERVAL || j.StartAt != "" {
+		return
+	}
+
+	i, err := time.ParseDuration(j.Interval)
+	if err != nil {
+		return
+	}
+	timezone, err := loadLocation(j.Timezone)
+	if err != nil {
+		return
+	}
+	j.StartAt = time.Now().In(timezone).Add(i).Format(time.DateTime)
+}
+
+// The fields only set for a single run are not stored.
+func (j *Job) clearRunFields() {
+	j.WorkflowRunId = ""
+	j.TriggeredBy = ""
+	j.TriggerResult = nil
+}
+
 // Called when the job run `init` or scheduler run `UpdateJob`.
-func (j *Job) check() error {
-	if _, ok := funcMap[j.FuncName]; !ok {
+func (j *Job) check(getFunc func(name string) (registeredFunc, bool)) error {
+	rf, ok := getFunc(j.FuncName)
+	if !ok {
 		return FuncUnregisteredError(j.FuncName)
 	}
 
+	// Aliases are replaced with the name of the function.
+	j.FuncName = rf.info.Name
+
+	if rf.validate != nil {
+		if fields := rf.validate(j.Args); len(fields) > 0 {
+			return &JobArgsError{FullName: j.FullName(), FuncName: j.FuncName, Fields: fields}
+		}
+	}
+
 	_, err := time.ParseDuration(j.Timeout)
 	if err != nil {
 		return &JobTimeoutError{FullName: j.FullName(), Timeout: j.Timeout, Err: err}
 	}
 
+	if _, err := loadLocation(j.Timezone); err != nil {
+		return &JobScheduleError{FullName: j.FullName(), Field: "timezone", Value: j.Timezone, Err: err}
+	}
+	if field, err := checkAllowedWindows(j.AllowedWindows, j.WindowPolicy); err != nil {
+		value := fmt.Sprintf("%v", j.AllowedWindows)
+		if field == "window_policy" {
+			value = j.WindowPolicy
+		}
+		return &JobScheduleError{FullName: j.FullName(), Field: field, Value: value, Err: err}
+	}
+	if field, err := checkDSTPolicies(j.DSTGap, j.DSTOverlap); err != nil {
+		value := j.DSTGap
+		if field == "dst_overlap" {
+			value = j.DSTOverlap
+		}
+		return &JobScheduleError{FullName: j.FullName(), Field: field, Value: value, Err: err}
+	}
+
+	// Paused jobs are not scheduled, but their expressions are still checked.
+	if strings.ToLower(j.Type) == TYPE_CRON {
+		if _, err := parseCronExpr(j.CronExpr, j.Id); err != nil {
+			return &JobScheduleError{FullName: j.FullName(), Field: "cron_expr", Value: j.CronExpr, Err: err}
+		}
+	}
+	if strings.ToLower(j.Type) == TYPE_RRULE {
+		timezone, err := loadLocation(j.Timezone)
+		if err != nil {
+			return &JobScheduleError{FullName: j.FullName(), Field: "timezone", Value: j.Timezone, Err: err}
+		}
+		if _, err := parseRRuleSet(j.RRule, j.StartAt, timezone); err != nil {
+			return &JobScheduleError{FullName: j.FullName(), Field: "rrule", Value: j.RRule, Err: err}
+		}
+	}
+	if strings.ToLower(j.Type) == TYPE_COMBINED {
+		timezone, err := loadLocation(j.Timezone)
+		if err != nil {
+			return &JobScheduleError{FullName: j.FullName(), Field: "timezone", Value: j.Timezone, Err: err}
+		}
+		if _, err := j.combinedSchedule(timezone); err != nil {
+			return err
+		}
+	}
+
+	if _, err := j.jitter(); err != nil {
+		return err
+	}
+
+	switch j.TriggerRule {
+	case "", TRIGGER_ALL_SUCCEEDED, TRIGGER_ANY_FAILED, TRIGGER_ALWAYS:
+	default:
+		return fmt.Errorf("job `%s` TriggerRule `%s` unknown", j.FullName(), j.TriggerRule)
+	}
+
 	return nil
 }
 
+// Parse `Jitter`, which is 0 when it is empty or the job type does not use it.
+func (j *Job) jitter() (time.Duration, error) {
+	t := strings.ToLower(j.Type)
+	if j.Jitter == "" || (t != TYPE_INTERVAL && t != TYPE_CRON) {
+		return 0, nil
+	}
+
+	jitter, err := time.ParseDuration(j.Jitter)
+	if err != nil {
+		return 0, &JobScheduleError{FullName: j.FullName(), Field: "jitter", Value: j.Jitter, Err: err}
+	}
+	if jitter < 0 {
+		return 0, &JobScheduleError{FullName: j.FullName(), Field: "jitter", Value: j.Jitter, Err: errors.New("must not be negative")}
+	}
+	if i, err := time.ParseDuration(j.Interval); t == TYPE_INTERVAL && err == nil && jitter >= i {
+		return 0, &JobScheduleError{FullName: j.FullName(), Field: "jitter", Value: j.Jitter, Err: errors.New("must be less than `interval`")}
+	}
+
+	return jitter, nil
+}
+
 func (j *Job) FullName() string {
 	return j.Id + ":" + j.Name
 }
 
 func (j *Job) LastRunTimeWithTimezone() time.Time {
-	timezone, _ := time.LoadLocation(j.Timezone)
+	timezone, err := loadLocation(j.Timezone)
+	if err != nil {
+		timezone = time.UTC
+	}
 
 	return j.LastRunTime.In(timezone)
 }
 
 func (j *Job) NextRunTimeWithTimezone() time.Time {
-	timezone, _ := time.LoadLocation(j.Timezone)
+	timezone, err := loadLocation(j.Timezone)
+	if err != nil {
+		timezone = time.UTC
+	}
 
 	return j.NextRunTime.In(timezone)
 }
@@ -152,12 +355,16 @@ func (j *Job) NextRunTimeWithTimezone() time.Time {
 func (j Job) String() string {
 	return fmt.Sprintf(
 		"Job{'Id':'%s', 'Name':'%s', 'Type':'%s', 'StartAt':'%s', 'EndAt':'%s', "+
-			"'Interval':'%s', 'CronExpr':'%s', 'Timezone':'%s', "+
-			"'FuncName':'%s', 'Args':'%s', 'Timeout':'%s', 'Queues':'%s', "+
+			"'Interval':'%s', 'Jitter':'%s', 'CronExpr':'%s', 'RRule':'%s', 'Operator':'%s', 'Triggers':'%s', 'Timezone':'%s', 'DSTGap':'%s', 'DSTOverlap':'%s', "+
+			"'FuncName':'%s', 'Args':'%s', 'Timeout':'%s', 'Queues':'%s', 'Priority':'%d', 'Tags':'%s', "+
+			"'Upstreams':'%s', 'TriggerRule':'%s', 'WorkflowRunId':'%s', "+
+			"'OnSuccess':'%s', 'OnFailure':'%s', 'TriggeredBy':'%s', 'TriggerResult':'%s', 'Calendars':'%s', 'AllowedWindows':'%v', 'WindowPolicy':'%s', 'ConcurrencyKeys':'%s', "+
 			"'LastRunTime':'%s', 'NextRunTime':'%s', 'Status':'%s'}",
 		j.Id, j.Name, j.Type, j.StartAt, j.EndAt,
-		j.Interval, j.CronExpr, j.Timezone,
-		j.FuncName, j.Args, j.Timeout, j.Queues,
+		j.Interval, j.Jitter, j.CronExpr, j.RRule, j.Operator, j.Triggers, j.Timezone, j.DSTGap, j.DSTOverlap,
+		j.FuncName, j.Args, j.Timeout, j.Queues, j.Priority, j.Tags,
+		j.Upstreams, j.TriggerRule, j.WorkflowRunId,
+		j.OnSuccess, j.OnFailure, j.TriggeredBy, j.TriggerResult, j.Calendars, j.AllowedWindows, j.WindowPolicy, j.ConcurrencyKeys,
 		j.LastRunTimeWithTimezone(), j.NextRunTimeWithTimezone(), j.Status,
 	)
 }
@@ -188,20 +395,44 @@ func StateLoad(state []byte) (Job, error) {
 // Used to gRPC Protobuf
 func JobToPbJobPtr(j Job) *pb.Job {
 	args, _ := structpb.NewStruct(j.Args)
+	var triggerResult *structpb.Struct
+	if j.TriggerResult != nil {
+		triggerResult, _ = structpb.NewStruct(j.TriggerResult)
+	}
 
 	return &pb.Job{
-		Id:       j.Id,
-		Name:     j.Name,
-		Type:     j.Type,
-		StartAt:  j.StartAt,
-		EndAt:    j.EndAt,
-		Interval: j.Interval,
-		CronExpr: j.CronExpr,
-		Timezone: j.Timezone,
-		FuncName: j.FuncName,
-		Args:     args,
-		Timeout:  j.Timeout,
-		Queues:   j.Queues,
+		Id:         j.Id,
+		Name:       j.Name,
+		Type:       j.Type,
+		StartAt:    j.StartAt,
+		EndAt:      j.EndAt,
+		Interval:   j.Interval,
+		Jitter:     j.Jitter,
+		CronExpr:   j.CronExpr,
+		Rrule:      j.RRule,
+		Operator:   j.Operator,
+		Triggers:   triggersToPbTriggersPtr(j.Triggers),
+		Timezone:   j.Timezone,
+		DstGap:     j.DSTGap,
+		DstOverlap: j.DSTOverlap,
+		FuncName:   j.FuncName,
+		Args:       args,
+		Timeout:    j.Timeout,
+		Queues:     j.Queues,
+		Priority:   int32(j.Priority),
+		Tags:       j.Tags,
+
+		Upstreams:       j.Upstreams,
+		TriggerRule:     j.TriggerRule,
+		WorkflowRunId:   j.WorkflowRunId,
+		OnSuccess:       j.OnSuccess,
+		OnFailure:       j.OnFailure,
+		TriggeredBy:     j.TriggeredBy,
+		TriggerResult:   triggerResult,
+		Calendars:       j.Calendars,
+		AllowedWindows:  calendarWindowsToPbCalendarWindowsPtr(j.AllowedWindows),
+		WindowPolicy:    j.WindowPolicy,
+		ConcurrencyKeys: j.ConcurrencyKeys,
 
 		LastRunTime: timestamppb.New(j.LastRunTime),
 		NextRunTime: timestamppb.New(j.NextRunTime),
@@ -211,19 +442,44 @@ func JobToPbJobPtr(j Job) *pb.Job {
 
 // Used to gRPC Protobuf
 func PbJobPtrToJob(pbJob *pb.Job) Job {
+	var triggerResult map[string]any
+	if pbJob.GetTriggerResult() != nil {
+		triggerResult = pbJob.GetTriggerResult().AsMap()
+	}
+
 	return Job{
-		Id:       pbJob.GetId(),
-		Name:     pbJob.GetName(),
-		Type:     pbJob.GetType(),
-		StartAt:  pbJob.GetStartAt(),
-		EndAt:    pbJob.GetEndAt(),
-		Interval: pbJob.GetInterval(),
-		CronExpr: pbJob.GetCronExpr(),
-		Timezone: pbJob.GetTimezone(),
-		FuncName: pbJob.GetFuncName(),
-		Args:     pbJob.GetArgs().AsMap(),
-		Timeout:  pbJob.GetTimeout(),
-		Queues:   pbJob.GetQueues(),
+		Id:         pbJob.GetId(),
+		Name:       pbJob.GetName(),
+		Type:       pbJob.GetType(),
+		StartAt:    pbJob.GetStartAt(),
+		EndAt:      pbJob.GetEndAt(),
+		Interval:   pbJob.GetInterval(),
+		Jitter:     pbJob.GetJitter(),
+		CronExpr:   pbJob.GetCronExpr(),
+		RRule:      pbJob.GetRrule(),
+		Operator:   pbJob.GetOperator(),
+		Triggers:   pbTriggersPtrToTriggers(pbJob.GetTriggers()),
+		Timezone:   pbJob.GetTimezone(),
+		DSTGap:     pbJob.GetDstGap(),
+		DSTOverlap: pbJob.GetDstOverlap(),
+		FuncName:   pbJob.GetFuncName(),
+		Args:       pbJob.GetArgs().AsMap(),
+		Timeout:    pbJob.GetTimeout(),
+		Queues:     pbJob.GetQueues(),
+		Priority:   int(pbJob.GetPriority()),
+		Tags:       pbJob.GetTags(),
+
+		Upstreams:       pbJob.GetUpstreams(),
+		TriggerRule:     pbJob.GetTriggerRule(),
+		WorkflowRunId:   pbJob.GetWorkflowRunId(),
+		OnSuccess:       pbJob.GetOnSuccess(),
+		OnFailure:       pbJob.GetOnFailure(),
+		TriggeredBy:     pbJob.GetTriggeredBy(),
+		TriggerResult:   triggerResult,
+		Calendars:       pbJob.GetCalendars(),
+		AllowedWindows:  pbCalendarWindowsPtrToCalendarWindows(pbJob.GetAllowedWindows()),
+		WindowPolicy:    pbJob.GetWindowPolicy(),
+		ConcurrencyKeys: pbJob.GetConcurrencyKeys(),
 
 		LastRunTime: pbJob.GetLastRunTime().AsTime(),
 		NextRunTime: pbJob.GetNextRunTime().AsTime(),
@@ -253,18 +509,103 @@ func PbJobsPtrToJobs(pbJs *pb.Jobs) []Job {
 	return js
 }
 
-// Record the actual path of function and the corresponding function.
-// Since golang can't serialize functions,
-// need to register them with `RegisterFuncs` before using it.
-var funcMap = make(map[string]func(context.Context, Job))
+// Select jobs for bulk operations, every non-empty field must match.
+// An empty selector is rejected, so that a forgotten body does not act on all jobs,
+// `All` must be set to select all jobs.
+type JobSelector struct {
+	Ids      []string `json:"ids"`
+	Names    []string `json:"names"`
+	Queue    string   `json:"queue"`
+	FuncName string   `json:"func_name"`
+	Status   string   `json:"status"`
+	// The job must have all of these tags.
+	Tags []string `json:"tags"`
+	// Select all jobs, the other fields are still matched.
+	All bool `json:"all"`
+}
+
+func (sel JobSelector) isEmpty() bool {
+	return len(sel.Ids) == 0 && len(sel.Names) == 0 && sel.Queue == "" &&
+		sel.FuncName == "" && sel.Status == "" && len(sel.Tags) == 0
+}
+
+func (sel JobSelector) Match(j Job) bool {
+	if len(sel.Ids) != 0 && !slices.Contains(sel.Ids, j.Id) {
+		return false
+	}
+	if len(sel.Names) != 0 && !slices.Contains(sel.Names, j.Name) {
+		return false
+	}
+	if sel.Queue != "" && !slices.Contains(j.Queues, sel.Queue) {
+		return false
+	}
+	if sel.FuncName != "" && sel.FuncName != j.FuncName {
+		return false
+	}
+	if sel.Status != "" && sel.Status != j.Status {
+		return false
+	}
+	for _, tag := range sel.Tags {
+		if !slices.Contains(j.Tags, tag) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// The result of a bulk operation on a single job,
+// `Error` is empty if the operation succeeded.
+type BulkResult struct {
+	Id    string `json:"id"`
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
+// Used to gRPC Protobuf
+func JobSelectorToPbJobSelectorPtr(sel JobSelector) *pb.JobSelector {
+	return &pb.JobSelector{
+		Ids:      sel.Ids,
+		Names:    sel.Names,
+		Queue:    sel.Queue,
+		FuncName: sel.FuncName,
+		Status:   sel.Status,
+		Tags:     sel.Tags,
+		All:      sel.All,
+	}
+}
+
+// Used to gRPC Protobuf
+func PbJobSelectorPtrToJobSelector(pbSel *pb.JobSelector) JobSelector {
+	return JobSelector{
+		Ids:      pbSel.GetIds(),
+		Names:    pbSel.GetNames(),
+		Queue:    pbSel.GetQueue(),
+		FuncName: pbSel.GetFuncName(),
+		Status:   pbSel.GetStatus(),
+		Tags:     pbSel.GetTags(),
+		All:      pbSel.GetAll(),
+	}
+}
+
+// Used to gRPC Protobuf
+func BulkResultsToPbBulkResultsPtr(rs []BulkResult) *pb.BulkResults {
+	pbRs := pb.BulkResults{}
 
-func getFuncName(f func(context.Context, Job)) string {
-	return runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+	for _, r := range rs {
+		pbRs.Results = append(pbRs.Results, &pb.BulkResult{Id: r.Id, Name: r.Name, Error: r.Error})
+	}
+
+	return &pbRs
 }
 
-func RegisterFuncs(fs ...func(context.Context, Job)) {
-	for _, f := range fs {
-		fName := getFuncName(f)
-		funcMap[fName] = f
+// Used to gRPC Protobuf
+func PbBulkResultsPtrToBulkResults(pbRs *pb.BulkResults) []BulkResult {
+	rs := make([]BulkResult, 0)
+
+	for _, pbR := range pbRs.Results {
+		rs = append(rs, BulkResult{Id: pbR.GetId(), Name: pbR.GetName(), Error: pbR.GetError()})
 	}
+
+	return rs
 }
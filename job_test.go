@@ -3,7 +3,9 @@ package agscheduler
 import (
 	"context"
 	"reflect"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -83,6 +85,31 @@ func TestPbJobPtrToJob(t *testing.T) {
 	assert.NotEmpty(t, j)
 }
 
+func TestPbJobPtrToJobPriority(t *testing.T) {
+	j := getJob()
+	j.Priority = 5
+	j = PbJobPtrToJob(JobToPbJobPtr(j))
+
+	assert.Equal(t, 5, j.Priority)
+}
+
+func TestJobPrioritySlice(t *testing.T) {
+	now := time.Now()
+	js := []Job{
+		{Id: "1", NextRunTime: now.Add(-2 * time.Second)},
+		{Id: "2", NextRunTime: now.Add(-time.Second), Priority: 10},
+		{Id: "3", NextRunTime: now.Add(-3 * time.Second), Priority: -1},
+		{Id: "4", NextRunTime: now.Add(-3 * time.Second)},
+	}
+	sort.Stable(JobPrioritySlice(js))
+
+	ids := make([]string, 0, len(js))
+	for _, j := range js {
+		ids = append(ids, j.Id)
+	}
+	assert.Equal(t, []string{"2", "4", "1", "3"}, ids)
+}
+
 func TestJobsToPbJobsPtr(t *testing.T) {
 	js := make([]Job, 0)
 	js = append(js, getJob())
@@ -104,10 +131,46 @@ func TestPbJobsPtrToJobs(t *testing.T) {
 	assert.Len(t, js, 2)
 }
 
+func TestJobSelectorMatch(t *testing.T) {
+	j := getJob()
+	j.Id = "1"
+	j.Queues = []string{"default"}
+	j.Tags = []string{"team=billing", "env=prod"}
+	j.Status = STATUS_RUNNING
+
+	assert.True(t, JobSelector{}.Match(j))
+	assert.True(t, JobSelector{Ids: []string{"1", "2"}, Names: []string{"Job"}}.Match(j))
+	assert.True(t, JobSelector{Queue: "default", Status: STATUS_RUNNING}.Match(j))
+	assert.True(t, JobSelector{Tags: []string{"team=billing"}}.Match(j))
+
+	assert.False(t, JobSelector{Ids: []string{"2"}}.Match(j))
+	assert.False(t, JobSelector{Queue: "other"}.Match(j))
+	assert.False(t, JobSelector{FuncName: "other"}.Match(j))
+	assert.False(t, JobSelector{Status: STATUS_PAUSED}.Match(j))
+	assert.False(t, JobSelector{Tags: []string{"team=billing", "env=dev"}}.Match(j))
+}
+
+func TestJobSelectorToPbJobSelectorPtr(t *testing.T) {
+	sel := JobSelector{Ids: []string{"1"}, Queue: "default", Tags: []string{"team=billing"}}
+	pbSel := JobSelectorToPbJobSelectorPtr(sel)
+
+	assert.IsType(t, &pb.JobSelector{}, pbSel)
+	assert.Equal(t, sel, PbJobSelectorPtrToJobSelector(pbSel))
+}
+
+func TestBulkResultsToPbBulkResultsPtr(t *testing.T) {
+	rs := []BulkResult{{Id: "1", Name: "Job"}, {Id: "2", Name: "Job", Error: "error"}}
+	pbRs := BulkResultsToPbBulkResultsPtr(rs)
+
+	assert.IsType(t, &pb.BulkResults{}, pbRs)
+	assert.Len(t, pbRs.Results, 2)
+	assert.Equal(t, rs, PbBulkResultsPtrToBulkResults(pbRs))
+}
+
 func TestRegisterFuncs(t *testing.T) {
-	assert.Empty(t, funcMap)
+	assert.Empty(t, defaultFuncRegistry.Names())
 
 	RegisterFuncs(func(ctx context.Context, j Job) {})
 
-	assert.Len(t, funcMap, 1)
+	assert.Len(t, defaultFuncRegistry.Names(), 1)
 }
@@ -0,0 +1,245 @@
+package agscheduler
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// constant indicating how a run is handled when the backlog of the pool is full
+const (
+	// The run waits until the backlog has room, in its own goroutine so the caller is not held up.
+	OVERFLOW_BLOCK = "block"
+	// The run is dropped.
+	OVERFLOW_DROP = "drop"
+	// The run is submitted again after `POOL_DEFER_INTERVAL`.
+	OVERFLOW_DEFER = "defer"
+)
+
+// How long a run overflowing the backlog is deferred, with `OVERFLOW_DEFER`.
+const POOL_DEFER_INTERVAL = time.Second
+
+// The utilization of the pool executing the runs of a scheduler.
+type PoolStats struct {
+	// 0 is unlimited.
+	Size    int `json:"size"`
+	Running int `json:"running"`
+	Backlog int `json:"backlog"`
+	// `Running` divided by `Size`, 0 when the pool is unlimited.
+	Utilization float64 `json:"utilization"`
+}
+
+// Whether a node with this pool can start a run without waiting.
+func (ps PoolStats) hasCapacity() bool {
+	return ps.Size <= 0 || ps.Running+ps.Backlog < ps.Size
+}
+
+// Put the stats in the entry of a node in the node map.
+func (ps PoolStats) setTo(n map[string]any) {
+	n["pool_size"] = ps.Size
+	n["pool_running"] = ps.Running
+	n["pool_backlog"] = ps.Backlog
+	n["pool_utilization"] = ps.Utilization
+}
+
+// Read the stats from the entry of a node in the node map.
+func poolStatsFrom(n map[string]any) PoolStats {
+	ps := PoolStats{}
+	ps.Size, _ = n["pool_size"].(int)
+	ps.Running, _ = n["pool_running"].(int)
+	ps.Backlog, _ = n["pool_backlog"].(int)
+	ps.Utilization, _ = n["pool_utilization"].(float64)
+
+	return ps
+}
+
+// A run waiting for a free worker.
+type poolRun struct {
+	j      Job
+	queues []string
+	start  func()
+	cancel func(status string)
+}
+
+// Counts the runs executed by a scheduler, the limits are the fields of the scheduler.
+type workerPool struct {
+	mu sync.Mutex
+	// Signaled when a run finishes or the pool is closed, for the runs blocked by `OVERFLOW_BLOCK`.
+	cond *sync.Cond
+
+	running      int
+	queueRunning map[string]int
+	funcRunning  map[string]int
+	backlog      []*poolRun
+	closed       bool
+}
+
+// Called with `s.pool.mu` held.
+func (s *Scheduler) poolInit() {
+	if s.pool.cond == nil {
+		s.pool.cond = sync.NewCond(&s.pool.mu)
+		s.pool.queueRunning = make(map[string]int)
+		s.pool.funcRunning = make(map[string]int)
+	}
+}
+
+// The queues a run is counted against.
+// In cluster mode it is the queue of this node, otherwise the queues of the job.
+func (s *Scheduler) runQueues(j Job) []string {
+	if s.clusterNode != nil {
+		return []string{s.clusterNode.Queue}
+	}
+
+	return j.Queues
+}
+
+// Called with `s.pool.mu` held.
+func (s *Scheduler) poolCanStart(r *poolRun) bool {
+	if s.PoolSize > 0 && s.pool.running >= s.PoolSize {
+		return false
+	}
+	for _, q := range r.queues {
+		if limit, ok := s.QueueLimits[q]; ok && s.pool.queueRunning[q] >= limit {
+			return false
+		}
+	}
+	if limit, ok := s.FuncLimits[r.j.FuncName]; ok && s.pool.funcRunning[r.j.FuncName] >= limit {
+		return false
+	}
+
+	return true
+}
+
+// Called with `s.pool.mu` held.
+func (s *Scheduler) poolAcquire(r *poolRun) {
+	s.pool.running++
+	for _, q := range r.queues {
+		s.pool.queueRunning[q]++
+	}
+	s.pool.funcRunning[r.j.FuncName]++
+}
+
+// Start the run when the limits allow it, otherwise it waits in the backlog.
+// `cancel` is called instead of `start` when the run never starts,
+// with `WORKFLOW_SKIPPED` when it is dropped or `RECORD_CANCELED` when the scheduler is shut down.
+func (s *Scheduler) submitRun(j Job, start func(), cancel func(status string)) {
+	s.poolSubmit(&poolRun{j: j, queues: s.runQueues(j), start: start, cancel: cancel}, false)
+}
+
+// `waiting` is set in the goroutine of a run waiting for room in the backlog, with `OVERFLOW_BLOCK`.
+func (s *Scheduler) poolSubmit(r *poolRun, waiting bool) {
+	j, start, cancel := r.j, r.start, r.cancel
+
+	s.pool.mu.Lock()
+	s.poolInit()
+	for {
+		if s.pool.closed {
+			s.pool.mu.Unlock()
+			slog.Warn(fmt.Sprintf("Scheduler is shut down, job `%s` run rejected\n", j.FullName()))
+			cancel(RECORD_CANCELED)
+			return
+		}
+		if s.poolCanStart(r) {
+			s.poolAcquire(r)
+			s.pool.mu.Unlock()
+			start()
+			return
+		}
+		if s.BacklogSize <= 0 || len(s.pool.backlog) < s.BacklogSize {
+			// Ordered by priority, then by submission.
+			i := slices.IndexFunc(s.pool.backlog, func(b *poolRun) bool { return b.j.Priority < j.Priority })
+			if i < 0 {
+				i = len(s.pool.backlog)
+			}
+			s.pool.backlog = slices.Insert(s.pool.backlog, i, r)
+			s.pool.mu.Unlock()
+			slog.Info(fmt.Sprintf("Job `%s` is waiting in the backlog of the pool.\n", j.FullName()))
+			return
+		}
+
+		switch strings.ToLower(s.OverflowPolicy) {
+		case OVERFLOW_DROP:
+			s.pool.mu.Unlock()
+			slog.Warn(fmt.Sprintf("Job `%s` run dropped, the backlog of the pool is full\n", j.FullName()))
+			s.records.event(j, RECORD_SKIPPED, map[string]any{"reason": "backlog full"}, s.MaxRecords)
+			cancel(WORKFLOW_SKIPPED)
+			return
+		case OVERFLOW_DEFER:
+			s.pool.mu.Unlock()
+			until := time.Now().Add(POOL_DEFER_INTERVAL)
+			slog.Info(fmt.Sprintf("Job `%s` run deferred to `%s`, the backlog of the pool is full.\n", j.FullName(), until.Format(time.RFC3339Nano)))
+			s.records.event(j, RECORD_DEFERRED, map[string]any{"deferred_to": until.Format(time.RFC3339Nano)}, s.MaxRecords)
+			time.AfterFunc(POOL_DEFER_INTERVAL, func() { s.poolSubmit(r, false) })
+			return
+		default:
+			if !waiting {
+				s.pool.mu.Unlock()
+				slog.Info(fmt.Sprintf("Job `%s` is waiting for room in the backlog of the pool.\n", j.FullName()))
+				// The caller may be the scheduling loop.
+				go s.poolSubmit(r, true)
+				return
+			}
+			s.pool.cond.Wait()
+		}
+	}
+}
+
+// Called when a run finishes, the runs in the backlog allowed by the limits are started in order of priority.
+func (s *Scheduler) releaseRun(j Job) {
+	s.pool.mu.Lock()
+	s.poolInit()
+	s.pool.running--
+	for _, q := range s.runQueues(j) {
+		s.pool.queueRunning[q]--
+	}
+	s.pool.funcRunning[j.FuncName]--
+
+	starts := make([]func(), 0)
+	s.pool.backlog = slices.DeleteFunc(s.pool.backlog, func(r *poolRun) bool {
+		if !s.poolCanStart(r) {
+			return false
+		}
+		s.poolAcquire(r)
+		starts = append(starts, r.start)
+		return true
+	})
+	s.pool.cond.Broadcast()
+	s.pool.mu.Unlock()
+
+	for _, start := range starts {
+		start()
+	}
+}
+
+// Called by `Shutdown`, the runs in the backlog are canceled.
+func (s *Scheduler) closePool() {
+	s.pool.mu.Lock()
+	s.poolInit()
+	s.pool.closed = true
+	backlog := s.pool.backlog
+	s.pool.backlog = nil
+	s.pool.cond.Broadcast()
+	s.pool.mu.Unlock()
+
+	for _, r := range backlog {
+		slog.Warn(fmt.Sprintf("Job `%s` run canceled by shutdown, it was in the backlog\n", r.j.FullName()))
+		r.cancel(RECORD_CANCELED)
+	}
+}
+
+// Returns the utilization of the pool executing the runs of this scheduler.
+func (s *Scheduler) PoolStats() PoolStats {
+	defer s.pool.mu.Unlock()
+
+	s.pool.mu.Lock()
+
+	ps := PoolStats{Size: s.PoolSize, Running: s.pool.running, Backlog: len(s.pool.backlog)}
+	if s.PoolSize > 0 {
+		ps.Utilization = float64(s.pool.running) / float64(s.PoolSize)
+	}
+
+	return ps
+}
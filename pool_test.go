@@ -0,0 +1,130 @@
+package agscheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Submit a run of a job, the returned channel receives "start" or the status it is canceled with.
+func submitPoolRun(s *Scheduler, j Job) chan string {
+	ch := make(chan string, 1)
+	s.submitRun(j, func() { ch <- "start" }, func(status string) { ch <- status })
+
+	return ch
+}
+
+func assertPoolRun(t *testing.T, ch chan string, expected string) {
+	select {
+	case got := <-ch:
+		assert.Equal(t, expected, got)
+	case <-time.After(2 * time.Second):
+		assert.Fail(t, "run not handled", expected)
+	}
+}
+
+func TestSchedulerPoolLimits(t *testing.T) {
+	s := &Scheduler{PoolSize: 2, FuncLimits: map[string]int{"f": 1}, QueueLimits: map[string]int{"q": 1}}
+	f1 := Job{Id: "1", FuncName: "f"}
+	f2 := Job{Id: "2", FuncName: "f"}
+	q1 := Job{Id: "3", FuncName: "g", Queues: []string{"q"}}
+	q2 := Job{Id: "4", FuncName: "g", Queues: []string{"q"}}
+
+	assertPoolRun(t, submitPoolRun(s, f1), "start")
+	ch2 := submitPoolRun(s, f2)
+	assertPoolRun(t, submitPoolRun(s, q1), "start")
+	ch4 := submitPoolRun(s, q2)
+	assert.Equal(t, PoolStats{Size: 2, Running: 2, Backlog: 2, Utilization: 1}, s.PoolStats())
+
+	s.releaseRun(f1)
+	assertPoolRun(t, ch2, "start")
+	assert.Equal(t, 1, s.PoolStats().Backlog)
+
+	s.releaseRun(q1)
+	assertPoolRun(t, ch4, "start")
+	assert.Equal(t, PoolStats{Size: 2, Running: 2, Backlog: 0, Utilization: 1}, s.PoolStats())
+}
+
+func TestSchedulerPoolPriority(t *testing.T) {
+	s := &Scheduler{PoolSize: 1}
+	j1 := Job{Id: "1", FuncName: "f"}
+	low := Job{Id: "2", FuncName: "f"}
+	high1 := Job{Id: "3", FuncName: "f", Priority: 5}
+	high2 := Job{Id: "4", FuncName: "f", Priority: 5}
+
+	assertPoolRun(t, submitPoolRun(s, j1), "start")
+	chLow := submitPoolRun(s, low)
+	chHigh1 := submitPoolRun(s, high1)
+	chHigh2 := submitPoolRun(s, high2)
+
+	s.releaseRun(j1)
+	assertPoolRun(t, chHigh1, "start")
+	s.releaseRun(high1)
+	assertPoolRun(t, chHigh2, "start")
+	s.releaseRun(high2)
+	assertPoolRun(t, chLow, "start")
+}
+
+func TestSchedulerPoolOverflow(t *testing.T) {
+	s := &Scheduler{PoolSize: 1, BacklogSize: 1, OverflowPolicy: OVERFLOW_DROP}
+	j1 := Job{Id: "1", FuncName: "f"}
+	j2 := Job{Id: "2", FuncName: "f"}
+	j3 := Job{Id: "3", FuncName: "f"}
+
+	assertPoolRun(t, submitPoolRun(s, j1), "start")
+	ch2 := submitPoolRun(s, j2)
+	assertPoolRun(t, submitPoolRun(s, j3), WORKFLOW_SKIPPED)
+	assert.Equal(t, RECORD_SKIPPED, s.records.get(j3.Id)[0].Status)
+
+	s.OverflowPolicy = OVERFLOW_DEFER
+	ch3 := submitPoolRun(s, j3)
+	assert.Equal(t, RECORD_DEFERRED, s.records.get(j3.Id)[0].Status)
+
+	// Submitted again after the interval, when the backlog has room.
+	s.releaseRun(j1)
+	assertPoolRun(t, ch2, "start")
+	time.Sleep(POOL_DEFER_INTERVAL + 100*time.Millisecond)
+	assert.Equal(t, 1, s.PoolStats().Backlog)
+
+	s.releaseRun(j2)
+	assertPoolRun(t, ch3, "start")
+}
+
+func TestSchedulerPoolBlock(t *testing.T) {
+	s := &Scheduler{PoolSize: 1, BacklogSize: 1}
+	j1 := Job{Id: "1", FuncName: "f"}
+	j2 := Job{Id: "2", FuncName: "f"}
+	j3 := Job{Id: "3", FuncName: "f"}
+
+	assertPoolRun(t, submitPoolRun(s, j1), "start")
+	ch2 := submitPoolRun(s, j2)
+
+	// The caller returns, the run waits for room in the backlog.
+	ch3 := submitPoolRun(s, j3)
+	select {
+	case got := <-ch3:
+		assert.Fail(t, "the run is not blocked", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+	assert.Equal(t, 1, s.PoolStats().Backlog)
+
+	s.releaseRun(j1)
+	assertPoolRun(t, ch2, "start")
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 1, s.PoolStats().Backlog)
+
+	// The backlog is canceled by shutdown.
+	s.closePool()
+	assertPoolRun(t, ch3, RECORD_CANCELED)
+	assertPoolRun(t, submitPoolRun(s, j1), RECORD_CANCELED)
+}
+
+func TestPoolStats(t *testing.T) {
+	n := map[string]any{}
+	ps := PoolStats{Size: 4, Running: 3, Backlog: 1, Utilization: 0.75}
+	ps.setTo(n)
+	assert.Equal(t, ps, poolStatsFrom(n))
+	assert.False(t, ps.hasCapacity())
+	assert.True(t, poolStatsFrom(map[string]any{}).hasCapacity())
+}
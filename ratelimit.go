@@ -0,0 +1,229 @@
+package agscheduler
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// A token bucket, a run takes a token before it is dispatched.
+type RateLimit struct {
+	// Tokens added per second, the limit is ignored if it is not positive.
+	Rate float64 `json:"rate"`
+	// The most tokens in the bucket, the runs dispatched at once.
+	// Default: `1`
+	Burst int `json:"burst"`
+}
+
+// The runs delayed by a rate limit of a scheduler.
+type ThrottleStats struct {
+	// The runs delayed so far.
+	Throttled int `json:"throttled"`
+	// The runs waiting for their tokens now.
+	Waiting int `json:"waiting"`
+	// In seconds.
+	TotalDelay float64 `json:"total_delay"`
+	// In seconds.
+	MaxDelay float64 `json:"max_delay"`
+}
+
+// The runs waiting for the tokens of their rate limits.
+type throttledRuns struct {
+	mu sync.Mutex
+
+	// def: map[<limit name>]ThrottleStats
+	stats map[string]ThrottleStats
+	// Closed by `Shutdown`, the waiting runs are submitted at once and rejected by the pool.
+	done   chan struct{}
+	closed bool
+}
+
+// Called with `tr.mu` held.
+func (tr *throttledRuns) init() {
+	if tr.done == nil {
+		tr.stats = make(map[string]ThrottleStats)
+		tr.done = make(chan struct{})
+	}
+}
+
+// Count a run waiting `delay` for the tokens of `names`,
+// returns the channel closed when the waiting runs should stop waiting.
+func (tr *throttledRuns) add(names []string, delay time.Duration) <-chan struct{} {
+	defer tr.mu.Unlock()
+
+	tr.mu.Lock()
+	tr.init()
+
+	for _, name := range names {
+		ts := tr.stats[name]
+		ts.Throttled++
+		ts.Waiting++
+		ts.TotalDelay += delay.Seconds()
+		ts.MaxDelay = max(ts.MaxDelay, delay.Seconds())
+		tr.stats[name] = ts
+	}
+
+	return tr.done
+}
+
+func (tr *throttledRuns) finish(names []string) {
+	defer tr.mu.Unlock()
+
+	tr.mu.Lock()
+	tr.init()
+
+	for _, name := range names {
+		ts := tr.stats[name]
+		ts.Waiting--
+		tr.stats[name] = ts
+	}
+}
+
+func (tr *throttledRuns) stop() {
+	defer tr.mu.Unlock()
+
+	tr.mu.Lock()
+	tr.init()
+
+	if !tr.closed {
+		tr.closed = true
+		close(tr.done)
+	}
+}
+
+// A token bucket is stored as the time its next token is theoretically available, in Unix microseconds (GCRA).
+// Returns the time to store after reserving a token and how long to wait until the token is available.
+// Used by the stores implementing `TokenStore`.
+func ReserveTokenAt(tat int64, now time.Time, rate float64, burst int) (int64, time.Duration) {
+	n := now.UnixMicro()
+	interval := int64(float64(time.Second/time.Microsecond) / rate)
+	burst = max(burst, 1)
+
+	tat = max(tat, n) + interval
+	wait := max(tat-int64(burst)*interval-n, 0)
+
+	return tat, time.Duration(wait) * time.Microsecond
+}
+
+// Returns the time to store after giving back a reserved token,
+// the tokens refilled meanwhile are not given back twice.
+// Used by the stores implementing `TokenStore`.
+func ReturnTokenAt(tat int64, now time.Time, rate float64) int64 {
+	n := now.UnixMicro()
+	interval := int64(float64(time.Second/time.Microsecond) / rate)
+	if tat <= n {
+		return tat
+	}
+
+	return max(tat-interval, n)
+}
+
+// Keeps the token buckets of a scheduler whose store does not implement `TokenStore`.
+type memoryTokens struct {
+	mu sync.Mutex
+
+	// def: map[<bucket name>]<theoretical arrival time>
+	tats map[string]int64
+}
+
+func (mt *memoryTokens) ReserveToken(name string, rate float64, burst int) (time.Duration, error) {
+	defer mt.mu.Unlock()
+
+	mt.mu.Lock()
+
+	if mt.tats == nil {
+		mt.tats = make(map[string]int64)
+	}
+	tat, wait := ReserveTokenAt(mt.tats[name], time.Now(), rate, burst)
+	mt.tats[name] = tat
+
+	return wait, nil
+}
+
+func (mt *memoryTokens) ReturnToken(name string, rate float64) error {
+	defer mt.mu.Unlock()
+
+	mt.mu.Lock()
+
+	if tat, ok := mt.tats[name]; ok {
+		mt.tats[name] = ReturnTokenAt(tat, time.Now(), rate)
+	}
+
+	return nil
+}
+
+// Returns the store when it implements `TokenStore`, otherwise the token buckets in process.
+func (s *Scheduler) tokenStore() TokenStore {
+	if ts, ok := s.store.(TokenStore); ok {
+		return ts
+	}
+
+	return &s.tokens
+}
+
+// The rate limits of a run, by the names of their token buckets in the store,
+// `func:<FuncName>` and `queue:<queue>`.
+// In cluster mode the queue is the queue of this node, as for `QueueLimits`.
+func (s *Scheduler) runRateLimits(j Job) map[string]RateLimit {
+	limits := make(map[string]RateLimit)
+	if rl, ok := s.FuncRateLimits[j.FuncName]; ok && rl.Rate > 0 {
+		limits["func:"+j.FuncName] = rl
+	}
+	for _, q := range s.runQueues(j) {
+		if rl, ok := s.QueueRateLimits[q]; ok && rl.Rate > 0 {
+			limits["queue:"+q] = rl
+		}
+	}
+
+	return limits
+}
+
+// Reserve a token of each rate limit of the run through the store,
+// so that the nodes of a cluster share the buckets, unless the store does not implement `TokenStore`.
+// Returns how long the run waits for all of them, the names of the limits delaying it,
+// and a function giving back the tokens when the run never starts.
+// A limit whose token can not be reserved is logged and ignored.
+func (s *Scheduler) reserveTokens(j Job) (time.Duration, []string, func()) {
+	var delay time.Duration
+	names := make([]string, 0)
+	reserved := make(map[string]RateLimit)
+	for name, rl := range s.runRateLimits(j) {
+		wait, err := s.tokenStore().ReserveToken(name, rl.Rate, rl.Burst)
+		if err != nil {
+			slog.Error(fmt.Sprintf("Job `%s` reserve token of rate limit `%s` error: %s\n", j.FullName(), name, err))
+			continue
+		}
+		reserved[name] = rl
+		if wait > 0 {
+			names = append(names, name)
+			delay = max(delay, wait)
+		}
+	}
+	sort.Strings(names)
+
+	giveBack := func() {
+		for name, rl := range reserved {
+			if err := s.tokenStore().ReturnToken(name, rl.Rate); err != nil {
+				slog.Error(fmt.Sprintf("Job `%s` return token of rate limit `%s` error: %s\n", j.FullName(), name, err))
+			}
+		}
+	}
+
+	return delay, names, giveBack
+}
+
+// Returns the runs delayed by the rate limits of this scheduler, by limit name such as `func:main.callAPI`.
+func (s *Scheduler) ThrottleStats() map[string]ThrottleStats {
+	defer s.throttled.mu.Unlock()
+
+	s.throttled.mu.Lock()
+
+	stats := make(map[string]ThrottleStats, len(s.throttled.stats))
+	for name, ts := range s.throttled.stats {
+		stats[name] = ts
+	}
+
+	return stats
+}
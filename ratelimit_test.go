@@ -0,0 +1,86 @@
+package agscheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedulerRunRateLimits(t *testing.T) {
+	s := &Scheduler{
+		FuncRateLimits:  map[string]RateLimit{"f": {Rate: 10}, "g": {Rate: 0}},
+		QueueRateLimits: map[string]RateLimit{"q": {Rate: 5, Burst: 2}},
+	}
+
+	assert.Equal(t,
+		map[string]RateLimit{"func:f": {Rate: 10}, "queue:q": {Rate: 5, Burst: 2}},
+		s.runRateLimits(Job{FuncName: "f", Queues: []string{"q", "other"}}),
+	)
+	// Ignored without a positive rate.
+	assert.Empty(t, s.runRateLimits(Job{FuncName: "g"}))
+
+	// In cluster mode, the queue of the node.
+	s.clusterNode = &ClusterNode{Queue: "q"}
+	assert.Contains(t, s.runRateLimits(Job{FuncName: "g"}), "queue:q")
+}
+
+func TestThrottledRuns(t *testing.T) {
+	tr := throttledRuns{}
+	done := tr.add([]string{"func:f", "queue:q"}, time.Second)
+	tr.add([]string{"func:f"}, 3*time.Second)
+	tr.finish([]string{"func:f", "queue:q"})
+
+	assert.Equal(t, ThrottleStats{Throttled: 2, Waiting: 1, TotalDelay: 4, MaxDelay: 3}, tr.stats["func:f"])
+	assert.Equal(t, ThrottleStats{Throttled: 1, Waiting: 0, TotalDelay: 1, MaxDelay: 1}, tr.stats["queue:q"])
+
+	tr.stop()
+	tr.stop()
+	select {
+	case <-done:
+	default:
+		assert.Fail(t, "the waiting runs are not stopped")
+	}
+}
+
+func TestReserveTokenAt(t *testing.T) {
+	now := time.UnixMicro(1e15)
+
+	tat, wait := ReserveTokenAt(0, now, 4, 2)
+	assert.Equal(t, int64(1e15+250000), tat)
+	assert.Zero(t, wait)
+	tat, wait = ReserveTokenAt(tat, now, 4, 2)
+	assert.Zero(t, wait)
+	tat, wait = ReserveTokenAt(tat, now, 4, 2)
+	assert.Equal(t, 250*time.Millisecond, wait)
+
+	// Refilled meanwhile.
+	_, wait = ReserveTokenAt(tat, now.Add(time.Second), 4, 2)
+	assert.Zero(t, wait)
+}
+
+// The token buckets are kept in process when the store does not implement `TokenStore`.
+func TestSchedulerReserveTokensFallback(t *testing.T) {
+	s := &Scheduler{FuncRateLimits: map[string]RateLimit{"f": {Rate: 10}}}
+
+	delay, names, _ := s.reserveTokens(Job{FuncName: "f"})
+	assert.Zero(t, delay)
+	assert.Empty(t, names)
+	delay, names, giveBack := s.reserveTokens(Job{FuncName: "f"})
+	assert.InDelta(t, 100*time.Millisecond, delay, float64(50*time.Millisecond))
+	assert.Equal(t, []string{"func:f"}, names)
+
+	// Reserved again once given back.
+	giveBack()
+	delay, _, _ = s.reserveTokens(Job{FuncName: "f"})
+	assert.InDelta(t, 100*time.Millisecond, delay, float64(50*time.Millisecond))
+}
+
+func TestReturnTokenAt(t *testing.T) {
+	now := time.UnixMicro(1e15)
+
+	assert.Equal(t, int64(1e15+250000), ReturnTokenAt(1e15+500000, now, 4))
+	assert.Equal(t, int64(1e15), ReturnTokenAt(1e15+100000, now, 4))
+	// Refilled meanwhile.
+	assert.Equal(t, int64(1e15-1), ReturnTokenAt(1e15-1, now, 4))
+}
@@ -0,0 +1,206 @@
+package agscheduler
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/kurtloong/agscheduler/services/proto"
+)
+
+// constant indicating a record's status
+const (
+	RECORD_RUNNING   = "running"
+	RECORD_SUCCEEDED = "succeeded"
+	RECORD_FAILED    = "failed"
+	RECORD_TIMEOUT   = "timeout"
+	RECORD_CANCELED  = "canceled"
+	// Not a run, the run was deferred to the next allowed window.
+	RECORD_DEFERRED = "deferred"
+	// Not a run, the run was skipped.
+	RECORD_SKIPPED = "skipped"
+	// Not a run, the run waits for its concurrency keys held by other runs.
+	RECORD_BLOCKED = "blocked"
+)
+
+// A run of a job, kept in the run history of the scheduler which ran it.
+type Record struct {
+	Id       string `json:"id"`
+	JobId    string `json:"job_id"`
+	JobName  string `json:"job_name"`
+	FuncName string `json:"func_name"`
+	// Optional: `RECORD_RUNNING` | `RECORD_SUCCEEDED` | `RECORD_FAILED` | `RECORD_TIMEOUT` | `RECORD_CANCELED`
+	// | `RECORD_DEFERRED` | `RECORD_SKIPPED` | `RECORD_BLOCKED`
+	Status  string    `json:"status"`
+	StartAt time.Time `json:"start_at"`
+	EndAt   time.Time `json:"end_at"`
+	// Returned by the built-in functions, such as the exit code and output of a command.
+	Result map[string]any `json:"result"`
+	Error  string         `json:"error"`
+	// Links the runs of the same workflow run.
+	WorkflowRunId string `json:"workflow_run_id"`
+	// How long the run was delayed by the rate limits, in seconds.
+	ThrottleDelay float64 `json:"throttle_delay"`
+}
+
+// Keep the latest records in memory, the oldest ones are dropped when it is full.
+type recordStore struct {
+	mu sync.Mutex
+
+	records []Record
+}
+
+// Default `limit`: 1000
+func (rs *recordStore) start(j Job, limit int) string {
+	return rs.add(j, RECORD_RUNNING, nil, limit)
+}
+
+// Record something happening to a run which has not started, such as a deferral.
+func (rs *recordStore) event(j Job, status string, result map[string]any, limit int) string {
+	return rs.add(j, status, result, limit)
+}
+
+func (rs *recordStore) add(j Job, status string, result map[string]any, limit int) string {
+	defer rs.mu.Unlock()
+
+	rs.mu.Lock()
+
+	now := time.Now().UTC()
+	r := Record{
+		Id:       strings.Replace(uuid.New().String(), "-", "", -1)[:16],
+		JobId:    j.Id,
+		JobName:  j.Name,
+		FuncName: j.FuncName,
+		Status:   status,
+		StartAt:  now,
+		Result:   result,
+
+		WorkflowRunId: j.WorkflowRunId,
+	}
+	if status != RECORD_RUNNING {
+		r.EndAt = now
+	}
+	rs.records = append(rs.records, r)
+
+	if limit <= 0 {
+		limit = 1000
+	}
+	if len(rs.records) > limit {
+		rs.records = rs.records[len(rs.records)-limit:]
+	}
+
+	return r.Id
+}
+
+// Set how long the run was delayed by the rate limits before it started.
+func (rs *recordStore) throttled(id string, delay time.Duration) {
+	defer rs.mu.Unlock()
+
+	rs.mu.Lock()
+
+	for i := len(rs.records) - 1; i >= 0; i-- {
+		if rs.records[i].Id == id {
+			rs.records[i].ThrottleDelay = delay.Seconds()
+			return
+		}
+	}
+}
+
+// Only a running record is finished,
+// so that a function returning after its timeout does not overwrite the status.
+// Returns whether the record is finished by this call.
+func (rs *recordStore) finish(id string, status string, result map[string]any, err error) bool {
+	defer rs.mu.Unlock()
+
+	rs.mu.Lock()
+
+	for i := len(rs.records) - 1; i >= 0; i-- {
+		r := &rs.records[i]
+		if r.Id != id {
+			continue
+		}
+		if r.Status != RECORD_RUNNING {
+			return false
+		}
+
+		r.Status = status
+		r.EndAt = time.Now().UTC()
+		r.Result = result
+		if err != nil {
+			r.Error = err.Error()
+		}
+		return true
+	}
+
+	return false
+}
+
+// Returns the records of a job, latest first,
+// all records are returned when `jobId` is empty.
+func (rs *recordStore) get(jobId string) []Record {
+	defer rs.mu.Unlock()
+
+	rs.mu.Lock()
+
+	records := make([]Record, 0)
+	for i := len(rs.records) - 1; i >= 0; i-- {
+		if jobId == "" || rs.records[i].JobId == jobId {
+			records = append(records, rs.records[i])
+		}
+	}
+
+	return records
+}
+
+// Used to gRPC Protobuf
+func RecordsToPbRecordsPtr(records []Record) *pb.Records {
+	pbRs := pb.Records{}
+
+	for _, r := range records {
+		result, _ := structpb.NewStruct(r.Result)
+		pbRs.Records = append(pbRs.Records, &pb.Record{
+			Id:       r.Id,
+			JobId:    r.JobId,
+			JobName:  r.JobName,
+			FuncName: r.FuncName,
+			Status:   r.Status,
+			StartAt:  timestamppb.New(r.StartAt),
+			EndAt:    timestamppb.New(r.EndAt),
+			Result:   result,
+			Error:    r.Error,
+
+			WorkflowRunId: r.WorkflowRunId,
+			ThrottleDelay: r.ThrottleDelay,
+		})
+	}
+
+	return &pbRs
+}
+
+// Used to gRPC Protobuf
+func PbRecordsPtrToRecords(pbRs *pb.Records) []Record {
+	records := make([]Record, 0)
+
+	for _, pbR := range pbRs.Records {
+		records = append(records, Record{
+			Id:       pbR.GetId(),
+			JobId:    pbR.GetJobId(),
+			JobName:  pbR.GetJobName(),
+			FuncName: pbR.GetFuncName(),
+			Status:   pbR.GetStatus(),
+			StartAt:  pbR.GetStartAt().AsTime(),
+			EndAt:    pbR.GetEndAt().AsTime(),
+			Result:   pbR.GetResult().AsMap(),
+			Error:    pbR.GetError(),
+
+			WorkflowRunId: pbR.GetWorkflowRunId(),
+			ThrottleDelay: pbR.GetThrottleDelay(),
+		})
+	}
+
+	return records
+}
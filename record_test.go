@@ -0,0 +1,64 @@
+package agscheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordStore(t *testing.T) {
+	rs := &recordStore{}
+	j := Job{Id: "1", Name: "job", FuncName: "func"}
+
+	id := rs.start(j, 0)
+	records := rs.get("1")
+	assert.Len(t, records, 1)
+	assert.Equal(t, RECORD_RUNNING, records[0].Status)
+	assert.Equal(t, "func", records[0].FuncName)
+
+	assert.True(t, rs.finish(id, RECORD_FAILED, map[string]any{"exit_code": 1}, errors.New("err")))
+	// A finished record is not overwritten.
+	assert.False(t, rs.finish(id, RECORD_SUCCEEDED, nil, nil))
+
+	records = rs.get("1")
+	assert.Equal(t, RECORD_FAILED, records[0].Status)
+	assert.Equal(t, "err", records[0].Error)
+	assert.Equal(t, map[string]any{"exit_code": 1}, records[0].Result)
+	assert.False(t, records[0].EndAt.IsZero())
+
+	assert.Empty(t, rs.get("2"))
+}
+
+func TestRecordStoreLimit(t *testing.T) {
+	rs := &recordStore{}
+
+	for _, id := range []string{"1", "2", "3"} {
+		rs.start(Job{Id: id}, 2)
+	}
+
+	records := rs.get("")
+	assert.Len(t, records, 2)
+	assert.Equal(t, "3", records[0].JobId)
+	assert.Equal(t, "2", records[1].JobId)
+}
+
+func TestRecordsToPbRecordsPtr(t *testing.T) {
+	now := time.Now().UTC()
+	records := []Record{{
+		Id:       "1",
+		JobId:    "2",
+		JobName:  "job",
+		FuncName: "func",
+		Status:   RECORD_SUCCEEDED,
+		StartAt:  now,
+		EndAt:    now,
+		Result:   map[string]any{"stdout": "ok"},
+	}}
+
+	pbRs := RecordsToPbRecordsPtr(records)
+
+	assert.Len(t, pbRs.Records, 1)
+	assert.Equal(t, records, PbRecordsPtrToRecords(pbRs))
+}
@@ -0,0 +1,340 @@
+package agscheduler
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/types/known/structpb"
+
+	pb "github.com/kurtloong/agscheduler/services/proto"
+)
+
+// Documentation of an argument in `Job.Args`.
+type FuncArg struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Required    bool   `json:"required"`
+}
+
+// Used in cluster mode, a node which registered the function.
+type FuncNode struct {
+	Id       string `json:"id"`
+	Queue    string `json:"queue"`
+	Endpoint string `json:"endpoint"`
+}
+
+// Information of a registered function, used to discover functions
+// when creating jobs over HTTP or gRPC.
+type FuncInfo struct {
+	// The name used as `Job.FuncName`, set automatically.
+	Name string `json:"name"`
+	// Friendly names which can also be used as `Job.FuncName`.
+	Aliases     []string `json:"aliases"`
+	Description string   `json:"description"`
+	// Functions registered by `RegisterTyped` are documented from their argument type,
+	// unless it is set manually.
+	Args []FuncArg `json:"args"`
+	// JSON Schema of the `Args`, set automatically.
+	Schema map[string]any `json:"schema"`
+
+	// Used in cluster mode, set automatically.
+	Nodes []FuncNode `json:"nodes"`
+}
+
+type registeredFunc struct {
+	// The result is recorded in the run history.
+	run func(context.Context, Job) (map[string]any, error)
+	// Set by `RegisterTyped`, used to check the `Args` of a job.
+	validate func(args map[string]any) []ArgsFieldError
+
+	info FuncInfo
+}
+
+// Record the actual path of function and the corresponding function.
+// Since golang can't serialize functions,
+// need to register them with `Register` before using it.
+//
+// Each scheduler can bind its own registry through `SetFuncRegistry`,
+// otherwise the default registry filled by `RegisterFuncs` is used.
+type FuncRegistry struct {
+	mu sync.RWMutex
+
+	funcMap map[string]registeredFunc
+	// def: map[<alias>]<name>
+	aliasMap map[string]string
+}
+
+func NewFuncRegistry() *FuncRegistry {
+	return &FuncRegistry{
+		funcMap:  make(map[string]registeredFunc),
+		aliasMap: make(map[string]string),
+	}
+}
+
+func (r *FuncRegistry) set(name string, rf registeredFunc) {
+	defer r.mu.Unlock()
+
+	r.mu.Lock()
+
+	if r.funcMap == nil {
+		r.funcMap = make(map[string]registeredFunc)
+	}
+
+	// Keep the information when a function is registered again.
+	if old, ok := r.funcMap[name]; ok {
+		rf.info.Aliases = old.info.Aliases
+		rf.info.Description = old.info.Description
+		if rf.validate == nil {
+			rf.info.Args = old.info.Args
+		}
+	}
+	rf.info.Name = name
+
+	r.funcMap[name] = rf
+}
+
+func (r *FuncRegistry) Register(fs ...func(context.Context, Job)) {
+	for _, f := range fs {
+		f := f
+		r.set(getFuncName(f), registeredFunc{
+			run: func(ctx context.Context, j Job) (map[string]any, error) {
+				f(ctx, j)
+				return nil, nil
+			},
+			info: FuncInfo{Schema: map[string]any{"type": "object"}},
+		})
+	}
+}
+
+// Register a function with its aliases, description and arguments.
+func (r *FuncRegistry) RegisterWithInfo(f func(context.Context, Job), info FuncInfo) error {
+	r.Register(f)
+
+	return r.Describe(getFuncName(f), info)
+}
+
+// Set the aliases, description and arguments of a registered function,
+// `name` can also be an alias.
+func (r *FuncRegistry) Describe(name string, info FuncInfo) error {
+	defer r.mu.Unlock()
+
+	r.mu.Lock()
+
+	name = r.resolve(name)
+	rf, ok := r.funcMap[name]
+	if !ok {
+		return FuncUnregisteredError(name)
+	}
+
+	for _, alias := range info.Aliases {
+		if n, ok := r.aliasMap[alias]; ok && n != name {
+			return fmt.Errorf("function alias `%s` already used by `%s`", alias, n)
+		}
+		if _, ok := r.funcMap[alias]; ok && alias != name {
+			return fmt.Errorf("function alias `%s` already used by a function", alias)
+		}
+	}
+
+	if r.aliasMap == nil {
+		r.aliasMap = make(map[string]string)
+	}
+	for _, alias := range rf.info.Aliases {
+		delete(r.aliasMap, alias)
+	}
+	for _, alias := range info.Aliases {
+		r.aliasMap[alias] = name
+	}
+
+	rf.info.Aliases = info.Aliases
+	rf.info.Description = info.Description
+	if info.Args != nil {
+		rf.info.Args = info.Args
+	}
+	r.funcMap[name] = rf
+
+	return nil
+}
+
+// Returns the name of the function that an alias refers to.
+func (r *FuncRegistry) resolve(name string) string {
+	if n, ok := r.aliasMap[name]; ok {
+		return n
+	}
+
+	return name
+}
+
+// `name` can also be an alias.
+func (r *FuncRegistry) get(name string) (registeredFunc, bool) {
+	defer r.mu.RUnlock()
+
+	r.mu.RLock()
+
+	rf, ok := r.funcMap[r.resolve(name)]
+
+	return rf, ok
+}
+
+// Returns the names of all registered functions.
+func (r *FuncRegistry) Names() []string {
+	defer r.mu.RUnlock()
+
+	r.mu.RLock()
+
+	names := make([]string, 0, len(r.funcMap))
+	for name := range r.funcMap {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// Returns the information of all registered functions, sorted by name.
+func (r *FuncRegistry) List() []FuncInfo {
+	defer r.mu.RUnlock()
+
+	r.mu.RLock()
+
+	infos := make([]FuncInfo, 0, len(r.funcMap))
+	for _, rf := range r.funcMap {
+		infos = append(infos, rf.info)
+	}
+	slices.SortFunc(infos, func(a, b FuncInfo) int { return strings.Compare(a.Name, b.Name) })
+
+	return infos
+}
+
+// Returns the JSON Schema of the `Args` of a registered function,
+// functions registered by `Register` accept any object.
+func (r *FuncRegistry) Schema(name string) (map[string]any, error) {
+	rf, ok := r.get(name)
+	if !ok {
+		return nil, FuncUnregisteredError(name)
+	}
+
+	return rf.info.Schema, nil
+}
+
+// Used by the schedulers without their own registry.
+var defaultFuncRegistry = NewFuncRegistry()
+
+func getFuncName(f func(context.Context, Job)) string {
+	return runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+}
+
+// Register functions to the default registry.
+func RegisterFuncs(fs ...func(context.Context, Job)) {
+	defaultFuncRegistry.Register(fs...)
+}
+
+// Register a function with its aliases, description and arguments to the default registry.
+func RegisterFuncWithInfo(f func(context.Context, Job), info FuncInfo) error {
+	return defaultFuncRegistry.RegisterWithInfo(f, info)
+}
+
+// Set the aliases, description and arguments of a function in the default registry.
+func DescribeFunc(name string, info FuncInfo) error {
+	return defaultFuncRegistry.Describe(name, info)
+}
+
+// Register a function with typed arguments to the default registry.
+//
+// The `Args` of a job are decoded into `T` before calling the function,
+// and are validated when the scheduler run `AddJob` or `UpdateJob`.
+// Jobs refer to this function by `FuncName`.
+func RegisterTyped[T any](name string, f func(context.Context, Job, T) error) {
+	RegisterTypedTo(defaultFuncRegistry, name, f)
+}
+
+// Same as `RegisterTyped`, but register to the given registry.
+func RegisterTypedTo[T any](r *FuncRegistry, name string, f func(context.Context, Job, T) error) {
+	registerTypedResultTo(r, name, func(ctx context.Context, j Job, args T) (map[string]any, error) {
+		return nil, f(ctx, j, args)
+	})
+}
+
+// Used by the built-in functions, the result is recorded in the run history.
+func registerTypedResultTo[T any](r *FuncRegistry, name string, f func(context.Context, Job, T) (map[string]any, error)) {
+	r.set(name, registeredFunc{
+		run: func(ctx context.Context, j Job) (map[string]any, error) {
+			args, fields := decodeArgs[T](j.Args)
+			if len(fields) > 0 {
+				return nil, &JobArgsError{FullName: j.FullName(), FuncName: name, Fields: fields}
+			}
+
+			return f(ctx, j, args)
+		},
+		validate: func(args map[string]any) []ArgsFieldError {
+			_, fields := decodeArgs[T](args)
+			return fields
+		},
+		info: FuncInfo{
+			Args:   argsDoc[T](),
+			Schema: argsSchema[T](name),
+		},
+	})
+}
+
+// Used to gRPC Protobuf
+func FuncInfosToPbFuncsPtr(infos []FuncInfo) *pb.Funcs {
+	pbFs := pb.Funcs{}
+
+	for _, info := range infos {
+		schema, _ := structpb.NewStruct(info.Schema)
+		pbF := &pb.Func{
+			Name:        info.Name,
+			Aliases:     info.Aliases,
+			Description: info.Description,
+			Schema:      schema,
+		}
+		for _, a := range info.Args {
+			pbF.Args = append(pbF.Args, &pb.FuncArg{
+				Name:        a.Name,
+				Type:        a.Type,
+				Description: a.Description,
+				Required:    a.Required,
+			})
+		}
+		for _, n := range info.Nodes {
+			pbF.Nodes = append(pbF.Nodes, &pb.FuncNode{Id: n.Id, Queue: n.Queue, Endpoint: n.Endpoint})
+		}
+		pbFs.Funcs = append(pbFs.Funcs, pbF)
+	}
+
+	return &pbFs
+}
+
+// Used to gRPC Protobuf
+func PbFuncsPtrToFuncInfos(pbFs *pb.Funcs) []FuncInfo {
+	infos := make([]FuncInfo, 0)
+
+	for _, pbF := range pbFs.Funcs {
+		info := FuncInfo{
+			Name:        pbF.GetName(),
+			Aliases:     pbF.GetAliases(),
+			Description: pbF.GetDescription(),
+			Schema:      pbF.GetSchema().AsMap(),
+		}
+		for _, pbA := range pbF.GetArgs() {
+			info.Args = append(info.Args, FuncArg{
+				Name:        pbA.GetName(),
+				Type:        pbA.GetType(),
+				Description: pbA.GetDescription(),
+				Required:    pbA.GetRequired(),
+			})
+		}
+		for _, pbN := range pbF.GetNodes() {
+			info.Nodes = append(info.Nodes, FuncNode{Id: pbN.GetId(), Queue: pbN.GetQueue(), Endpoint: pbN.GetEndpoint()})
+		}
+		infos = append(infos, info)
+	}
+
+	return infos
+}
@@ -0,0 +1,149 @@
+package agscheduler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func dryRunRegistry(ctx context.Context, j Job) {}
+
+func TestFuncRegistry(t *testing.T) {
+	r := NewFuncRegistry()
+	assert.Empty(t, r.Names())
+
+	r.Register(dryRunRegistry)
+
+	name := getFuncName(dryRunRegistry)
+	assert.Equal(t, []string{name}, r.Names())
+	_, ok := r.get(name)
+	assert.True(t, ok)
+	_, ok = defaultFuncRegistry.get(name)
+	assert.False(t, ok)
+}
+
+func TestFuncRegistryRegisterMultiple(t *testing.T) {
+	r := NewFuncRegistry()
+	called := ""
+	f1 := func(ctx context.Context, j Job) { called = "f1" }
+	f2 := func(ctx context.Context, j Job) { called = "f2" }
+	r.Register(f1, f2)
+
+	rf, _ := r.get(getFuncName(f1))
+	rf.run(context.TODO(), Job{})
+	assert.Equal(t, "f1", called)
+	rf, _ = r.get(getFuncName(f2))
+	rf.run(context.TODO(), Job{})
+	assert.Equal(t, "f2", called)
+}
+
+func TestFuncRegistryZeroValue(t *testing.T) {
+	r := &FuncRegistry{}
+	_, ok := r.get(getFuncName(dryRunRegistry))
+	assert.False(t, ok)
+
+	r.Register(dryRunRegistry)
+
+	assert.Len(t, r.Names(), 1)
+}
+
+func TestFuncRegistrySchema(t *testing.T) {
+	r := NewFuncRegistry()
+	r.Register(dryRunRegistry)
+	RegisterTypedTo(r, "typed", func(ctx context.Context, j Job, args argsValidated) error { return nil })
+
+	s, err := r.Schema(getFuncName(dryRunRegistry))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"type": "object"}, s)
+
+	s, err = r.Schema("typed")
+	assert.NoError(t, err)
+	assert.Equal(t, "typed", s["title"])
+
+	_, err = r.Schema("unknown")
+	assert.ErrorIs(t, err, FuncUnregisteredError("unknown"))
+}
+
+func TestRegisterTypedTo(t *testing.T) {
+	r := NewFuncRegistry()
+	var got argsValidated
+	RegisterTypedTo(r, "typed", func(ctx context.Context, j Job, args argsValidated) error {
+		got = args
+		return nil
+	})
+
+	rf, ok := r.get("typed")
+	assert.True(t, ok)
+	assert.Empty(t, rf.validate(map[string]any{"times": float64(2)}))
+
+	_, err := rf.run(context.TODO(), Job{Id: "1", Name: "job", Args: map[string]any{"times": float64(2)}})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, got.Times)
+
+	_, err = rf.run(context.TODO(), Job{Id: "1", Name: "job", Args: map[string]any{"times": "2"}})
+	assert.IsType(t, &JobArgsError{}, err)
+}
+
+func TestFuncRegistryDescribe(t *testing.T) {
+	r := NewFuncRegistry()
+	err := r.RegisterWithInfo(dryRunRegistry, FuncInfo{
+		Aliases:     []string{"dry"},
+		Description: "Do nothing",
+		Args:        []FuncArg{{Name: "times", Type: "integer"}},
+	})
+	assert.NoError(t, err)
+	RegisterTypedTo(r, "typed", func(ctx context.Context, j Job, args argsValidated) error { return nil })
+
+	rf, ok := r.get("dry")
+	assert.True(t, ok)
+	assert.Equal(t, getFuncName(dryRunRegistry), rf.info.Name)
+	assert.Equal(t, "Do nothing", rf.info.Description)
+
+	err = r.Describe("typed", FuncInfo{Aliases: []string{"dry"}})
+	assert.Error(t, err)
+	err = r.Describe("typed", FuncInfo{Aliases: []string{getFuncName(dryRunRegistry)}})
+	assert.Error(t, err)
+	err = r.Describe("unknown", FuncInfo{})
+	assert.ErrorIs(t, err, FuncUnregisteredError("unknown"))
+
+	err = r.Describe("dry", FuncInfo{Aliases: []string{"noop"}})
+	assert.NoError(t, err)
+	_, ok = r.get("dry")
+	assert.False(t, ok)
+	_, ok = r.get("noop")
+	assert.True(t, ok)
+
+	// Registering again keeps the information.
+	r.Register(dryRunRegistry)
+	rf, _ = r.get("noop")
+	assert.Equal(t, []FuncArg{{Name: "times", Type: "integer"}}, rf.info.Args)
+}
+
+func TestFuncRegistryList(t *testing.T) {
+	r := NewFuncRegistry()
+	r.Register(dryRunRegistry)
+	RegisterTypedTo(r, "typed", func(ctx context.Context, j Job, args argsValidated) error { return nil })
+
+	infos := r.List()
+	assert.Len(t, infos, 2)
+	assert.Equal(t, getFuncName(dryRunRegistry), infos[0].Name)
+	assert.Equal(t, "typed", infos[1].Name)
+	assert.Equal(t, []FuncArg{{Name: "times", Type: "integer", Required: true}}, infos[1].Args)
+}
+
+func TestFuncInfosToPbFuncsPtr(t *testing.T) {
+	infos := []FuncInfo{{
+		Name:        "typed",
+		Aliases:     []string{"t"},
+		Description: "Typed",
+		Args:        []FuncArg{{Name: "times", Type: "integer", Description: "Times", Required: true}},
+		Schema:      map[string]any{"type": "object"},
+		Nodes:       []FuncNode{{Id: "1", Queue: "default", Endpoint: "127.0.0.1:36380"}},
+	}}
+
+	pbFs := FuncInfosToPbFuncsPtr(infos)
+
+	assert.Len(t, pbFs.Funcs, 1)
+	assert.Equal(t, infos, PbFuncsPtrToFuncInfos(pbFs))
+}
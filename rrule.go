@@ -0,0 +1,550 @@
+package agscheduler
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The most periods of a rule scanned for the next occurrence, before giving up.
+const RRULE_MAX_PERIODS = 100000
+
+const (
+	rruleYearly = iota
+	rruleMonthly
+	rruleWeekly
+	rruleDaily
+	rruleHourly
+	rruleMinutely
+	rruleSecondly
+)
+
+var rruleFreqs = map[string]int{
+	"YEARLY":   rruleYearly,
+	"MONTHLY":  rruleMonthly,
+	"WEEKLY":   rruleWeekly,
+	"DAILY":    rruleDaily,
+	"HOURLY":   rruleHourly,
+	"MINUTELY": rruleMinutely,
+	"SECONDLY": rruleSecondly,
+}
+
+var rruleByDayRegexp = regexp.MustCompile(`^([+-]?\d{1,2})?(MO|TU|WE|TH|FR|SA|SU)$`)
+
+type rruleWeekday struct {
+	// 0 for every such weekday of the period, or its position such as `2` or `-1`.
+	n       int
+	weekday time.Weekday
+}
+
+// A `RRULE` of RFC 5545, `BYWEEKNO` is not supported.
+type rrule struct {
+	loc     *time.Location
+	dtstart time.Time
+
+	freq     int
+	interval int
+	count    int
+	until    time.Time
+	wkst     time.Weekday
+
+	byMonth    []int
+	byMonthDay []int
+	byYearDay  []int
+	byDay      []rruleWeekday
+	byHour     []int
+	byMinute   []int
+	bySecond   []int
+	bySetPos   []int
+}
+
+func parseRRuleInts(name string, value string, min int, max int, allowNeg bool) ([]int, error) {
+	vs := make([]int, 0)
+	for _, s := range strings.Split(value, ",") {
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s `%s` must be integers", name, value)
+		}
+		if !((v >= min && v <= max) || (allowNeg && v <= -min && v >= -max && v != 0)) {
+			return nil, fmt.Errorf("%s `%d` out of range", name, v)
+		}
+		vs = append(vs, v)
+	}
+
+	return vs, nil
+}
+
+// Parse the value of a `RRULE` line, such as `FREQ=MONTHLY;BYDAY=2TU`.
+func parseRRule(value string, dtstart time.Time, loc *time.Location) (*rrule, error) {
+	r := &rrule{loc: loc, dtstart: dtstart, interval: 1, freq: -1, wkst: time.Monday}
+
+	for _, part := range strings.Split(value, ";") {
+		if part == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(part, "=")
+		if !ok || v == "" {
+			return nil, fmt.Errorf("part `%s` must be `NAME=VALUE`", part)
+		}
+		k, v = strings.ToUpper(k), strings.ToUpper(v)
+
+		var err error
+		switch k {
+		case "FREQ":
+			freq, ok := rruleFreqs[v]
+			if !ok {
+				return nil, fmt.Errorf("FREQ `%s` unknown", v)
+			}
+			r.freq = freq
+		case "INTERVAL":
+			if r.interval, err = strconv.Atoi(v); err != nil || r.interval <= 0 {
+				return nil, fmt.Errorf("INTERVAL `%s` must be a positive integer", v)
+			}
+		case "COUNT":
+			if r.count, err = strconv.Atoi(v); err != nil || r.count <= 0 {
+				return nil, fmt.Errorf("COUNT `%s` must be a positive integer", v)
+			}
+		case "UNTIL":
+			until, isDate, err := parseICSTime(nil, v, loc)
+			if err != nil {
+				return nil, fmt.Errorf("UNTIL `%s` error: %s", v, err)
+			}
+			if isDate {
+				until = until.AddDate(0, 0, 1).Add(-time.Second)
+			}
+			r.until = until
+		case "WKST":
+			wd, ok := icsWeekdays[v]
+			if !ok {
+				return nil, fmt.Errorf("WKST `%s` unknown", v)
+			}
+			r.wkst = time.Weekday(wd)
+		case "BYMONTH":
+			r.byMonth, err = parseRRuleInts(k, v, 1, 12, false)
+		case "BYMONTHDAY":
+			r.byMonthDay, err = parseRRuleInts(k, v, 1, 31, true)
+		case "BYYEARDAY":
+			r.byYearDay, err = parseRRuleInts(k, v, 1, 366, true)
+		case "BYHOUR":
+			r.byHour, err = parseRRuleInts(k, v, 0, 23, false)
+		case "BYMINUTE":
+			r.byMinute, err = parseRRuleInts(k, v, 0, 59, false)
+		case "BYSECOND":
+			r.bySecond, err = parseRRuleInts(k, v, 0, 59, false)
+		case "BYSETPOS":
+			r.bySetPos, err = parseRRuleInts(k, v, 1, 366, true)
+		case "BYDAY":
+			for _, s := range strings.Split(v, ",") {
+				m := rruleByDayRegexp.FindStringSubmatch(s)
+				if m == nil {
+					return nil, fmt.Errorf("BYDAY `%s` unknown", s)
+				}
+				n := 0
+				if m[1] != "" {
+					n, _ = strconv.Atoi(m[1])
+					if n == 0 || n > 53 || n < -53 {
+						return nil, fmt.Errorf("BYDAY `%s` position out of range", s)
+					}
+				}
+				r.byDay = append(r.byDay, rruleWeekday{n: n, weekday: time.Weekday(icsWeekdays[m[2]])})
+			}
+		default:
+			return nil, fmt.Errorf("`%s` unsupported", k)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if r.freq < 0 {
+		return nil, fmt.Errorf("FREQ is required")
+	}
+	if r.count > 0 && !r.until.IsZero() {
+		return nil, fmt.Errorf("COUNT and UNTIL must not be used together")
+	}
+	for _, wd := range r.byDay {
+		if wd.n != 0 && r.freq != rruleYearly && r.freq != rruleMonthly {
+			return nil, fmt.Errorf("BYDAY positions are only allowed when FREQ is YEARLY or MONTHLY")
+		}
+	}
+
+	// The missing parts are taken from DTSTART.
+	hasDayRule := len(r.byMonthDay) > 0 || len(r.byYearDay) > 0 || len(r.byDay) > 0
+	switch r.freq {
+	case rruleYearly:
+		if !hasDayRule {
+			if len(r.byMonth) == 0 {
+				r.byMonth = []int{int(dtstart.Month())}
+			}
+			r.byMonthDay = []int{dtstart.Day()}
+		}
+	case rruleMonthly:
+		if !hasDayRule {
+			r.byMonthDay = []int{dtstart.Day()}
+		}
+	case rruleWeekly:
+		if !hasDayRule {
+			r.byDay = []rruleWeekday{{weekday: dtstart.Weekday()}}
+		}
+	}
+	if len(r.byHour) == 0 && r.freq < rruleHourly {
+		r.byHour = []int{dtstart.Hour()}
+	}
+	if len(r.byMinute) == 0 && r.freq < rruleMinutely {
+		r.byMinute = []int{dtstart.Minute()}
+	}
+	if len(r.bySecond) == 0 && r.freq < rruleSecondly {
+		r.bySecond = []int{dtstart.Second()}
+	}
+
+	return r, nil
+}
+
+func civilDate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func daysBetween(a time.Time, b time.Time) int {
+	return int(civilDate(b).Sub(civilDate(a)).Hours() / 24)
+}
+
+// Whether `day` matches the day rules, `day` is a civil date in UTC.
+func (r *rrule) dayMatches(day time.Time) bool {
+	y, m, d := day.Date()
+	monthStart := time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+	daysInMonth := monthStart.AddDate(0, 1, -1).Day()
+	daysInYear := time.Date(y, 12, 31, 0, 0, 0, 0, time.UTC).YearDay()
+
+	if len(r.byMonth) > 0 && !slices.Contains(r.byMonth, int(m)) {
+		return false
+	}
+	if len(r.byMonthDay) > 0 && !slices.Contains(r.byMonthDay, d) && !slices.Contains(r.byMonthDay, d-daysInMonth-1) {
+		return false
+	}
+	yd := day.YearDay()
+	if len(r.byYearDay) > 0 && !slices.Contains(r.byYearDay, yd) && !slices.Contains(r.byYearDay, yd-daysInYear-1) {
+		return false
+	}
+	if len(r.byDay) == 0 {
+		return true
+	}
+
+	// Positions count in the month, or in the year when no month is given.
+	index, last := d, daysInMonth
+	if r.freq == rruleYearly && len(r.byMonth) == 0 {
+		index, last = yd, daysInYear
+	}
+	for _, wd := range r.byDay {
+		if wd.weekday != day.Weekday() {
+			continue
+		}
+		if wd.n == 0 || wd.n == (index-1)/7+1 || wd.n == -((last-index)/7+1) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func intsContain(vs []int, v int) bool {
+	return len(vs) == 0 || slices.Contains(vs, v)
+}
+
+// The start of the `k`th period, in `r.loc`.
+func (r *rrule) periodStart(k int) time.Time {
+	y, m, d := r.dtstart.Date()
+	n := k * r.interval
+	switch r.freq {
+	case rruleYearly:
+		return time.Date(y+n, 1, 1, 0, 0, 0, 0, r.loc)
+	case rruleMonthly:
+		return time.Date(y, m+time.Month(n), 1, 0, 0, 0, 0, r.loc)
+	case rruleWeekly:
+		offset := (int(r.dtstart.Weekday()) - int(r.wkst) + 7) % 7
+		return time.Date(y, m, d-offset+7*n, 0, 0, 0, 0, r.loc)
+	case rruleDaily:
+		return time.Date(y, m, d+n, 0, 0, 0, 0, r.loc)
+	case rruleHourly:
+		return time.Date(y, m, d, r.dtstart.Hour(), 0, 0, 0, r.loc).Add(time.Duration(n) * time.Hour)
+	case rruleMinutely:
+		return time.Date(y, m, d, r.dtstart.Hour(), r.dtstart.Minute(), 0, 0, r.loc).Add(time.Duration(n) * time.Minute)
+	default:
+		return r.dtstart.Add(time.Duration(n) * time.Second)
+	}
+}
+
+// The number of periods which can be skipped, since they end before `after`.
+func (r *rrule) skipPeriods(after time.Time) int {
+	after = after.In(r.loc)
+	var n int
+	switch r.freq {
+	case rruleYearly:
+		n = after.Year() - r.dtstart.Year()
+	case rruleMonthly:
+		n = (after.Year()-r.dtstart.Year())*12 + int(after.Month()) - int(r.dtstart.Month())
+	case rruleWeekly:
+		n = daysBetween(r.periodStart(0), after) / 7
+	case rruleDaily:
+		n = daysBetween(r.dtstart, after)
+	case rruleHourly:
+		n = int(after.Sub(r.periodStart(0)) / time.Hour)
+	case rruleMinutely:
+		n = int(after.Sub(r.periodStart(0)) / time.Minute)
+	default:
+		n = int(after.Sub(r.periodStart(0)) / time.Second)
+	}
+
+	return max(n/r.interval-1, 0)
+}
+
+// The occurrences in the `k`th period, in order.
+func (r *rrule) expand(k int) []time.Time {
+	start := r.periodStart(k)
+
+	var days []time.Time
+	switch r.freq {
+	case rruleYearly:
+		first := time.Date(start.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+		for d := first; d.Year() == start.Year(); d = d.AddDate(0, 0, 1) {
+			days = append(days, d)
+		}
+	case rruleMonthly:
+		first := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+		for d := first; d.Month() == start.Month(); d = d.AddDate(0, 0, 1) {
+			days = append(days, d)
+		}
+	case rruleWeekly:
+		first := civilDate(start)
+		for i := 0; i < 7; i++ {
+			days = append(days, first.AddDate(0, 0, i))
+		}
+	default:
+		days = []time.Time{civilDate(start)}
+	}
+
+	occurrences := make([]time.Time, 0)
+	for _, day := range days {
+		if !r.dayMatches(day) {
+			continue
+		}
+
+		hours, minutes, seconds := r.byHour, r.byMinute, r.bySecond
+		// Sub-daily periods filter the parts they cover.
+		if r.freq >= rruleHourly {
+			if !intsContain(hours, start.Hour()) {
+				continue
+			}
+			hours = []int{start.Hour()}
+		}
+		if r.freq >= rruleMinutely {
+			if !intsContain(minutes, start.Minute()) {
+				continue
+			}
+			minutes = []int{start.Minute()}
+		}
+		if r.freq >= rruleSecondly {
+			if !intsContain(seconds, start.Second()) {
+				continue
+			}
+			seconds = []int{start.Second()}
+		}
+
+		y, m, d := day.Date()
+		for _, h := range hours {
+			for _, mi := range minutes {
+				for _, s := range seconds {
+					occurrences = append(occurrences, time.Date(y, m, d, h, mi, s, 0, r.loc))
+				}
+			}
+		}
+	}
+	slices.SortFunc(occurrences, func(a, b time.Time) int { return a.Compare(b) })
+	occurrences = slices.CompactFunc(occurrences, func(a, b time.Time) bool { return a.Equal(b) })
+
+	if len(r.bySetPos) == 0 {
+		return occurrences
+	}
+	selected := make([]time.Time, 0, len(r.bySetPos))
+	for i, o := range occurrences {
+		if slices.Contains(r.bySetPos, i+1) || slices.Contains(r.bySetPos, i-len(occurrences)) {
+			selected = append(selected, o)
+		}
+	}
+
+	return selected
+}
+
+// The first occurrence after `after`, or `time.Time{}` when there is no more.
+func (r *rrule) next(after time.Time) time.Time {
+	k, count := 0, 0
+	// The occurrences before `after` are counted when `COUNT` is set.
+	if r.count == 0 {
+		k = r.skipPeriods(after)
+	}
+
+	for i := 0; i < RRULE_MAX_PERIODS; i, k = i+1, k+1 {
+		if !r.until.IsZero() && r.periodStart(k).After(r.until) {
+			return time.Time{}
+		}
+
+		for _, o := range r.expand(k) {
+			if o.Before(r.dtstart) {
+				continue
+			}
+			if !r.until.IsZero() && o.After(r.until) {
+				return time.Time{}
+			}
+			count++
+			if r.count > 0 && count > r.count {
+				return time.Time{}
+			}
+			if o.After(after) {
+				return o
+			}
+		}
+	}
+
+	return time.Time{}
+}
+
+// The `RRULE`, `RDATE` and `EXDATE` lines of RFC 5545, evaluated in a timezone.
+type rruleSet struct {
+	loc     *time.Location
+	rules   []*rrule
+	rdates  []time.Time
+	exdates []time.Time
+	// Dates of the `EXDATE` lines with `VALUE=DATE`, `2006-01-02`.
+	exDays []string
+}
+
+func (rs *rruleSet) isExcluded(t time.Time) bool {
+	if slices.Contains(rs.exDays, t.In(rs.loc).Format(time.DateOnly)) {
+		return true
+	}
+
+	return slices.ContainsFunc(rs.exdates, func(ex time.Time) bool { return ex.Equal(t) })
+}
+
+// The first occurrence after `t`, or `time.Time{}` when there is no more.
+func (rs *rruleSet) Next(t time.Time) time.Time {
+	var next time.Time
+	for _, r := range rs.rules {
+		for o := r.next(t); !o.IsZero(); o = r.next(o) {
+			if !rs.isExcluded(o) {
+				if next.IsZero() || o.Before(next) {
+					next = o
+				}
+				break
+			}
+		}
+	}
+	for _, o := range rs.rdates {
+		if o.After(t) && !rs.isExcluded(o) && (next.IsZero() || o.Before(next)) {
+			next = o
+		}
+	}
+
+	return next
+}
+
+// Parse the lines of a recurrence, separated by new lines, such as:
+//
+//	DTSTART:20240101T090000
+//	RRULE:FREQ=MONTHLY;BYDAY=2TU
+//	RDATE:20240105T090000,20240106T090000
+//	EXDATE;VALUE=DATE:20240312
+//
+// A line without a name is a `RRULE`.
+// Times without `Z` or `TZID` are in `loc`, so are the dates.
+// Default `DTSTART`: `startAt` in `loc`
+func parseRRuleSet(s string, startAt string, loc *time.Location) (*rruleSet, error) {
+	type line struct {
+		name   string
+		params map[string]string
+		value  string
+	}
+
+	lines := make([]line, 0)
+	var dtstart time.Time
+	for _, l := range strings.Split(s, "\n") {
+		l = strings.TrimSpace(l)
+		if l == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(l, ":")
+		if !ok {
+			key, value = "RRULE", l
+		}
+		parts := strings.Split(key, ";")
+		params := make(map[string]string)
+		for _, p := range parts[1:] {
+			k, v, _ := strings.Cut(p, "=")
+			params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+		name := strings.ToUpper(parts[0])
+
+		if name == "DTSTART" {
+			t, _, err := parseICSTime(params, value, loc)
+			if err != nil {
+				return nil, fmt.Errorf("DTSTART `%s` error: %s", value, err)
+			}
+			dtstart = t.In(loc)
+			continue
+		}
+		lines = append(lines, line{name: name, params: params, value: value})
+	}
+
+	if dtstart.IsZero() {
+		if startAt == "" {
+			return nil, fmt.Errorf("DTSTART or StartAt is required")
+		}
+		t, err := time.ParseInLocation(time.DateTime, startAt, loc)
+		if err != nil {
+			return nil, fmt.Errorf("StartAt `%s` error: %s", startAt, err)
+		}
+		dtstart = t
+	}
+
+	rs := &rruleSet{loc: loc}
+	for _, l := range lines {
+		switch l.name {
+		case "RRULE":
+			r, err := parseRRule(l.value, dtstart, loc)
+			if err != nil {
+				return nil, fmt.Errorf("RRULE `%s` error: %s", l.value, err)
+			}
+			rs.rules = append(rs.rules, r)
+		case "RDATE", "EXDATE":
+			for _, v := range strings.Split(l.value, ",") {
+				t, isDate, err := parseICSTime(l.params, v, loc)
+				if err != nil {
+					return nil, fmt.Errorf("%s `%s` error: %s", l.name, v, err)
+				}
+				switch {
+				case l.name == "EXDATE" && isDate:
+					rs.exDays = append(rs.exDays, t.Format(time.DateOnly))
+				case l.name == "EXDATE":
+					rs.exdates = append(rs.exdates, t)
+				case isDate:
+					// Dates run at the time of DTSTART.
+					y, m, d := t.Date()
+					rs.rdates = append(rs.rdates, time.Date(y, m, d, dtstart.Hour(), dtstart.Minute(), dtstart.Second(), 0, loc))
+				default:
+					rs.rdates = append(rs.rdates, t)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("`%s` unsupported", l.name)
+		}
+	}
+
+	if len(rs.rules) == 0 && len(rs.rdates) == 0 {
+		return nil, fmt.Errorf("RRULE or RDATE is required")
+	}
+
+	return rs, nil
+}
@@ -0,0 +1,151 @@
+package agscheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func rruleNexts(t *testing.T, s string, startAt string, loc *time.Location, from time.Time, n int) []time.Time {
+	rs, err := parseRRuleSet(s, startAt, loc)
+	assert.NoError(t, err, s)
+
+	nexts := make([]time.Time, 0, n)
+	for i := 0; i < n; i++ {
+		from = rs.Next(from)
+		if from.IsZero() {
+			break
+		}
+		nexts = append(nexts, from)
+	}
+
+	return nexts
+}
+
+func TestParseRRuleSet(t *testing.T) {
+	ny, _ := time.LoadLocation("America/New_York")
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, ny)
+	d := func(y int, m time.Month, d int, h int) time.Time {
+		return time.Date(y, m, d, h, 0, 0, 0, ny)
+	}
+
+	for _, c := range []struct {
+		s       string
+		startAt string
+		nexts   []time.Time
+	}{
+		{
+			"RRULE:FREQ=MONTHLY;BYDAY=2TU", "2024-01-01 09:00:00",
+			[]time.Time{d(2024, 1, 9, 9), d(2024, 2, 13, 9), d(2024, 3, 12, 9), d(2024, 4, 9, 9)},
+		},
+		{
+			"FREQ=MONTHLY;BYDAY=-1FR", "2024-01-01 09:00:00",
+			[]time.Time{d(2024, 1, 26, 9), d(2024, 2, 23, 9), d(2024, 3, 29, 9), d(2024, 4, 26, 9)},
+		},
+		{
+			"RRULE:FREQ=WEEKLY;INTERVAL=3;BYDAY=MO,WE;UNTIL=20240131T235959Z", "2024-01-01 10:00:00",
+			[]time.Time{d(2024, 1, 1, 10), d(2024, 1, 3, 10), d(2024, 1, 22, 10), d(2024, 1, 24, 10)},
+		},
+		{
+			"RRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1", "2024-01-01 18:00:00",
+			[]time.Time{d(2024, 1, 31, 18), d(2024, 2, 29, 18), d(2024, 3, 29, 18), d(2024, 4, 30, 18)},
+		},
+		{
+			"DTSTART:20240229T080000\nRRULE:FREQ=YEARLY", "",
+			[]time.Time{d(2024, 2, 29, 8), d(2028, 2, 29, 8), d(2032, 2, 29, 8), d(2036, 2, 29, 8)},
+		},
+		{
+			"RRULE:FREQ=DAILY;COUNT=3", "2024-01-01 07:00:00",
+			[]time.Time{d(2024, 1, 1, 7), d(2024, 1, 2, 7), d(2024, 1, 3, 7)},
+		},
+		{
+			"RRULE:FREQ=HOURLY;INTERVAL=6;BYHOUR=6,12", "2024-01-01 00:00:00",
+			[]time.Time{d(2024, 1, 1, 6), d(2024, 1, 1, 12), d(2024, 1, 2, 6), d(2024, 1, 2, 12)},
+		},
+		{
+			"RRULE:FREQ=DAILY\nEXDATE:20240102T090000\nEXDATE;VALUE=DATE:20240103\nRDATE:20240102T120000",
+			"2024-01-01 09:00:00",
+			[]time.Time{d(2024, 1, 1, 9), d(2024, 1, 2, 12), d(2024, 1, 4, 9), d(2024, 1, 5, 9)},
+		},
+		{
+			"RDATE;VALUE=DATE:20240305,20240201", "2024-01-01 09:00:00",
+			[]time.Time{d(2024, 2, 1, 9), d(2024, 3, 5, 9)},
+		},
+		{
+			"DTSTART;TZID=UTC:20240101T140000\nRRULE:FREQ=DAILY", "",
+			[]time.Time{d(2024, 1, 1, 9), d(2024, 1, 2, 9), d(2024, 1, 3, 9), d(2024, 1, 4, 9)},
+		},
+	} {
+		nexts := rruleNexts(t, c.s, c.startAt, ny, from.Add(-time.Second), 4)
+		assert.Len(t, nexts, len(c.nexts), c.s)
+		for i := range c.nexts {
+			if i < len(nexts) {
+				assert.True(t, c.nexts[i].Equal(nexts[i]), "%s: %s != %s", c.s, c.nexts[i], nexts[i])
+			}
+		}
+	}
+}
+
+func TestParseRRuleSetSkipPeriods(t *testing.T) {
+	from := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	for s, next := range map[string]time.Time{
+		"FREQ=DAILY":                         time.Date(2024, 6, 2, 9, 0, 0, 0, time.UTC),
+		"FREQ=WEEKLY;INTERVAL=2":             time.Date(2024, 6, 15, 9, 0, 0, 0, time.UTC),
+		"FREQ=MINUTELY;INTERVAL=7":           time.Date(2024, 6, 1, 12, 2, 0, 0, time.UTC),
+		"FREQ=YEARLY;BYMONTH=7;BYDAY=1MO":    time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC),
+		"FREQ=MONTHLY;BYMONTHDAY=31":         time.Date(2024, 7, 31, 9, 0, 0, 0, time.UTC),
+		"FREQ=YEARLY;BYYEARDAY=-1":           time.Date(2024, 12, 31, 9, 0, 0, 0, time.UTC),
+		"FREQ=DAILY;UNTIL=20240531":          {},
+		"FREQ=DAILY;BYMONTH=2;BYMONTHDAY=30": {},
+	} {
+		rs, err := parseRRuleSet(s, "2020-01-04 09:00:00", time.UTC)
+		assert.NoError(t, err, s)
+		assert.Equal(t, next, rs.Next(from), s)
+	}
+}
+
+func TestParseRRuleSetError(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"RRULE:INTERVAL=2",
+		"RRULE:FREQ=FORTNIGHTLY",
+		"RRULE:FREQ=DAILY;INTERVAL=0",
+		"RRULE:FREQ=DAILY;COUNT=2;UNTIL=20240101",
+		"RRULE:FREQ=WEEKLY;BYDAY=2MO",
+		"RRULE:FREQ=MONTHLY;BYMONTHDAY=32",
+		"RRULE:FREQ=YEARLY;BYWEEKNO=1",
+		"RRULE:FREQ=DAILY;BYHOUR",
+		"EXRULE:FREQ=DAILY",
+		"RDATE:2024-01-01",
+	} {
+		_, err := parseRRuleSet(s, "2024-01-01 00:00:00", time.UTC)
+		assert.Error(t, err, s)
+	}
+
+	_, err := parseRRuleSet("RRULE:FREQ=DAILY", "", time.UTC)
+	assert.Error(t, err)
+}
+
+func TestCalcNextRunTimeRRule(t *testing.T) {
+	j := Job{
+		Name: "Job", Type: TYPE_RRULE, Timezone: "Asia/Shanghai",
+		StartAt: "2024-01-01 09:00:00", RRule: "RRULE:FREQ=DAILY;COUNT=2",
+	}
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	nextRunTime, err := calcNextRunTime(j, from, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC), nextRunTime)
+
+	// No more run times after `COUNT`.
+	timezone, _ := time.LoadLocation(j.Timezone)
+	nextRunTime, err = calcNextRunTime(j, from.AddDate(0, 0, 2), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, maxRunTime(timezone), nextRunTime)
+
+	j.RRule = "RRULE:FREQ=DAILY;BYDAY=XX"
+	_, err = calcNextRunTime(j, from, nil)
+	assert.ErrorContains(t, err, "rrule")
+}
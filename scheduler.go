@@ -4,13 +4,14 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hash/fnv"
 	"io/ioutil"
 	"log"
 	"log/slog"
 	"net/http"
 	"net/smtp"
-	"reflect"
 	"runtime/debug"
 	"slices"
 	"sort"
@@ -18,9 +19,9 @@ import (
 	"sync"
 	"time"
 
-	"github.com/gorhill/cronexpr"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	pb "github.com/kurtloong/agscheduler/services/proto"
 )
@@ -28,8 +29,6 @@ import (
 var GetStore = (*Scheduler).getStore
 var GetClusterNode = (*Scheduler).getClusterNode
 
-var mutexS sync.Mutex
-
 type EmailConfig struct {
 	SMTPServer string
 	Port       int
@@ -48,18 +47,91 @@ type HTTPCallbackConfig struct {
 // In standalone mode, the scheduler only needs to run jobs on a regular basis.
 // In cluster mode, the scheduler also needs to be responsible for allocating jobs to cluster nodes.
 type Scheduler struct {
+	// Guard the state of this scheduler,
+	// so that schedulers in the same process do not block each other.
+	mu sync.Mutex
+
 	// Job store
 	store Store
+	// Registered functions of this scheduler.
+	// Default: the registry filled by `RegisterFuncs`
+	funcRegistry *FuncRegistry
 	// When the time is up, the scheduler will wake up.
 	timer *time.Timer
 	// Input is received when `stop` is called or no job in store.
 	quitChan chan struct{}
 	// It should not be set manually.
 	isRunning bool
+	// When paused, jobs are still added, updated and advanced,
+	// but no function is executed.
+	// It should not be set manually.
+	isPaused bool
+	// Set by `Shutdown`, no new runs are accepted afterwards.
+	// It should not be set manually.
+	isShutdown bool
+	// Track the running jobs, so that `Shutdown` can wait for them.
+	runWg sync.WaitGroup
+	// The parent context of all running jobs, canceled by `Shutdown`.
+	runCtx    context.Context
+	runCancel context.CancelFunc
 
 	// Used in cluster mode, bind to each other and the cluster node.
 	clusterNode *ClusterNode
 
+	// Out-of-process workers connected to this scheduler.
+	workers workerSet
+
+	// Used when the store does not implement `CalendarStore`.
+	calendars memoryCalendars
+
+	// Runs deferred to the allowed windows of their jobs.
+	deferred deferredRuns
+
+	// Counts the running runs, the runs over the limits wait in its backlog.
+	pool workerPool
+	// The number of runs executed at the same time.
+	// Default: `0`, unlimited
+	PoolSize int
+	// The most runs of a queue executed at the same time, by queue.
+	// In cluster mode, the runs of a node count against the queue of the node.
+	QueueLimits map[string]int
+	// The most runs of a function executed at the same time, by `FuncName`.
+	FuncLimits map[string]int
+	// The number of runs waiting for a free worker, before `OverflowPolicy` applies.
+	// Default: `0`, unlimited
+	BacklogSize int
+	// Optional: `OVERFLOW_BLOCK` | `OVERFLOW_DROP` | `OVERFLOW_DEFER`
+	// Default: `OVERFLOW_BLOCK`
+	OverflowPolicy string
+
+	// Used when the store does not implement `SemaphoreStore`.
+	semaphores memorySemaphores
+	// The number of runs holding a concurrency key at the same time, by key,
+	// counted across the nodes of a cluster. Keys without a limit are mutexes.
+	Semaphores map[string]int
+	// How long a run waits for its `ConcurrencyKeys` before it fails.
+	// Default: `1m`
+	SemaphoreTimeout time.Duration
+
+	// The runs delayed by the rate limits.
+	throttled throttledRuns
+	// Used when the store does not implement `TokenStore`.
+	tokens memoryTokens
+	// Token buckets limiting how often the runs of a function are dispatched, by `FuncName`.
+	// The buckets are kept in the store, so that the nodes of a cluster share them.
+	FuncRateLimits map[string]RateLimit
+	// Token buckets limiting how often the runs of a queue are dispatched, by queue.
+	// In cluster mode, the runs of a node take the tokens of the queue of the node.
+	QueueRateLimits map[string]RateLimit
+
+	// Run history of the jobs run by this scheduler.
+	records recordStore
+	// Workflow runs started by this scheduler.
+	workflows workflowStore
+	// The number of records and workflow runs kept in the run history.
+	// Default: `1000`
+	MaxRecords int
+
 	EmailConfig        *EmailConfig
 	HTTPCallbackConfig *HTTPCallbackConfig
 }
@@ -78,6 +150,94 @@ func (s *Scheduler) getStore() Store {
 	return s.store
 }
 
+// Bind the function registry, isolating this scheduler
+// from the functions registered by `RegisterFuncs`.
+func (s *Scheduler) SetFuncRegistry(r *FuncRegistry) {
+	s.funcRegistry = r
+}
+
+func (s *Scheduler) funcs() *FuncRegistry {
+	if s.funcRegistry == nil {
+		return defaultFuncRegistry
+	}
+
+	return s.funcRegistry
+}
+
+// Registered functions come first,
+// the others are run on the workers which advertised them.
+func (s *Scheduler) getFunc(name string) (registeredFunc, bool) {
+	if rf, ok := s.funcs().get(name); ok {
+		return rf, true
+	}
+
+	return s.workers.get(name)
+}
+
+// Returns the names of the registered functions and the functions of the workers.
+func (s *Scheduler) funcNames() []string {
+	names := s.funcs().Names()
+	for _, name := range s.workers.funcNames() {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// Returns the JSON Schema of the `Args` of a registered function.
+func (s *Scheduler) FuncSchema(name string) (map[string]any, error) {
+	return s.funcs().Schema(name)
+}
+
+// Returns the information of all registered functions.
+// In cluster mode, the functions registered on the other nodes are also listed,
+// along with the nodes and queues where each function is registered.
+func (s *Scheduler) ListFuncs() []FuncInfo {
+	infos := s.funcs().List()
+	for _, name := range s.workers.funcNames() {
+		if !slices.ContainsFunc(infos, func(info FuncInfo) bool { return info.Name == name }) {
+			infos = append(infos, FuncInfo{Name: name, Description: "Run by workers."})
+		}
+	}
+	if s.clusterNode == nil {
+		return infos
+	}
+
+	index := make(map[string]int)
+	for i, info := range infos {
+		index[info.Name] = i
+	}
+	for q, v := range s.clusterNode.NodeMap() {
+		for id, v2 := range v {
+			fs, _ := v2["funcs"].([]string)
+			if id == s.clusterNode.Id {
+				fs = s.funcNames()
+			}
+			for _, name := range fs {
+				i, ok := index[name]
+				if !ok {
+					infos = append(infos, FuncInfo{Name: name})
+					i = len(infos) - 1
+					index[name] = i
+				}
+				infos[i].Nodes = append(infos[i].Nodes, FuncNode{Id: id, Queue: q, Endpoint: v2["endpoint"].(string)})
+			}
+		}
+	}
+
+	slices.SortFunc(infos, func(a, b FuncInfo) int { return strings.Compare(a.Name, b.Name) })
+	for _, info := range infos {
+		slices.SortFunc(info.Nodes, func(a, b FuncNode) int {
+			return strings.Compare(a.Queue+"/"+a.Id, b.Queue+"/"+b.Id)
+		})
+	}
+
+	return infos
+}
+
 // Bind the cluster node
 func (s *Scheduler) SetClusterNode(ctx context.Context, cn *ClusterNode) error {
 	s.clusterNode = cn
@@ -95,43 +255,217 @@ func (s *Scheduler) getClusterNode() *ClusterNode {
 
 // Calculate the next run time, different job type will be calculated in different ways,
 // when the job is paused, will return `9999-09-09 09:09:09`.
-func CalcNextRunTime(j Job) (time.Time, error) {
-	timezone, err := time.LoadLocation(j.Timezone)
+// The run times excluded by `calendars` are skipped over,
+// a datetime job never runs when its `StartAt` is excluded.
+func CalcNextRunTime(j Job, calendars ...Calendar) (time.Time, error) {
+	return calcNextRunTime(j, time.Now(), calendars)
+}
+
+// Used by paused jobs and jobs which never run on their own.
+func maxRunTime(timezone *time.Location) time.Time {
+	nextRunTimeMax, _ := time.ParseInLocation(time.DateTime, "9999-09-09 09:09:09", timezone)
+	return time.Unix(nextRunTimeMax.Unix(), 0).UTC()
+}
+
+// The offset in `0 ~ jitter` added to the run time `t` of the job `id`,
+// hashed from both so that jobs sharing a schedule are spread, and previews are stable.
+func jitterOffset(id string, t time.Time, jitter time.Duration) time.Duration {
+	if jitter <= 0 {
+		return 0
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(fmt.Sprintf("%s:%d", id, t.UnixMilli())))
+
+	return time.Duration(h.Sum64() % uint64(jitter+1))
+}
+
+// The next run time after `from`, in UTC and truncated to milliseconds.
+func calcNextRunTime(j Job, from time.Time, calendars []Calendar) (time.Time, error) {
+	timezone, err := loadLocation(j.Timezone)
 	if err != nil {
-		return time.Time{}, fmt.Errorf("job `%s` Timezone `%s` error: %s", j.FullName(), j.Timezone, err)
+		return time.Time{}, &JobScheduleError{FullName: j.FullName(), Field: "timezone", Value: j.Timezone, Err: err}
 	}
 
-	if j.Status == STATUS_PAUSED {
-		nextRunTimeMax, _ := time.ParseInLocation(time.DateTime, "9999-09-09 09:09:09", timezone)
-		return time.Unix(nextRunTimeMax.Unix(), 0).UTC(), nil
+	// Jobs with upstreams only run in workflow runs.
+	if j.Status == STATUS_PAUSED || len(j.Upstreams) > 0 {
+		return maxRunTime(timezone), nil
+	}
+
+	jitter, err := j.jitter()
+	if err != nil {
+		return time.Time{}, err
 	}
 
 	var nextRunTime time.Time
+	// Returns the first run time not before `until`, the end of an exclusion.
+	var skipTo func(t time.Time, until time.Time) time.Time
 	switch strings.ToLower(j.Type) {
 	case TYPE_DATETIME:
 		nextRunTime, err = time.ParseInLocation(time.DateTime, j.StartAt, timezone)
 		if err != nil {
-			return time.Time{}, fmt.Errorf("job `%s` StartAt `%s` error: %s", j.FullName(), j.Timezone, err)
+			return time.Time{}, &JobScheduleError{FullName: j.FullName(), Field: "start_at", Value: j.StartAt, Err: err}
 		}
 	case TYPE_INTERVAL:
 		i, err := time.ParseDuration(j.Interval)
 		if err != nil {
-			return time.Time{}, fmt.Errorf("job `%s` Interval `%s` error: %s", j.FullName(), j.Interval, err)
+			return time.Time{}, &JobScheduleError{FullName: j.FullName(), Field: "interval", Value: j.Interval, Err: err}
+		}
+		if i <= 0 {
+			return time.Time{}, &JobScheduleError{FullName: j.FullName(), Field: "interval", Value: j.Interval, Err: errors.New("must be positive")}
+		}
+		// Run times are on the grid of `StartAt`, without it they follow `from`.
+		is := intervalSchedule{d: i}
+		if j.StartAt != "" {
+			if is.anchor, err = time.ParseInLocation(time.DateTime, j.StartAt, timezone); err != nil {
+				return time.Time{}, &JobScheduleError{FullName: j.FullName(), Field: "start_at", Value: j.StartAt, Err: err}
+			}
+		}
+		nextRunTime = is.Next(from.In(timezone))
+		skipTo = func(t time.Time, until time.Time) time.Time {
+			return t.Add(max((until.Sub(t)+i-1)/i, 1) * i)
 		}
-		nextRunTime = time.Now().In(timezone).Add(i)
 	case TYPE_CRON:
-		nextRunTime = cronexpr.MustParse(j.CronExpr).Next(time.Now().In(timezone))
+		expr, err := parseCronExpr(j.CronExpr, j.Id)
+		if err != nil {
+			return time.Time{}, &JobScheduleError{FullName: j.FullName(), Field: "cron_expr", Value: j.CronExpr, Err: err}
+		}
+		schedule := newDSTSchedule(expr, timezone, j.DSTGap, j.DSTOverlap)
+		nextRunTime = schedule.Next(from.In(timezone))
+		skipTo = func(t time.Time, until time.Time) time.Time {
+			return schedule.Next(until.Add(-time.Nanosecond))
+		}
+	case TYPE_RRULE:
+		rs, err := parseRRuleSet(j.RRule, j.StartAt, timezone)
+		if err != nil {
+			return time.Time{}, &JobScheduleError{FullName: j.FullName(), Field: "rrule", Value: j.RRule, Err: err}
+		}
+		nextRunTime = rs.Next(from.In(timezone))
+		skipTo = func(t time.Time, until time.Time) time.Time {
+			return rs.Next(until.Add(-time.Nanosecond))
+		}
+	case TYPE_COMBINED:
+		cs, err := j.combinedSchedule(timezone)
+		if err != nil {
+			return time.Time{}, err
+		}
+		nextRunTime = cs.Next(from.In(timezone))
+		skipTo = func(t time.Time, until time.Time) time.Time {
+			return cs.Next(until.Add(-time.Nanosecond))
+		}
 	default:
-		return time.Time{}, fmt.Errorf("job `%s` Type `%s` unknown", j.FullName(), j.Type)
+		return time.Time{}, &JobScheduleError{FullName: j.FullName(), Field: "type", Value: j.Type, Err: errors.New("unknown")}
 	}
 
-	return time.Unix(nextRunTime.Unix(), 0).UTC(), nil
+	for skips := 0; ; skips++ {
+		// Rules and expressions matching no more time never run again.
+		if nextRunTime.IsZero() {
+			return maxRunTime(timezone), nil
+		}
+		runTime := nextRunTime.Add(jitterOffset(j.Id, nextRunTime, jitter))
+		until, ok := calendarsExcludedUntil(runTime, timezone, calendars)
+		if !ok {
+			nextRunTime = runTime
+			break
+		}
+		if skipTo == nil {
+			return maxRunTime(timezone), nil
+		}
+		if skips >= CALENDAR_MAX_SKIPS {
+			return time.Time{}, &JobScheduleError{
+				FullName: j.FullName(), Field: "calendars", Value: strings.Join(j.Calendars, ","),
+				Err: fmt.Errorf("no run time out of the calendars after %d skips", skips),
+			}
+		}
+		nextRunTime = skipTo(nextRunTime, until)
+	}
+
+	return time.UnixMilli(nextRunTime.UnixMilli()).UTC(), nil
+}
+
+// The most run times returned by `PreviewRunTimes`.
+const PREVIEW_MAX_RUN_TIMES = 1000
+
+// Returns the next `n` run times of the job after `from`, in the timezone of the job,
+// the job is not stored.
+// Datetime jobs run at most once, paused jobs and jobs with upstreams never run on their own.
+//
+// `n`: 1 ~ `PREVIEW_MAX_RUN_TIMES`
+// Default `from`: now
+func (s *Scheduler) PreviewRunTimes(j Job, n int, from time.Time) ([]time.Time, error) {
+	if n <= 0 || n > PREVIEW_MAX_RUN_TIMES {
+		return nil, fmt.Errorf("preview count `%d` must be between 1 and %d", n, PREVIEW_MAX_RUN_TIMES)
+	}
+	if j.Timezone == "" {
+		j.Timezone = "UTC"
+	}
+	if from.IsZero() {
+		from = time.Now()
+	}
+
+	timezone, err := loadLocation(j.Timezone)
+	if err != nil {
+		return nil, &JobScheduleError{FullName: j.FullName(), Field: "timezone", Value: j.Timezone, Err: err}
+	}
+
+	calendars, err := s.getCalendars(j.Calendars)
+	if err != nil {
+		return nil, &JobScheduleError{FullName: j.FullName(), Field: "calendars", Value: strings.Join(j.Calendars, ","), Err: err}
+	}
+
+	runTimes := make([]time.Time, 0, n)
+	if j.Status == STATUS_PAUSED || len(j.Upstreams) > 0 {
+		return runTimes, nil
+	}
+
+	for len(runTimes) < n {
+		nextRunTime, err := calcNextRunTime(j, from, calendars)
+		if err != nil {
+			return nil, err
+		}
+		// Past or excluded datetime jobs, or cron expressions matching no more time.
+		if nextRunTime.Before(from.Truncate(time.Millisecond)) || nextRunTime.Equal(maxRunTime(timezone)) {
+			break
+		}
+		runTimes = append(runTimes, nextRunTime.In(timezone))
+
+		if strings.ToLower(j.Type) == TYPE_DATETIME {
+			break
+		}
+		from = nextRunTime
+	}
+
+	return runTimes, nil
+}
+
+// Used to gRPC Protobuf
+func RunTimesToPbRunTimesPtr(ts []time.Time, timezone string) *pb.RunTimes {
+	pbRunTimes := &pb.RunTimes{Timezone: timezone}
+	for _, t := range ts {
+		pbRunTimes.RunTimes = append(pbRunTimes.RunTimes, &pb.RunTime{
+			Utc:   timestamppb.New(t),
+			Local: t.Format(time.RFC3339Nano),
+		})
+	}
+
+	return pbRunTimes
 }
 
 func (s *Scheduler) AddJob(j Job) (Job, error) {
-	if err := j.init(); err != nil {
+	if err := j.init(s.getFunc); err != nil {
+		return Job{}, err
+	}
+	if err := s.checkUpstreams(j); err != nil {
+		return Job{}, err
+	}
+	j.clearRunFields()
+
+	// Calculated again with the calendars in the store.
+	nextRunTime, err := s.nextRunTime(j)
+	if err != nil {
 		return Job{}, err
 	}
+	j.NextRunTime = nextRunTime
 
 	slog.Info(fmt.Sprintf("Scheduler add job `%s`.\n", j.FullName()))
 
@@ -155,15 +489,26 @@ func (s *Scheduler) GetAllJobs() ([]Job, error) {
 }
 
 func (s *Scheduler) UpdateJob(j Job) (Job, error) {
-	if _, err := s.GetJob(j.Id); err != nil {
+	oJ, err := s.GetJob(j.Id)
+	if err != nil {
 		return Job{}, err
 	}
 
-	if err := j.check(); err != nil {
+	// Keep the grid of interval jobs when `StartAt` is not given.
+	if strings.ToLower(j.Type) == TYPE_INTERVAL && j.StartAt == "" && strings.ToLower(oJ.Type) == TYPE_INTERVAL {
+		j.StartAt = oJ.StartAt
+	}
+	j.anchorInterval()
+
+	if err := j.check(s.getFunc); err != nil {
+		return Job{}, err
+	}
+	if err := s.checkUpstreams(j); err != nil {
 		return Job{}, err
 	}
+	j.clearRunFields()
 
-	nextRunTime, err := CalcNextRunTime(j)
+	nextRunTime, err := s.nextRunTime(j)
 	if err != nil {
 		return Job{}, err
 	}
@@ -187,16 +532,30 @@ func (s *Scheduler) DeleteJob(id string) error {
 	slog.Info(fmt.Sprintf("Scheduler delete jobId `%s`.\n", id))
 
 	if _, err := s.GetJob(id); err != nil {
+		// A one-off job is removed once due, its run deferred to an allowed window is still canceled.
+		if s.deferred.remove(id) {
+			return nil
+		}
 		return err
 	}
 
-	return s.store.DeleteJob(id)
+	if err := s.store.DeleteJob(id); err != nil {
+		return err
+	}
+	s.deferred.remove(id)
+
+	return nil
 }
 
 func (s *Scheduler) DeleteAllJobs() error {
 	slog.Info("Scheduler delete all jobs.\n")
 
-	return s.store.DeleteAllJobs()
+	if err := s.store.DeleteAllJobs(); err != nil {
+		return err
+	}
+	s.deferred.stop()
+
+	return nil
 }
 
 func (s *Scheduler) PauseJob(id string) (Job, error) {
@@ -235,58 +594,243 @@ func (s *Scheduler) ResumeJob(id string) (Job, error) {
 	return j, nil
 }
 
+// Returns the run history of a job, latest first,
+// the history of all jobs is returned when `id` is empty.
+// In cluster mode, only the runs on this node are recorded.
+func (s *Scheduler) GetRecords(id string) []Record {
+	return s.records.get(id)
+}
+
+// Apply `op` to all jobs matching the selector,
+// an error of one job does not stop the others.
+// `ErrEmptyJobSelector` is returned if the selector is empty without `All`.
+func (s *Scheduler) _bulkJobs(sel JobSelector, op func(j Job) error) ([]BulkResult, error) {
+	if sel.isEmpty() && !sel.All {
+		return nil, ErrEmptyJobSelector
+	}
+
+	js, err := s.GetAllJobs()
+	if err != nil {
+		return nil, err
+	}
+
+	rs := make([]BulkResult, 0)
+	for _, j := range js {
+		if !sel.Match(j) {
+			continue
+		}
+
+		r := BulkResult{Id: j.Id, Name: j.Name}
+		if err := op(j); err != nil {
+			r.Error = err.Error()
+		}
+		rs = append(rs, r)
+	}
+
+	return rs, nil
+}
+
+func (s *Scheduler) PauseJobs(sel JobSelector) ([]BulkResult, error) {
+	slog.Info(fmt.Sprintf("Scheduler pause jobs `%+v`.\n", sel))
+
+	return s._bulkJobs(sel, func(j Job) error {
+		_, err := s.PauseJob(j.Id)
+		return err
+	})
+}
+
+func (s *Scheduler) ResumeJobs(sel JobSelector) ([]BulkResult, error) {
+	slog.Info(fmt.Sprintf("Scheduler resume jobs `%+v`.\n", sel))
+
+	return s._bulkJobs(sel, func(j Job) error {
+		_, err := s.ResumeJob(j.Id)
+		return err
+	})
+}
+
+func (s *Scheduler) DeleteJobs(sel JobSelector) ([]BulkResult, error) {
+	slog.Info(fmt.Sprintf("Scheduler delete jobs `%+v`.\n", sel))
+
+	return s._bulkJobs(sel, func(j Job) error {
+		return s.DeleteJob(j.Id)
+	})
+}
+
+// Run all matching jobs immediately without changing their next run time,
+// in cluster mode, each job is scheduled to a node.
+func (s *Scheduler) RunJobsNow(sel JobSelector) ([]BulkResult, error) {
+	slog.Info(fmt.Sprintf("Scheduler run jobs now `%+v`.\n", sel))
+
+	return s._bulkJobs(sel, s.ScheduleJob)
+}
+
+// Returns the parent context of running jobs, created on first use.
+func (s *Scheduler) jobContext() (context.Context, bool) {
+	defer s.mu.Unlock()
+
+	s.mu.Lock()
+
+	if s.isShutdown {
+		return nil, false
+	}
+	if s.runCtx == nil {
+		s.runCtx, s.runCancel = context.WithCancel(context.Background())
+	}
+	s.runWg.Add(1)
+
+	return s.runCtx, true
+}
+
+// Finish the record of a run, and report it for the workflow run and chained jobs.
+func (s *Scheduler) finishRun(j Job, recordId string, status string, result map[string]any, err error) {
+	if s.records.finish(recordId, status, result, err) {
+		s.jobFinished(j, status, result)
+	}
+}
+
 // Used in standalone mode.
 func (s *Scheduler) _runJob(j Job) {
-	f := reflect.ValueOf(funcMap[j.FuncName])
-	if f.IsNil() {
+	rf, ok := s.getFunc(j.FuncName)
+	if !ok {
 		slog.Warn(fmt.Sprintf("Job `%s` Func `%s` unregistered\n", j.FullName(), j.FuncName))
-	} else {
-		slog.Info(fmt.Sprintf("Job `%s` is running, next run time: `%s`\n", j.FullName(), j.NextRunTimeWithTimezone().String()))
-		go func() {
-			timeout, err := time.ParseDuration(j.Timeout)
-			if err != nil {
-				e := &JobTimeoutError{FullName: j.FullName(), Timeout: j.Timeout, Err: err}
-				slog.Error(e.Error())
-				s.sendEmail(j, e.Error())    // 发送邮件
-				s.httpCallback(j, e.Error()) // HTTP 回调
-				return
+		s.jobFinished(j, RECORD_FAILED, nil)
+		return
+	}
+
+	parentCtx, ok := s.jobContext()
+	if !ok {
+		slog.Warn(fmt.Sprintf("Scheduler is shut down, job `%s` run rejected\n", j.FullName()))
+		s.jobFinished(j, RECORD_CANCELED, nil)
+		return
+	}
+
+	delay, names, giveBack := s.reserveTokens(j)
+	submit := func() {
+		s.startRun(parentCtx, rf, j, delay, func(status string) {
+			giveBack()
+			s.runWg.Done()
+			s.jobFinished(j, status, nil)
+		})
+	}
+	if delay == 0 {
+		submit()
+		return
+	}
+
+	slog.Info(fmt.Sprintf("Job `%s` is throttled by the rate limits `%s`, delayed %s.\n", j.FullName(), names, delay))
+	done := s.throttled.add(names, delay)
+	go func() {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-done:
+		}
+		s.throttled.finish(names)
+		submit()
+	}()
+}
+
+// Called once the run is no longer delayed by the rate limits.
+// The run takes its concurrency keys before a worker of the pool,
+// so that a blocked run does not hold up the pool, then it executes once the pool has a free worker.
+// `cancel` is called when the run never starts.
+func (s *Scheduler) startRun(parentCtx context.Context, rf registeredFunc, j Job, throttleDelay time.Duration, cancel func(status string)) {
+	if len(j.ConcurrencyKeys) == 0 {
+		s.submitRun(j, func() { s.executeRun(parentCtx, rf, j, throttleDelay, func() {}) }, cancel)
+		return
+	}
+
+	go func() {
+		release, err := s.acquireConcurrencyKeys(parentCtx, j)
+		if err != nil {
+			status := RECORD_FAILED
+			if errors.Is(err, context.Canceled) {
+				status = RECORD_CANCELED
 			}
+			slog.Error(fmt.Sprintf("Job `%s` run error: %s\n", j.FullName(), err))
+			recordId := s.records.start(j, s.MaxRecords)
+			s.finishRun(j, recordId, status, nil, err)
+			s.sendEmail(j, err.Error())    // 发送邮件
+			s.httpCallback(j, err.Error()) // HTTP 回调
+			s.runWg.Done()
+			return
+		}
+		s.submitRun(j, func() { s.executeRun(parentCtx, rf, j, throttleDelay, release) }, func(status string) {
+			release()
+			cancel(status)
+		})
+	}()
+}
 
-			ctx, cancel := context.WithTimeout(context.Background(), timeout)
-			defer cancel()
-
-			ch := make(chan error, 1)
-			go func() {
-				defer close(ch)
-				defer func() {
-					if err := recover(); err != nil {
-						errMsg := fmt.Sprintf("Job `%s` run error: %s\n", j.FullName(), err)
-						slog.Error(errMsg)
-						slog.Debug(fmt.Sprintf("%s\n", string(debug.Stack())))
-						s.sendEmail(j, errMsg)    // 发送邮件
-						s.httpCallback(j, errMsg) // HTTP 回调
-					}
-				}()
-
-				results := f.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(j)})
-				if len(results) > 0 && !results[0].IsNil() {
-					err := results[0].Interface().(error)
-					slog.Error(err.Error())
-					s.sendEmail(j, err.Error())    // 发送邮件
-					s.httpCallback(j, err.Error()) // HTTP 回调
+// `release` frees the concurrency keys held by the run when it finishes.
+func (s *Scheduler) executeRun(parentCtx context.Context, rf registeredFunc, j Job, throttleDelay time.Duration, release func()) {
+	slog.Info(fmt.Sprintf("Job `%s` is running, next run time: `%s`\n", j.FullName(), j.NextRunTimeWithTimezone().String()))
+	recordId := s.records.start(j, s.MaxRecords)
+	if throttleDelay > 0 {
+		s.records.throttled(recordId, throttleDelay)
+	}
+	go func() {
+		defer s.runWg.Done()
+		defer s.releaseRun(j)
+		defer release()
+
+		timeout, err := time.ParseDuration(j.Timeout)
+		if err != nil {
+			e := &JobTimeoutError{FullName: j.FullName(), Timeout: j.Timeout, Err: err}
+			slog.Error(e.Error())
+			s.finishRun(j, recordId, RECORD_FAILED, nil, e)
+			s.sendEmail(j, e.Error())    // 发送邮件
+			s.httpCallback(j, e.Error()) // HTTP 回调
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(parentCtx, timeout)
+		defer cancel()
+
+		ch := make(chan error, 1)
+		go func() {
+			defer close(ch)
+			defer func() {
+				if err := recover(); err != nil {
+					errMsg := fmt.Sprintf("Job `%s` run error: %s\n", j.FullName(), err)
+					slog.Error(errMsg)
+					slog.Debug(fmt.Sprintf("%s\n", string(debug.Stack())))
+					s.finishRun(j, recordId, RECORD_FAILED, nil, fmt.Errorf("%s", err))
+					s.sendEmail(j, errMsg)    // 发送邮件
+					s.httpCallback(j, errMsg) // HTTP 回调
 				}
 			}()
 
-			select {
-			case <-ch:
+			result, err := rf.run(ctx, j)
+			if err != nil {
+				slog.Error(err.Error())
+				s.finishRun(j, recordId, RECORD_FAILED, result, err)
+				s.sendEmail(j, err.Error())    // 发送邮件
+				s.httpCallback(j, err.Error()) // HTTP 回调
 				return
-			case <-ctx.Done():
-				slog.Warn(fmt.Sprintf("Job `%s` run timeout\n", j.FullName()))
-				s.sendEmail(j, "Job run timeout")    // 发送邮件
-				s.httpCallback(j, "Job run timeout") // HTTP 回调
 			}
+			s.finishRun(j, recordId, RECORD_SUCCEEDED, result, nil)
 		}()
-	}
+
+		select {
+		case <-ch:
+			return
+		case <-ctx.Done():
+			if ctx.Err() == context.Canceled {
+				slog.Warn(fmt.Sprintf("Job `%s` run canceled by shutdown\n", j.FullName()))
+				s.finishRun(j, recordId, RECORD_CANCELED, nil, ctx.Err())
+				s.sendEmail(j, "Job run canceled")    // 发送邮件
+				s.httpCallback(j, "Job run canceled") // HTTP 回调
+				return
+			}
+			slog.Warn(fmt.Sprintf("Job `%s` run timeout\n", j.FullName()))
+			s.finishRun(j, recordId, RECORD_TIMEOUT, nil, ctx.Err())
+			s.sendEmail(j, "Job run timeout")    // 发送邮件
+			s.httpCallback(j, "Job run timeout") // HTTP 回调
+		}
+	}()
 }
 
 // Used in cluster mode.
@@ -306,21 +850,26 @@ func (s *Scheduler) _runJobRemote(node *ClusterNode, j Job) {
 		_, err := client.RunJob(ctx, pbJ)
 		if err != nil {
 			slog.Error(fmt.Sprintf("Scheduler run job `%s` remote error %s\n", j.FullName(), err))
+			s.jobFinished(j, RECORD_FAILED, nil)
 		}
 	}()
 }
 
 func (s *Scheduler) _flushJob(j Job, now time.Time) error {
-	j.LastRunTime = time.Unix(now.Unix(), 0).UTC()
+	j.LastRunTime = time.UnixMilli(now.UnixMilli()).UTC()
 
 	if j.Type == TYPE_DATETIME {
+		// The store is used directly, a run deferred to an allowed window is kept.
 		if j.NextRunTime.Before(now) {
-			if err := s.DeleteJob(j.Id); err != nil {
+			if err := s.store.DeleteJob(j.Id); err != nil {
 				return fmt.Errorf("delete job `%s` error: %s", j.FullName(), err)
 			}
 		}
 	} else {
-		if _, err := s.UpdateJob(j); err != nil {
+		// The advanced next run time is stored without checking the job again,
+		// so a job whose func is unregistered or whose args no longer validate is not dispatched at every wakeup.
+		j.clearRunFields()
+		if err := s.store.UpdateJob(j); err != nil {
 			return fmt.Errorf("update job `%s` error: %s", j.FullName(), err)
 		}
 	}
@@ -328,7 +877,13 @@ func (s *Scheduler) _flushJob(j Job, now time.Time) error {
 	return nil
 }
 
+// The allowed windows of all scheduled runs are checked here, local or remote,
+// whether the run is due, deferred, chained or in a workflow.
 func (s *Scheduler) _scheduleJob(j Job) error {
+	if !s.checkAllowedWindows(j, time.Now()) {
+		return nil
+	}
+
 	isRunJobLocal := false
 
 	// In standalone mode.
@@ -336,7 +891,7 @@ func (s *Scheduler) _scheduleJob(j Job) error {
 		isRunJobLocal = true
 	} else {
 		// In cluster mode, all nodes are equal and may pick myself.
-		node, err := s.clusterNode.choiceNode(j.Queues)
+		node, err := s.clusterNode.choiceNode(j.Queues, j.FuncName)
 		if err != nil || s.clusterNode.Id == node.Id {
 			isRunJobLocal = true
 		} else {
@@ -346,7 +901,7 @@ func (s *Scheduler) _scheduleJob(j Job) error {
 	}
 
 	if isRunJobLocal {
-		if len(j.Queues) == 0 || slices.Contains(j.Queues, s.clusterNode.Queue) {
+		if len(j.Queues) == 0 || s.clusterNode == nil || slices.Contains(j.Queues, s.clusterNode.Queue) {
 			s._runJob(j)
 		} else {
 			return fmt.Errorf("cluster node with queue `%s` does not exist", j.Queues)
@@ -359,7 +914,16 @@ func (s *Scheduler) _scheduleJob(j Job) error {
 func (s *Scheduler) RunJob(j Job) error {
 	slog.Info(fmt.Sprintf("Scheduler run job `%s`.\n", j.FullName()))
 
-	s._runJob(j)
+	if s.IsPaused() {
+		return ErrSchedulerPaused
+	}
+
+	js, err := s.GetAllJobs()
+	if err != nil {
+		return err
+	}
+
+	s._runJob(s.startWorkflow(j, js))
 
 	return nil
 }
@@ -369,7 +933,16 @@ func (s *Scheduler) RunJob(j Job) error {
 func (s *Scheduler) ScheduleJob(j Job) error {
 	slog.Info(fmt.Sprintf("Scheduler schedule job `%s`.\n", j.FullName()))
 
-	err := s._scheduleJob(j)
+	if s.IsPaused() {
+		return ErrSchedulerPaused
+	}
+
+	js, err := s.GetAllJobs()
+	if err != nil {
+		return err
+	}
+
+	err = s.scheduleWorkflow(j, js)
 	if err != nil {
 		return fmt.Errorf("scheduler schedule job `%s` error: %s", j.FullName(), err)
 	}
@@ -399,29 +972,40 @@ func (s *Scheduler) run() {
 				continue
 			}
 
-			// If there are ineligible job, subsequent job do not need to be checked.
+			isPaused := s.IsPaused()
+
+			// The jobs due at this wakeup are dispatched by priority,
+			// the jobs after them do not need to be checked.
 			sort.Sort(JobSlice(js))
-			for _, j := range js {
-				if j.NextRunTime.Before(now) {
-					nextRunTime, err := CalcNextRunTime(j)
-					if err != nil {
-						slog.Error(fmt.Sprintf("Scheduler calc next run time error: %s\n", err))
-						continue
-					}
-					j.NextRunTime = nextRunTime
+			due := sort.Search(len(js), func(i int) bool { return !js[i].NextRunTime.Before(now) })
+			sort.Stable(JobPrioritySlice(js[:due]))
+			for _, j := range js[:due] {
+				// One-off jobs are deferred until the scheduler resumes,
+				// other jobs keep advancing and their runs are skipped.
+				if isPaused && j.Type == TYPE_DATETIME {
+					continue
+				}
+
+				nextRunTime, err := s.nextRunTime(j)
+				if err != nil {
+					slog.Error(fmt.Sprintf("Scheduler calc next run time error: %s\n", err))
+					continue
+				}
+				j.NextRunTime = nextRunTime
 
-					err = s._scheduleJob(j)
+				if isPaused {
+					slog.Info(fmt.Sprintf("Scheduler is paused, job `%s` run skipped.\n", j.FullName()))
+				} else {
+					err = s.scheduleWorkflow(j, js)
 					if err != nil {
 						slog.Error(fmt.Sprintf("Scheduler schedule job `%s` error: %s\n", j.FullName(), err))
 					}
+				}
 
-					err = s._flushJob(j, now)
-					if err != nil {
-						slog.Error(fmt.Sprintf("Scheduler %s\n", err))
-						continue
-					}
-				} else {
-					break
+				err = s._flushJob(j, now)
+				if err != nil {
+					slog.Error(fmt.Sprintf("Scheduler %s\n", err))
+					continue
 				}
 			}
 
@@ -436,9 +1020,14 @@ func (s *Scheduler) run() {
 // In addition to being called manually,
 // it is also called after `AddJob`.
 func (s *Scheduler) Start() {
-	defer mutexS.Unlock()
+	defer s.mu.Unlock()
+
+	s.mu.Lock()
 
-	mutexS.Lock()
+	if s.isShutdown {
+		slog.Info("Scheduler has shut down.\n")
+		return
+	}
 
 	if s.isRunning {
 		slog.Info("Scheduler is running.\n")
@@ -457,9 +1046,9 @@ func (s *Scheduler) Start() {
 // In addition to being called manually,
 // there is no job in store that will also be called.
 func (s *Scheduler) Stop() {
-	defer mutexS.Unlock()
+	defer s.mu.Unlock()
 
-	mutexS.Lock()
+	s.mu.Lock()
 
 	if !s.isRunning {
 		slog.Info("Scheduler has stopped.\n")
@@ -472,6 +1061,90 @@ func (s *Scheduler) Stop() {
 	slog.Info("Scheduler stop.\n")
 }
 
+// Stop the scheduler gracefully, no new runs are accepted
+// and the running jobs are waited for until `ctx` is done,
+// then the rest of them are canceled through their contexts.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	slog.Info("Scheduler shutdown.\n")
+
+	s.Stop()
+
+	s.mu.Lock()
+	s.isShutdown = true
+	s.mu.Unlock()
+
+	s.deferred.stop()
+	s.closePool()
+	s.throttled.stop()
+
+	done := make(chan struct{})
+	go func() {
+		s.runWg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		if s.runCancel != nil {
+			s.runCancel()
+		}
+		s.mu.Unlock()
+
+		return fmt.Errorf("scheduler shutdown, running jobs canceled: %s", ctx.Err())
+	}
+}
+
+// Pause the whole scheduler (maintenance mode).
+// Jobs can still be added and updated and keep advancing on time,
+// but no function is executed until `Resume` is called.
+func (s *Scheduler) Pause() {
+	defer s.mu.Unlock()
+
+	s.mu.Lock()
+
+	if s.isPaused {
+		slog.Info("Scheduler is paused.\n")
+		return
+	}
+
+	s.isPaused = true
+
+	slog.Info("Scheduler pause.\n")
+}
+
+// Resume the scheduler, one-off jobs deferred during the pause run immediately.
+func (s *Scheduler) Resume() {
+	s.mu.Lock()
+
+	if !s.isPaused {
+		s.mu.Unlock()
+		slog.Info("Scheduler is not paused.\n")
+		return
+	}
+
+	s.isPaused = false
+	isRunning := s.isRunning
+
+	s.mu.Unlock()
+
+	slog.Info("Scheduler resume.\n")
+
+	if isRunning {
+		s.wakeup()
+	}
+}
+
+func (s *Scheduler) IsPaused() bool {
+	defer s.mu.Unlock()
+
+	s.mu.Lock()
+
+	return s.isPaused
+}
+
 // Dynamically calculate the next wakeup interval, avoid frequent wakeup of the scheduler
 func (s *Scheduler) getNextWakeupInterval() time.Duration {
 	nextRunTimeMin, err := s.store.GetNextRunTime()
@@ -482,8 +1155,15 @@ func (s *Scheduler) getNextWakeupInterval() time.Duration {
 
 	now := time.Now().UTC()
 	nextWakeupInterval := nextRunTimeMin.Sub(now)
+	// Overdue jobs which failed to be updated are retried shortly, without spinning.
+	// While paused, the overdue one-off jobs stay due until `Resume` wakes up the scheduler,
+	// so the store is not polled at the short interval.
 	if nextWakeupInterval < 0 {
-		nextWakeupInterval = time.Second
+		if s.IsPaused() {
+			nextWakeupInterval = time.Second
+		} else {
+			nextWakeupInterval = 10 * time.Millisecond
+		}
 	}
 
 	return nextWakeupInterval
@@ -2,6 +2,10 @@ package agscheduler_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -16,6 +20,18 @@ func dryRunScheduler(ctx context.Context, j agscheduler.Job) {}
 
 func runSchedulerPanic(ctx context.Context, j agscheduler.Job) { panic(nil) }
 
+var runSchedulerSleepDone = make(chan struct{}, 1)
+
+func runSchedulerSleep(ctx context.Context, j agscheduler.Job) {
+	select {
+	case <-time.After(200 * time.Millisecond):
+	case <-ctx.Done():
+	}
+	runSchedulerSleepDone <- struct{}{}
+}
+
+func runSchedulerWait(ctx context.Context, j agscheduler.Job) { <-ctx.Done() }
+
 func getSchedulerWithStore() *agscheduler.Scheduler {
 	store := &stores.MemoryStore{}
 	scheduler := &agscheduler.Scheduler{}
@@ -25,7 +41,7 @@ func getSchedulerWithStore() *agscheduler.Scheduler {
 }
 
 func getJob() agscheduler.Job {
-	agscheduler.RegisterFuncs(dryRunScheduler, runSchedulerPanic)
+	agscheduler.RegisterFuncs(dryRunScheduler, runSchedulerPanic, runSchedulerSleep, runSchedulerWait)
 
 	return agscheduler.Job{
 		Name:     "Job",
@@ -79,6 +95,21 @@ func TestSchedulerSetClusterNode(t *testing.T) {
 	assert.NotNil(t, agscheduler.GetClusterNode(s))
 }
 
+func TestSchedulerSetFuncRegistry(t *testing.T) {
+	s := getSchedulerWithStore()
+	defer s.Stop()
+	r := agscheduler.NewFuncRegistry()
+	s.SetFuncRegistry(r)
+
+	_, err := s.AddJob(getJob())
+	assert.ErrorAs(t, err, new(agscheduler.FuncUnregisteredError))
+
+	r.Register(dryRunScheduler)
+
+	_, err = s.AddJob(getJob())
+	assert.NoError(t, err)
+}
+
 func TestSchedulerAddJob(t *testing.T) {
 	s := getSchedulerWithStore()
 	defer s.Stop()
@@ -96,39 +127,1041 @@ func TestSchedulerAddJob(t *testing.T) {
 	time.Sleep(500 * time.Millisecond)
 }
 
-func TestSchedulerAddJobDatetime(t *testing.T) {
-	s := getSchedulerWithStore()
+func TestSchedulerAddJobDatetime(t *testing.T) {
+	s := getSchedulerWithStore()
+	defer s.Stop()
+	j := getJob()
+	j.Type = agscheduler.TYPE_DATETIME
+	j.StartAt = "2023-09-22 07:30:08"
+
+	j, err := s.AddJob(j)
+	assert.NoError(t, err)
+
+	time.Sleep(50 * time.Millisecond)
+
+	_, err = s.GetJob(j.Id)
+	assert.ErrorIs(t, err, agscheduler.JobNotFoundError(j.Id))
+}
+
+func TestSchedulerAddJobUnregisteredError(t *testing.T) {
+	s := getSchedulerWithStore()
+	defer s.Stop()
+	j := getJobWithoutFunc()
+
+	_, err := s.AddJob(j)
+	assert.ErrorIs(t, err, agscheduler.FuncUnregisteredError(""))
+}
+
+func TestSchedulerAddJobTimeoutError(t *testing.T) {
+	s := getSchedulerWithStore()
+	defer s.Stop()
+	j := getJob()
+	j.Timeout = "errorTimeout"
+
+	_, err := s.AddJob(j)
+	assert.Contains(t, err.Error(), "Timeout `"+j.Timeout+"` error")
+}
+
+type typedArgsScheduler struct {
+	Times int `json:"times"`
+}
+
+func TestSchedulerAddJobArgsError(t *testing.T) {
+	s := getSchedulerWithStore()
+	defer s.Stop()
+	r := agscheduler.NewFuncRegistry()
+	ch := make(chan int, 1)
+	agscheduler.RegisterTypedTo(r, "typed", func(ctx context.Context, j agscheduler.Job, args typedArgsScheduler) error {
+		select {
+		case ch <- args.Times:
+		default:
+		}
+		return nil
+	})
+	s.SetFuncRegistry(r)
+	j := getJobWithoutFunc()
+	j.FuncName = "typed"
+	j.Args = map[string]any{"times": "1"}
+
+	_, err := s.AddJob(j)
+	argsErr := &agscheduler.JobArgsError{}
+	assert.ErrorAs(t, err, &argsErr)
+	assert.Equal(t, "times", argsErr.Fields[0].Field)
+
+	j.Args = map[string]any{"times": float64(2)}
+	j, err = s.AddJob(j)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, <-ch)
+
+	j.Args = map[string]any{}
+	_, err = s.UpdateJob(j)
+	assert.ErrorAs(t, err, &argsErr)
+}
+
+func TestSchedulerAddJobAlias(t *testing.T) {
+	s := getSchedulerWithStore()
+	defer s.Stop()
+	r := agscheduler.NewFuncRegistry()
+	err := r.RegisterWithInfo(dryRunScheduler, agscheduler.FuncInfo{Aliases: []string{"dry"}})
+	assert.NoError(t, err)
+	s.SetFuncRegistry(r)
+	j := getJobWithoutFunc()
+	j.FuncName = "dry"
+
+	j, err = s.AddJob(j)
+	assert.NoError(t, err)
+	assert.Equal(t, r.Names()[0], j.FuncName)
+}
+
+func TestSchedulerListFuncs(t *testing.T) {
+	s := getSchedulerWithStore()
+	r := agscheduler.NewFuncRegistry()
+	r.Register(dryRunScheduler)
+	s.SetFuncRegistry(r)
+
+	infos := s.ListFuncs()
+
+	assert.Len(t, infos, 1)
+	assert.Equal(t, r.Names()[0], infos[0].Name)
+	assert.Empty(t, infos[0].Nodes)
+}
+
+func TestSchedulerGetRecords(t *testing.T) {
+	s := getSchedulerWithStore()
+	r := agscheduler.NewFuncRegistry()
+	r.Register(dryRunScheduler, runSchedulerPanic)
+	agscheduler.RegisterTypedTo(r, "typed", func(ctx context.Context, j agscheduler.Job, args typedArgsScheduler) error {
+		return errors.New("typed error")
+	})
+	s.SetFuncRegistry(r)
+	j := getJob()
+	j.Id = "1"
+	j.Timeout = "1s"
+	j.FuncName = r.Names()[0]
+	j2 := j
+	j2.Id = "2"
+	j2.FuncName = "typed"
+	j2.Args = map[string]any{"times": 1}
+	j3 := j
+	j3.Id = "3"
+	j3.FuncName = r.Names()[1]
+
+	for _, j := range []agscheduler.Job{j, j2, j3} {
+		err := s.RunJob(j)
+		assert.NoError(t, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err := s.Shutdown(ctx)
+	assert.NoError(t, err)
+
+	assert.Len(t, s.GetRecords(""), 3)
+	records := s.GetRecords("1")
+	assert.Len(t, records, 1)
+	assert.Equal(t, agscheduler.RECORD_SUCCEEDED, records[0].Status)
+	records = s.GetRecords("2")
+	assert.Equal(t, agscheduler.RECORD_FAILED, records[0].Status)
+	assert.Equal(t, "typed error", records[0].Error)
+	records = s.GetRecords("3")
+	assert.Equal(t, agscheduler.RECORD_FAILED, records[0].Status)
+}
+
+func TestSchedulerWorker(t *testing.T) {
+	s := getSchedulerWithStore()
+	defer s.Stop()
+	s.SetFuncRegistry(agscheduler.NewFuncRegistry())
+	j := getJob()
+	j.Interval = "1h"
+	j.Timeout = "1s"
+	j.FuncName = "worker.func"
+
+	_, err := s.AddJob(j)
+	assert.ErrorAs(t, err, new(agscheduler.FuncUnregisteredError))
+
+	w := s.ConnectWorker("w1", []string{"worker.func"})
+	j, err = s.AddJob(j)
+	assert.NoError(t, err)
+	assert.Contains(t, s.ListFuncs(), agscheduler.FuncInfo{Name: "worker.func", Description: "Run by workers."})
+
+	go func() {
+		run := <-w.Runs()
+		w.Report(agscheduler.WorkerResult{RunId: run.Id, Result: map[string]any{"job_id": run.Job.Id}})
+	}()
+	err = s.RunJob(j)
+	assert.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+
+	records := s.GetRecords(j.Id)
+	assert.Len(t, records, 1)
+	assert.Equal(t, agscheduler.RECORD_SUCCEEDED, records[0].Status)
+	assert.Equal(t, map[string]any{"job_id": j.Id}, records[0].Result)
+
+	go func() {
+		<-w.Runs()
+		s.DisconnectWorker(w)
+	}()
+	err = s.RunJob(j)
+	assert.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+
+	records = s.GetRecords(j.Id)
+	assert.Equal(t, agscheduler.RECORD_FAILED, records[0].Status)
+
+	err = s.DeleteAllJobs()
+	assert.NoError(t, err)
+}
+
+func TestSchedulerWorkflow(t *testing.T) {
+	s := getSchedulerWithStore()
+	defer s.Stop()
+	r := agscheduler.NewFuncRegistry()
+	r.Register(dryRunScheduler)
+	agscheduler.RegisterTypedTo(r, "fail", func(ctx context.Context, j agscheduler.Job, args struct{}) error {
+		return errors.New("fail")
+	})
+	s.SetFuncRegistry(r)
+
+	a := getJob()
+	a.Interval = "1h"
+	a.Timeout = "1s"
+	a, err := s.AddJob(a)
+	assert.NoError(t, err)
+
+	b := getJob()
+	b.FuncName = "fail"
+	b.Timeout = "1s"
+	b.Upstreams = []string{a.Id}
+	b, err = s.AddJob(b)
+	assert.NoError(t, err)
+	assert.Equal(t, agscheduler.TRIGGER_ALL_SUCCEEDED, b.TriggerRule)
+	assert.Equal(t, 9999, b.NextRunTime.Year())
+
+	c := getJob()
+	c.Timeout = "1s"
+	c.Upstreams = []string{b.Id}
+	c.TriggerRule = agscheduler.TRIGGER_ANY_FAILED
+	c, err = s.AddJob(c)
+	assert.NoError(t, err)
+
+	d := getJob()
+	d.Timeout = "1s"
+	d.Upstreams = []string{b.Id}
+	d, err = s.AddJob(d)
+	assert.NoError(t, err)
+
+	err = s.RunJob(a)
+	assert.NoError(t, err)
+	time.Sleep(200 * time.Millisecond)
+
+	records := s.GetRecords(c.Id)
+	assert.Len(t, records, 1)
+	assert.Equal(t, agscheduler.RECORD_SUCCEEDED, records[0].Status)
+	assert.Empty(t, s.GetRecords(d.Id))
+
+	run, err := s.GetWorkflowRun(records[0].WorkflowRunId)
+	assert.NoError(t, err)
+	assert.Equal(t, a.Id, run.RootJobId)
+	assert.Equal(t, agscheduler.RECORD_FAILED, run.Status)
+	statuses := make(map[string]string)
+	for _, wj := range run.Jobs {
+		statuses[wj.Id] = wj.Status
+	}
+	assert.Equal(t, map[string]string{
+		a.Id: agscheduler.RECORD_SUCCEEDED,
+		b.Id: agscheduler.RECORD_FAILED,
+		c.Id: agscheduler.RECORD_SUCCEEDED,
+		d.Id: agscheduler.WORKFLOW_SKIPPED,
+	}, statuses)
+
+	_, err = s.GetWorkflowRun("unknown")
+	assert.ErrorIs(t, err, agscheduler.WorkflowRunNotFoundError("unknown"))
+}
+
+func TestSchedulerWorkflowError(t *testing.T) {
+	s := getSchedulerWithStore()
+	defer s.Stop()
+	a := getJob()
+	a.Interval = "1h"
+	a, err := s.AddJob(a)
+	assert.NoError(t, err)
+	b := getJob()
+	b.Upstreams = []string{a.Id}
+	b, err = s.AddJob(b)
+	assert.NoError(t, err)
+
+	c := getJob()
+	c.Upstreams = []string{"unknown"}
+	_, err = s.AddJob(c)
+	assert.ErrorIs(t, err, agscheduler.JobNotFoundError("unknown"))
+
+	c.Upstreams = []string{a.Id}
+	c.TriggerRule = "unknown"
+	_, err = s.AddJob(c)
+	assert.Error(t, err)
+
+	a.Upstreams = []string{b.Id}
+	_, err = s.UpdateJob(a)
+	cycleErr := &agscheduler.WorkflowCycleError{}
+	assert.ErrorAs(t, err, &cycleErr)
+	assert.Equal(t, []string{a.Id, b.Id, a.Id}, cycleErr.Cycle)
+}
+
+func TestSchedulerChainJobs(t *testing.T) {
+	s := getSchedulerWithStore()
+	defer s.Stop()
+	r := agscheduler.NewFuncRegistry()
+	agscheduler.RegisterShellTo(r)
+	agscheduler.RegisterTypedTo(r, "fail", func(ctx context.Context, j agscheduler.Job, args struct{}) error {
+		return errors.New("fail")
+	})
+	chained := make(chan agscheduler.Job, 2)
+	agscheduler.RegisterTypedTo(r, "chained", func(ctx context.Context, j agscheduler.Job, args struct{}) error {
+		chained <- j
+		return nil
+	})
+	s.SetFuncRegistry(r)
+
+	b := getJob()
+	b.Name = "Job-B"
+	b.FuncName = "chained"
+	b.Interval = "1h"
+	b, err := s.AddJob(b)
+	assert.NoError(t, err)
+
+	a := getJob()
+	a.FuncName = agscheduler.FUNC_SHELL
+	a.Args = map[string]any{"argv": []any{"echo", "chained"}}
+	a.Interval = "1h"
+	a.Timeout = "1s"
+	a.OnSuccess = []string{"Job-B", "unknown"}
+	a.OnFailure = []string{b.Id}
+	a, err = s.AddJob(a)
+	assert.NoError(t, err)
+
+	err = s.RunJob(a)
+	assert.NoError(t, err)
+	select {
+	case j := <-chained:
+		assert.Equal(t, a.Id, j.TriggeredBy)
+		assert.Equal(t, "chained\n", j.TriggerResult["stdout"])
+	case <-time.After(time.Second):
+		t.Fatal("chained job not run")
+	}
+
+	a.FuncName = "fail"
+	a.Args = nil
+	a.OnSuccess = nil
+	a.TriggeredBy = "manual"
+	a, err = s.UpdateJob(a)
+	assert.NoError(t, err)
+	assert.Empty(t, a.TriggeredBy)
+
+	err = s.RunJob(a)
+	assert.NoError(t, err)
+	select {
+	case j := <-chained:
+		assert.Equal(t, a.Id, j.TriggeredBy)
+	case <-time.After(time.Second):
+		t.Fatal("chained job not run")
+	}
+
+	records := s.GetRecords(b.Id)
+	assert.Len(t, records, 2)
+}
+
+func TestSchedulerCronExprError(t *testing.T) {
+	s := getSchedulerWithStore()
+	defer s.Stop()
+	j := getJob()
+	j.Type = agscheduler.TYPE_CRON
+	j.CronExpr = "0 0 25 * * * *"
+
+	_, err := s.AddJob(j)
+	cronErr := &agscheduler.InvalidCronExprError{}
+	assert.ErrorAs(t, err, &cronErr)
+	assert.Equal(t, "hour", cronErr.Field)
+	assert.Equal(t, 4, cronErr.Position)
+
+	j.CronExpr = "H H * * *"
+	j, err = s.AddJob(j)
+	assert.NoError(t, err)
+	j, err = s.PauseJob(j.Id)
+	assert.NoError(t, err)
+
+	j.CronExpr = "@every 1"
+	_, err = s.UpdateJob(j)
+	assert.ErrorAs(t, err, &cronErr)
+	assert.Equal(t, 7, cronErr.Position)
+}
+
+func TestSchedulerRRule(t *testing.T) {
+	s := getSchedulerWithStore()
+	defer s.Stop()
+	j := getJob()
+	j.Type = agscheduler.TYPE_RRULE
+	j.Timezone = "America/New_York"
+	j.StartAt = "2024-01-01 09:00:00"
+	j.RRule = "RRULE:FREQ=MONTHLY;BYDAY=2TU\nEXDATE:20240109T090000"
+
+	j, err := s.AddJob(j)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Tuesday, j.NextRunTimeWithTimezone().Weekday())
+	assert.Equal(t, 9, j.NextRunTimeWithTimezone().Hour())
+	assert.True(t, j.NextRunTime.After(time.Now()))
+
+	ts, err := s.PreviewRunTimes(j, 2, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 2, 13, 14, 0, 0, 0, time.UTC), ts[0].UTC())
+	assert.Equal(t, time.Date(2024, 3, 12, 13, 0, 0, 0, time.UTC), ts[1].UTC())
+
+	j.RRule = "RRULE:FREQ=WEEKLY;BYDAY=1MO"
+	_, err = s.UpdateJob(j)
+	scheduleErr := &agscheduler.JobScheduleError{}
+	assert.ErrorAs(t, err, &scheduleErr)
+	assert.Equal(t, "rrule", scheduleErr.Field)
+
+	j.RRule = "RRULE:FREQ=DAILY;COUNT=1"
+	j, err = s.UpdateJob(j)
+	assert.NoError(t, err)
+	assert.Equal(t, "9999-09-09 09:09:09", j.NextRunTimeWithTimezone().Format(time.DateTime))
+}
+
+func TestSchedulerCombined(t *testing.T) {
+	s := getSchedulerWithStore()
+	defer s.Stop()
+	j := getJob()
+	j.Type = agscheduler.TYPE_COMBINED
+	j.Operator = agscheduler.OPERATOR_OR
+	j.Triggers = []agscheduler.Trigger{
+		{Type: agscheduler.TYPE_CRON, CronExpr: "0 0 9 * * * 2099"},
+		{Type: agscheduler.TYPE_DATETIME, StartAt: "2098-01-01 00:00:00"},
+	}
+
+	j, err := s.AddJob(j)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2098, 1, 1, 0, 0, 0, 0, time.UTC), j.NextRunTime)
+
+	j, err = s.GetJob(j.Id)
+	assert.NoError(t, err)
+	assert.Len(t, j.Triggers, 2)
+
+	j.Triggers[1].StartAt = "2098-13-01 00:00:00"
+	_, err = s.UpdateJob(j)
+	scheduleErr := &agscheduler.JobScheduleError{}
+	assert.ErrorAs(t, err, &scheduleErr)
+	assert.Equal(t, "triggers[1].start_at", scheduleErr.Field)
+}
+
+func TestSchedulerAllowedWindows(t *testing.T) {
+	s := getSchedulerWithStore()
+	defer s.Stop()
+	// The window opens within 2 seconds.
+	start := time.Now().UTC().Truncate(time.Second).Add(2 * time.Second)
+	window := agscheduler.CalendarWindow{Start: start.Format(time.TimeOnly), End: start.Add(time.Hour).Format(time.TimeOnly)}
+
+	j := getJob()
+	j.Interval = "200ms"
+	j.AllowedWindows = []agscheduler.CalendarWindow{window}
+	j, err := s.AddJob(j)
+	assert.NoError(t, err)
+	j2 := getJob()
+	j2.Interval = "200ms"
+	j2.AllowedWindows = []agscheduler.CalendarWindow{window}
+	j2.WindowPolicy = agscheduler.WINDOW_SKIP
+	j2, err = s.AddJob(j2)
+	assert.NoError(t, err)
+
+	time.Sleep(time.Until(start) - 300*time.Millisecond)
+	statuses := func(id string) []string {
+		ss := make([]string, 0)
+		for _, r := range s.GetRecords(id) {
+			ss = append(ss, r.Status)
+		}
+		return ss
+	}
+	assert.Contains(t, statuses(j.Id), agscheduler.RECORD_DEFERRED)
+	assert.NotContains(t, statuses(j.Id), agscheduler.RECORD_SUCCEEDED)
+	assert.Contains(t, statuses(j2.Id), agscheduler.RECORD_SKIPPED)
+	assert.NotContains(t, statuses(j2.Id), agscheduler.RECORD_SUCCEEDED)
+
+	time.Sleep(600 * time.Millisecond)
+	assert.Contains(t, statuses(j.Id), agscheduler.RECORD_SUCCEEDED)
+	assert.Contains(t, statuses(j2.Id), agscheduler.RECORD_SUCCEEDED)
+
+	j.AllowedWindows = []agscheduler.CalendarWindow{{Start: "25:00", End: "05:00"}}
+	_, err = s.UpdateJob(j)
+	assert.ErrorContains(t, err, "allowed_windows")
+}
+
+func TestSchedulerAllowedWindowsDatetime(t *testing.T) {
+	s := getSchedulerWithStore()
+	defer s.Stop()
+	// The window opens within 2 seconds.
+	start := time.Now().UTC().Truncate(time.Second).Add(2 * time.Second)
+	window := agscheduler.CalendarWindow{Start: start.Format(time.TimeOnly), End: start.Add(time.Hour).Format(time.TimeOnly)}
+
+	j := getJob()
+	j.Type = agscheduler.TYPE_DATETIME
+	j.StartAt = time.Now().UTC().Format(time.DateTime)
+	j.Timezone = "UTC"
+	j.AllowedWindows = []agscheduler.CalendarWindow{window}
+	j, err := s.AddJob(j)
+	assert.NoError(t, err)
+	// Deleted by the user while its run is deferred.
+	j2 := j
+	j2.Id = ""
+	j2, err = s.AddJob(j2)
+	assert.NoError(t, err)
+
+	time.Sleep(time.Until(start) - 300*time.Millisecond)
+	assert.Equal(t, agscheduler.RECORD_DEFERRED, s.GetRecords(j.Id)[0].Status)
+	assert.Equal(t, agscheduler.RECORD_DEFERRED, s.GetRecords(j2.Id)[0].Status)
+	// The one-off jobs are removed from the store once due.
+	_, err = s.GetJob(j.Id)
+	assert.ErrorIs(t, err, agscheduler.JobNotFoundError(j.Id))
+	err = s.DeleteJob(j2.Id)
+	assert.NoError(t, err)
+
+	time.Sleep(600 * time.Millisecond)
+	assert.Equal(t, agscheduler.RECORD_SUCCEEDED, s.GetRecords(j.Id)[0].Status)
+	assert.Equal(t, agscheduler.RECORD_DEFERRED, s.GetRecords(j2.Id)[0].Status)
+}
+
+// A third-party store, implementing none of the optional store interfaces.
+type jobsOnlyStore struct {
+	agscheduler.Store
+}
+
+func TestSchedulerCalendarFallback(t *testing.T) {
+	s := &agscheduler.Scheduler{}
+	err := s.SetStore(jobsOnlyStore{&stores.MemoryStore{}})
+	assert.NoError(t, err)
+	defer s.Stop()
+
+	_, err = s.SetCalendar(agscheduler.Calendar{Name: "weekdays", Rules: []agscheduler.CalendarRule{{Weekdays: []int{1, 2, 3, 4, 5}}}})
+	assert.NoError(t, err)
+	j := getJob()
+	j.Type = agscheduler.TYPE_CRON
+	j.CronExpr = "0 9 * * *"
+	j.Calendars = []string{"weekdays"}
+	j, err = s.AddJob(j)
+	assert.NoError(t, err)
+	assert.Contains(t, []time.Weekday{time.Saturday, time.Sunday}, j.NextRunTime.Weekday())
+
+	cs, err := s.GetAllCalendars()
+	assert.NoError(t, err)
+	assert.Len(t, cs, 1)
+	err = s.DeleteJob(j.Id)
+	assert.NoError(t, err)
+	err = s.DeleteCalendar("weekdays")
+	assert.NoError(t, err)
+	_, err = s.GetCalendar("weekdays")
+	assert.ErrorIs(t, err, agscheduler.CalendarNotFoundError("weekdays"))
+}
+
+func TestSchedulerCalendar(t *testing.T) {
+	s := getSchedulerWithStore()
+	defer s.Stop()
+
+	j := getJob()
+	j.Type = agscheduler.TYPE_CRON
+	j.CronExpr = "0 9 * * *"
+	j.Calendars = []string{"holidays"}
+	_, err := s.AddJob(j)
+	assert.ErrorIs(t, err, agscheduler.CalendarNotFoundError("holidays"))
+
+	_, err = s.SetCalendar(agscheduler.Calendar{Name: "holidays", Dates: []string{"2024-13-01"}})
+	assert.Error(t, err)
+	c, err := s.SetCalendar(agscheduler.Calendar{Name: "holidays"})
+	assert.NoError(t, err)
+	j, err = s.AddJob(j)
+	assert.NoError(t, err)
+
+	// Every day is excluded except the weekends.
+	c.Rules = []agscheduler.CalendarRule{{Weekdays: []int{1, 2, 3, 4, 5}}}
+	_, err = s.SetCalendar(c)
+	assert.NoError(t, err)
+	j, err = s.GetJob(j.Id)
+	assert.NoError(t, err)
+	assert.Contains(t, []time.Weekday{time.Saturday, time.Sunday}, j.NextRunTime.Weekday())
+
+	ts, err := s.PreviewRunTimes(j, 4, time.Time{})
+	assert.NoError(t, err)
+	for _, t2 := range ts {
+		assert.Contains(t, []time.Weekday{time.Saturday, time.Sunday}, t2.Weekday())
+	}
+
+	cs, err := s.GetAllCalendars()
+	assert.NoError(t, err)
+	assert.Len(t, cs, 1)
+
+	err = s.DeleteCalendar("holidays")
+	assert.Error(t, err)
+	err = s.DeleteJob(j.Id)
+	assert.NoError(t, err)
+	err = s.DeleteCalendar("holidays")
+	assert.NoError(t, err)
+	_, err = s.GetCalendar("holidays")
+	assert.ErrorIs(t, err, agscheduler.CalendarNotFoundError("holidays"))
+	err = s.DeleteCalendar("holidays")
+	assert.ErrorIs(t, err, agscheduler.CalendarNotFoundError("holidays"))
+
+	c, err = s.ImportCalendarICS("ics", strings.NewReader("BEGIN:VEVENT\nDTSTART;VALUE=DATE:20240101\nEND:VEVENT\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2024-01-01"}, c.Dates)
+	_, err = s.GetCalendar("ics")
+	assert.NoError(t, err)
+}
+
+func TestSchedulerPreviewRunTimes(t *testing.T) {
+	s := getSchedulerWithStore()
+	defer s.Stop()
+	from := time.Date(2024, 3, 9, 12, 0, 0, 0, time.UTC)
+
+	j := agscheduler.Job{
+		Name:     "Job",
+		Type:     agscheduler.TYPE_CRON,
+		CronExpr: "0 9 * * *",
+		Timezone: "America/New_York",
+	}
+	ts, err := s.PreviewRunTimes(j, 3, from)
+	assert.NoError(t, err)
+	assert.Len(t, ts, 3)
+	// Across the start of daylight saving time.
+	assert.Equal(t, time.Date(2024, 3, 9, 14, 0, 0, 0, time.UTC), ts[0].UTC())
+	assert.Equal(t, time.Date(2024, 3, 10, 13, 0, 0, 0, time.UTC), ts[1].UTC())
+	assert.Equal(t, time.Date(2024, 3, 11, 13, 0, 0, 0, time.UTC), ts[2].UTC())
+	assert.Equal(t, 9, ts[1].Hour())
+	assert.Equal(t, "America/New_York", ts[1].Location().String())
+
+	j.Type = agscheduler.TYPE_INTERVAL
+	j.Interval = "90m"
+	ts, err = s.PreviewRunTimes(j, 2, from)
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{from.Add(90 * time.Minute), from.Add(3 * time.Hour)}, []time.Time{ts[0].UTC(), ts[1].UTC()})
+
+	j.Type = agscheduler.TYPE_DATETIME
+	j.StartAt = "2024-03-10 09:00:00"
+	ts, err = s.PreviewRunTimes(j, 3, from)
+	assert.NoError(t, err)
+	assert.Len(t, ts, 1)
+	ts, err = s.PreviewRunTimes(j, 3, from.AddDate(0, 0, 2))
+	assert.NoError(t, err)
+	assert.Empty(t, ts)
+
+	j.Upstreams = []string{"upstream"}
+	ts, err = s.PreviewRunTimes(j, 3, from)
+	assert.NoError(t, err)
+	assert.Empty(t, ts)
+
+	js, err := s.GetAllJobs()
+	assert.NoError(t, err)
+	assert.Empty(t, js)
+}
+
+func TestSchedulerPreviewRunTimesError(t *testing.T) {
+	s := getSchedulerWithStore()
+	defer s.Stop()
+	j := agscheduler.Job{
+		Name:     "Job",
+		Type:     agscheduler.TYPE_CRON,
+		CronExpr: "* * *",
+	}
+
+	_, err := s.PreviewRunTimes(j, 0, time.Time{})
+	assert.Error(t, err)
+
+	_, err = s.PreviewRunTimes(j, 1, time.Time{})
+	scheduleErr := &agscheduler.JobScheduleError{}
+	assert.ErrorAs(t, err, &scheduleErr)
+	assert.Equal(t, "cron_expr", scheduleErr.Field)
+
+	j.Type = agscheduler.TYPE_INTERVAL
+	j.Interval = "2"
+	_, err = s.PreviewRunTimes(j, 1, time.Time{})
+	assert.ErrorAs(t, err, &scheduleErr)
+	assert.Equal(t, "interval", scheduleErr.Field)
+
+	j.Timezone = "unknown"
+	_, err = s.PreviewRunTimes(j, 1, time.Time{})
+	assert.ErrorAs(t, err, &scheduleErr)
+	assert.Equal(t, "timezone", scheduleErr.Field)
+}
+
+func TestSchedulerPool(t *testing.T) {
+	s := getSchedulerWithStore()
+	s.PoolSize = 1
+	r := agscheduler.NewFuncRegistry()
+	release := make(chan struct{})
+	agscheduler.RegisterTypedTo(r, "block", func(ctx context.Context, j agscheduler.Job, args struct{}) error {
+		<-release
+		return nil
+	})
+	s.SetFuncRegistry(r)
+	j := getJob()
+	j.FuncName = "block"
+	j.Timeout = "1s"
+
+	for i := 0; i < 3; i++ {
+		j.Id = fmt.Sprintf("%d", i)
+		err := s.RunJob(j)
+		assert.NoError(t, err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, agscheduler.PoolStats{Size: 1, Running: 1, Backlog: 2, Utilization: 1}, s.PoolStats())
+	assert.Len(t, s.GetRecords(""), 1)
+
+	release <- struct{}{}
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 1, s.PoolStats().Backlog)
+	assert.Len(t, s.GetRecords(""), 2)
+
+	// The run left in the backlog is canceled.
+	close(release)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err := s.Shutdown(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, agscheduler.PoolStats{Size: 1}, s.PoolStats())
+}
+
+func TestSchedulerConcurrencyKeys(t *testing.T) {
+	// Two schedulers sharing a store, like the nodes of a cluster.
+	store := &stores.MemoryStore{}
+	s1 := &agscheduler.Scheduler{}
+	s1.SetStore(store)
+	s2 := &agscheduler.Scheduler{SemaphoreTimeout: 300 * time.Millisecond}
+	s2.SetStore(store)
+
+	r := agscheduler.NewFuncRegistry()
+	release := make(chan struct{})
+	agscheduler.RegisterTypedTo(r, "block", func(ctx context.Context, j agscheduler.Job, args struct{}) error {
+		<-release
+		return nil
+	})
+	s1.SetFuncRegistry(r)
+	s2.SetFuncRegistry(r)
+	j := getJob()
+	j.FuncName = "block"
+	j.Timeout = "1s"
+	j.ConcurrencyKeys = []string{"table"}
+
+	j.Id = "1"
+	err := s1.RunJob(j)
+	assert.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+	j.Id = "2"
+	err = s2.RunJob(j)
+	assert.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, agscheduler.RECORD_BLOCKED, s2.GetRecords("2")[0].Status)
+
+	// Started once the key is released.
+	release <- struct{}{}
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, agscheduler.RECORD_SUCCEEDED, s1.GetRecords("1")[0].Status)
+	assert.Equal(t, agscheduler.RECORD_RUNNING, s2.GetRecords("2")[0].Status)
+
+	// Failed after waiting `SemaphoreTimeout`.
+	j.Id = "3"
+	err = s2.RunJob(j)
+	assert.NoError(t, err)
+	time.Sleep(500 * time.Millisecond)
+	records := s2.GetRecords("3")
+	assert.Len(t, records, 2)
+	assert.Equal(t, agscheduler.RECORD_FAILED, records[0].Status)
+	assert.Contains(t, records[0].Error, "wait timeout")
+
+	close(release)
+}
+
+func TestSchedulerSemaphores(t *testing.T) {
+	s := getSchedulerWithStore()
+	s.Semaphores = map[string]int{"table": 2}
+	r := agscheduler.NewFuncRegistry()
+	release := make(chan struct{})
+	agscheduler.RegisterTypedTo(r, "block", func(ctx context.Context, j agscheduler.Job, args struct{}) error {
+		<-release
+		return nil
+	})
+	s.SetFuncRegistry(r)
+	j := getJob()
+	j.FuncName = "block"
+	j.Timeout = "1s"
+
+	for i, keys := range [][]string{{"table"}, {"table", "other"}, {"other", "table"}} {
+		j.Id = fmt.Sprintf("%d", i)
+		j.ConcurrencyKeys = keys
+		err := s.RunJob(j)
+		assert.NoError(t, err)
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.Equal(t, agscheduler.RECORD_RUNNING, s.GetRecords("0")[0].Status)
+	assert.Equal(t, agscheduler.RECORD_RUNNING, s.GetRecords("1")[0].Status)
+	assert.Equal(t, agscheduler.RECORD_BLOCKED, s.GetRecords("2")[0].Status)
+
+	close(release)
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, agscheduler.RECORD_SUCCEEDED, s.GetRecords("2")[0].Status)
+}
+
+func TestSchedulerConcurrencyKeysPool(t *testing.T) {
+	s := getSchedulerWithStore()
+	s.PoolSize = 2
+	r := agscheduler.NewFuncRegistry()
+	release := make(chan struct{})
+	agscheduler.RegisterTypedTo(r, "block", func(ctx context.Context, j agscheduler.Job, args struct{}) error {
+		<-release
+		return nil
+	})
+	agscheduler.RegisterTypedTo(r, "dry", func(ctx context.Context, j agscheduler.Job, args struct{}) error {
+		return nil
+	})
+	s.SetFuncRegistry(r)
+	j := getJob()
+	j.FuncName = "block"
+	j.Timeout = "1s"
+	j.ConcurrencyKeys = []string{"table"}
+
+	for _, id := range []string{"1", "2"} {
+		j.Id = id
+		err := s.RunJob(j)
+		assert.NoError(t, err)
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.Equal(t, agscheduler.RECORD_BLOCKED, s.GetRecords("2")[0].Status)
+	// The blocked run does not take a worker of the pool.
+	assert.Equal(t, 1, s.PoolStats().Running)
+	j.Id = "3"
+	j.FuncName = "dry"
+	j.ConcurrencyKeys = nil
+	err := s.RunJob(j)
+	assert.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, agscheduler.RECORD_SUCCEEDED, s.GetRecords("3")[0].Status)
+
+	close(release)
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, agscheduler.RECORD_SUCCEEDED, s.GetRecords("2")[0].Status)
+}
+
+func TestSchedulerSemaphoresFallback(t *testing.T) {
+	s := &agscheduler.Scheduler{}
+	err := s.SetStore(jobsOnlyStore{&stores.MemoryStore{}})
+	assert.NoError(t, err)
+	r := agscheduler.NewFuncRegistry()
+	release := make(chan struct{})
+	agscheduler.RegisterTypedTo(r, "block", func(ctx context.Context, j agscheduler.Job, args struct{}) error {
+		<-release
+		return nil
+	})
+	s.SetFuncRegistry(r)
+	j := getJob()
+	j.FuncName = "block"
+	j.Timeout = "1s"
+	j.ConcurrencyKeys = []string{"table"}
+
+	for _, id := range []string{"1", "2"} {
+		j.Id = id
+		err := s.RunJob(j)
+		assert.NoError(t, err)
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.Equal(t, agscheduler.RECORD_RUNNING, s.GetRecords("1")[0].Status)
+	assert.Equal(t, agscheduler.RECORD_BLOCKED, s.GetRecords("2")[0].Status)
+
+	close(release)
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, agscheduler.RECORD_SUCCEEDED, s.GetRecords("2")[0].Status)
+}
+
+func TestSchedulerPriority(t *testing.T) {
+	s := getSchedulerWithStore()
+	s.PoolSize = 1
+	r := agscheduler.NewFuncRegistry()
+	release := make(chan struct{})
+	agscheduler.RegisterTypedTo(r, "block", func(ctx context.Context, j agscheduler.Job, args struct{}) error {
+		<-release
+		return nil
+	})
+	s.SetFuncRegistry(r)
+
+	// Due at the same wakeup, the low priority job is added first.
+	startAt := time.Now().UTC().Truncate(time.Second).Add(2 * time.Second)
+	j := agscheduler.Job{
+		Type: agscheduler.TYPE_DATETIME, StartAt: startAt.Format(time.DateTime), Timezone: "UTC",
+		FuncName: "block", Timeout: "1s",
+	}
+	j.Name = "Low"
+	low, err := s.AddJob(j)
+	assert.NoError(t, err)
+	j.Name = "High"
+	j.Priority = 5
+	high, err := s.AddJob(j)
+	assert.NoError(t, err)
+
+	time.Sleep(time.Until(startAt) + 200*time.Millisecond)
+	assert.Equal(t, agscheduler.RECORD_RUNNING, s.GetRecords(high.Id)[0].Status)
+	assert.Empty(t, s.GetRecords(low.Id))
+
+	close(release)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, agscheduler.RECORD_SUCCEEDED, s.GetRecords(low.Id)[0].Status)
+	s.Stop()
+}
+
+func TestSchedulerRateLimits(t *testing.T) {
+	s := getSchedulerWithStore()
+	s.FuncRateLimits = map[string]agscheduler.RateLimit{"api": {Rate: 10}}
+	r := agscheduler.NewFuncRegistry()
+	agscheduler.RegisterTypedTo(r, "api", func(ctx context.Context, j agscheduler.Job, args struct{}) error {
+		return nil
+	})
+	s.SetFuncRegistry(r)
+	j := getJob()
+	j.FuncName = "api"
+	j.Timeout = "1s"
+
+	for i := 0; i < 3; i++ {
+		j.Id = fmt.Sprintf("%d", i)
+		err := s.RunJob(j)
+		assert.NoError(t, err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, s.GetRecords(""), 1)
+	assert.Equal(t, 2, s.ThrottleStats()["func:api"].Waiting)
+
+	time.Sleep(300 * time.Millisecond)
+	for i, delay := range []float64{0, 0.1, 0.2} {
+		records := s.GetRecords(fmt.Sprintf("%d", i))
+		assert.Len(t, records, 1)
+		assert.Equal(t, agscheduler.RECORD_SUCCEEDED, records[0].Status)
+		assert.InDelta(t, delay, records[0].ThrottleDelay, 0.05)
+	}
+	ts := s.ThrottleStats()["func:api"]
+	assert.Equal(t, 2, ts.Throttled)
+	assert.Equal(t, 0, ts.Waiting)
+	assert.InDelta(t, 0.2, ts.MaxDelay, 0.05)
+}
+
+func TestSchedulerRateLimitsDropped(t *testing.T) {
+	s := getSchedulerWithStore()
+	s.PoolSize = 1
+	s.BacklogSize = 1
+	s.OverflowPolicy = agscheduler.OVERFLOW_DROP
+	s.FuncRateLimits = map[string]agscheduler.RateLimit{"block": {Rate: 10, Burst: 3}}
+	r := agscheduler.NewFuncRegistry()
+	release := make(chan struct{})
+	agscheduler.RegisterTypedTo(r, "block", func(ctx context.Context, j agscheduler.Job, args struct{}) error {
+		<-release
+		return nil
+	})
+	s.SetFuncRegistry(r)
+	j := getJob()
+	j.FuncName = "block"
+	j.Timeout = "1s"
+
+	// Running, in the backlog, then dropped twice.
+	for i := 0; i < 4; i++ {
+		j.Id = fmt.Sprintf("%d", i)
+		err := s.RunJob(j)
+		assert.NoError(t, err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, agscheduler.RECORD_SKIPPED, s.GetRecords("3")[0].Status)
+	// The tokens of the dropped runs are given back.
+	assert.Zero(t, s.ThrottleStats()["func:block"].Throttled)
+
+	close(release)
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestSchedulerPoolBlockNotHoldingLoop(t *testing.T) {
+	s := getSchedulerWithStore()
+	s.PoolSize = 1
+	s.BacklogSize = 1
+	r := agscheduler.NewFuncRegistry()
+	release := make(chan struct{})
+	agscheduler.RegisterTypedTo(r, "block", func(ctx context.Context, j agscheduler.Job, args struct{}) error {
+		<-release
+		return nil
+	})
+	s.SetFuncRegistry(r)
+	defer s.Stop()
+	defer close(release)
+
+	// The pool and its backlog are full after the first runs.
+	j1 := getJob()
+	j1.FuncName = "block"
+	j1, err := s.AddJob(j1)
+	assert.NoError(t, err)
+	j2 := getJob()
+	j2.Name = "Job2"
+	j2.FuncName = "block"
+	j2, err = s.AddJob(j2)
+	assert.NoError(t, err)
+
+	time.Sleep(300 * time.Millisecond)
+	assert.Equal(t, 1, s.PoolStats().Backlog)
+	for _, id := range []string{j1.Id, j2.Id} {
+		j, err := s.GetJob(id)
+		assert.NoError(t, err)
+		assert.True(t, j.NextRunTime.After(time.Now().Add(-100*time.Millisecond)), j.NextRunTime.String())
+	}
+}
+
+// Counts how often the scheduler reads all jobs, once per wakeup.
+type countingStore struct {
+	stores.MemoryStore
+
+	getAllJobs atomic.Int64
+}
+
+func (s *countingStore) GetAllJobs() ([]agscheduler.Job, error) {
+	s.getAllJobs.Add(1)
+	return s.MemoryStore.GetAllJobs()
+}
+
+func TestSchedulerPausedOverdueNotPolled(t *testing.T) {
+	store := &countingStore{}
+	s := &agscheduler.Scheduler{}
+	err := s.SetStore(store)
+	assert.NoError(t, err)
 	defer s.Stop()
+
+	// The one-off job stays due while paused.
+	s.Pause()
 	j := getJob()
 	j.Type = agscheduler.TYPE_DATETIME
-	j.StartAt = "2023-09-22 07:30:08"
-
-	j, err := s.AddJob(j)
+	j.StartAt = time.Now().UTC().Add(-time.Second).Format(time.DateTime)
+	j.Timezone = "UTC"
+	_, err = s.AddJob(j)
 	assert.NoError(t, err)
 
-	time.Sleep(50 * time.Millisecond)
-
-	_, err = s.GetJob(j.Id)
-	assert.ErrorIs(t, err, agscheduler.JobNotFoundError(j.Id))
+	time.Sleep(200 * time.Millisecond)
+	polls := store.getAllJobs.Load()
+	time.Sleep(time.Second)
+	assert.LessOrEqual(t, store.getAllJobs.Load()-polls, int64(2))
 }
 
-func TestSchedulerAddJobUnregisteredError(t *testing.T) {
-	s := getSchedulerWithStore()
+func TestSchedulerFlushUnregisteredFunc(t *testing.T) {
+	store := &stores.MemoryStore{}
+	s := &agscheduler.Scheduler{}
+	err := s.SetStore(store)
+	assert.NoError(t, err)
 	defer s.Stop()
-	j := getJobWithoutFunc()
-
-	_, err := s.AddJob(j)
-	assert.ErrorIs(t, err, agscheduler.FuncUnregisteredError(""))
-}
 
-func TestSchedulerAddJobTimeoutError(t *testing.T) {
-	s := getSchedulerWithStore()
-	defer s.Stop()
-	j := getJob()
-	j.Timeout = "errorTimeout"
+	j, err := s.AddJob(getJob())
+	assert.NoError(t, err)
+	// As if the worker advertising the func disconnected.
+	j.FuncName = "unregistered"
+	err = store.UpdateJob(j)
+	assert.NoError(t, err)
 
-	_, err := s.AddJob(j)
-	assert.Contains(t, err.Error(), "Timeout `"+j.Timeout+"` error")
+	time.Sleep(200 * time.Millisecond)
+	j, err = s.GetJob(j.Id)
+	assert.NoError(t, err)
+	assert.True(t, j.NextRunTime.After(time.Now().Add(-100*time.Millisecond)), j.NextRunTime.String())
 }
 
 func TestSchedulerRunJobPanic(t *testing.T) {
@@ -261,12 +1294,171 @@ func TestSchedulerResumeJob(t *testing.T) {
 	assert.Equal(t, agscheduler.STATUS_RUNNING, j.Status)
 }
 
+func TestSchedulerIntervalAnchored(t *testing.T) {
+	s := getSchedulerWithStore()
+	defer s.Stop()
+	j := getJob()
+	j.Interval = "1h"
+
+	j, err := s.AddJob(j)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, j.StartAt)
+	assert.Equal(t, j.StartAt, j.NextRunTimeWithTimezone().Format(time.DateTime))
+	nextRunTime := j.NextRunTime
+
+	_, err = s.PauseJob(j.Id)
+	assert.NoError(t, err)
+	time.Sleep(1100 * time.Millisecond)
+	j, err = s.ResumeJob(j.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, nextRunTime, j.NextRunTime)
+
+	j.StartAt = ""
+	j, err = s.UpdateJob(j)
+	assert.NoError(t, err)
+	assert.Equal(t, nextRunTime, j.NextRunTime)
+}
+
+func TestSchedulerSubSecondInterval(t *testing.T) {
+	s := getSchedulerWithStore()
+	defer s.Stop()
+	r := agscheduler.NewFuncRegistry()
+	runs := make(chan time.Time, 100)
+	agscheduler.RegisterTypedTo(r, "tick", func(ctx context.Context, j agscheduler.Job, args struct{}) error {
+		runs <- time.Now()
+		return nil
+	})
+	s.SetFuncRegistry(r)
+
+	j := getJob()
+	j.FuncName = "tick"
+	j.Interval = "200ms"
+	j, err := s.AddJob(j)
+	assert.NoError(t, err)
+
+	time.Sleep(1100 * time.Millisecond)
+	s.Stop()
+	assert.GreaterOrEqual(t, len(runs), 4)
+
+	j, err = s.GetJob(j.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, j.LastRunTime, j.LastRunTime.Truncate(time.Millisecond))
+	assert.Equal(t, j.NextRunTime, j.NextRunTime.Truncate(time.Millisecond))
+}
+
 func TestSchedulerResumeJobError(t *testing.T) {
 	s := getSchedulerWithStore()
 	_, err := s.ResumeJob("1")
 	assert.Error(t, err)
 }
 
+func TestSchedulerPauseJobs(t *testing.T) {
+	s := getSchedulerWithStore()
+	defer s.Stop()
+	j := getJob()
+	j.Tags = []string{"team=billing"}
+	j2 := getJob()
+
+	j, err := s.AddJob(j)
+	assert.NoError(t, err)
+	j2, err = s.AddJob(j2)
+	assert.NoError(t, err)
+
+	rs, err := s.PauseJobs(agscheduler.JobSelector{Tags: []string{"team=billing"}})
+	assert.NoError(t, err)
+	assert.Len(t, rs, 1)
+	assert.Equal(t, j.Id, rs[0].Id)
+	assert.Empty(t, rs[0].Error)
+
+	j, err = s.GetJob(j.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, agscheduler.STATUS_PAUSED, j.Status)
+	j2, err = s.GetJob(j2.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, agscheduler.STATUS_RUNNING, j2.Status)
+}
+
+func TestSchedulerResumeJobs(t *testing.T) {
+	s := getSchedulerWithStore()
+	defer s.Stop()
+	j := getJob()
+	j.Queues = []string{"default"}
+
+	j, err := s.AddJob(j)
+	assert.NoError(t, err)
+	_, err = s.PauseJob(j.Id)
+	assert.NoError(t, err)
+
+	rs, err := s.ResumeJobs(agscheduler.JobSelector{Queue: "default", Status: agscheduler.STATUS_PAUSED})
+	assert.NoError(t, err)
+	assert.Len(t, rs, 1)
+
+	j, err = s.GetJob(j.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, agscheduler.STATUS_RUNNING, j.Status)
+}
+
+func TestSchedulerDeleteJobs(t *testing.T) {
+	s := getSchedulerWithStore()
+	defer s.Stop()
+	j := getJob()
+	j2 := getJob()
+	j2.Name = "Job2"
+
+	_, err := s.AddJob(j)
+	assert.NoError(t, err)
+	j2, err = s.AddJob(j2)
+	assert.NoError(t, err)
+
+	rs, err := s.DeleteJobs(agscheduler.JobSelector{Names: []string{"Job"}})
+	assert.NoError(t, err)
+	assert.Len(t, rs, 1)
+
+	js, err := s.GetAllJobs()
+	assert.NoError(t, err)
+	assert.Len(t, js, 1)
+	assert.Equal(t, j2.Id, js[0].Id)
+}
+
+func TestSchedulerDeleteJobsEmptySelector(t *testing.T) {
+	s := getSchedulerWithStore()
+	defer s.Stop()
+
+	_, err := s.AddJob(getJob())
+	assert.NoError(t, err)
+
+	_, err = s.DeleteJobs(agscheduler.JobSelector{})
+	assert.ErrorIs(t, err, agscheduler.ErrEmptyJobSelector)
+	js, err := s.GetAllJobs()
+	assert.NoError(t, err)
+	assert.Len(t, js, 1)
+
+	rs, err := s.DeleteJobs(agscheduler.JobSelector{All: true})
+	assert.NoError(t, err)
+	assert.Len(t, rs, 1)
+	js, err = s.GetAllJobs()
+	assert.NoError(t, err)
+	assert.Empty(t, js)
+}
+
+func TestSchedulerRunJobsNow(t *testing.T) {
+	s := getSchedulerWithStore()
+	defer s.Stop()
+	j := getJob()
+
+	j, err := s.AddJob(j)
+	assert.NoError(t, err)
+
+	rs, err := s.RunJobsNow(agscheduler.JobSelector{Ids: []string{j.Id}})
+	assert.NoError(t, err)
+	assert.Len(t, rs, 1)
+	assert.Empty(t, rs[0].Error)
+
+	rs, err = s.RunJobsNow(agscheduler.JobSelector{Ids: []string{"1"}})
+	assert.NoError(t, err)
+	assert.Len(t, rs, 0)
+}
+
 func TestSchedulerRunJob(t *testing.T) {
 	s := getSchedulerWithStore()
 	j := getJob()
@@ -363,6 +1555,141 @@ func TestSchedulerStopOnce(t *testing.T) {
 	s.Stop()
 }
 
+func TestSchedulerMultiple(t *testing.T) {
+	s := getSchedulerWithStore()
+	s2 := getSchedulerWithStore()
+	r := agscheduler.NewFuncRegistry()
+	r.Register(dryRunScheduler)
+	s2.SetFuncRegistry(r)
+
+	j, err := s.AddJob(getJob())
+	assert.NoError(t, err)
+	_, err = s2.AddJob(getJob())
+	assert.NoError(t, err)
+	j3 := getJob()
+	j3.Func = runSchedulerPanic
+	_, err = s2.AddJob(j3)
+	assert.ErrorAs(t, err, new(agscheduler.FuncUnregisteredError))
+
+	s.Stop()
+	s.Pause()
+	assert.False(t, s2.IsPaused())
+
+	_, err = s2.GetJob(j.Id)
+	assert.ErrorIs(t, err, agscheduler.JobNotFoundError(j.Id))
+
+	s2.Stop()
+}
+
+func TestSchedulerPauseAndResume(t *testing.T) {
+	s := getSchedulerWithStore()
+	defer s.Stop()
+	j := getJob()
+
+	j, err := s.AddJob(j)
+	assert.NoError(t, err)
+
+	s.Pause()
+	s.Pause()
+	assert.True(t, s.IsPaused())
+
+	err = s.RunJob(j)
+	assert.ErrorIs(t, err, agscheduler.ErrSchedulerPaused)
+	err = s.ScheduleJob(j)
+	assert.ErrorIs(t, err, agscheduler.ErrSchedulerPaused)
+
+	_, err = s.AddJob(getJob())
+	assert.NoError(t, err)
+
+	time.Sleep(1200 * time.Millisecond)
+
+	jPaused, err := s.GetJob(j.Id)
+	assert.NoError(t, err)
+	assert.True(t, jPaused.NextRunTime.After(j.NextRunTime))
+
+	s.Resume()
+	s.Resume()
+	assert.False(t, s.IsPaused())
+
+	err = s.RunJob(j)
+	assert.NoError(t, err)
+}
+
+func TestSchedulerPauseDatetimeDeferred(t *testing.T) {
+	s := getSchedulerWithStore()
+	defer s.Stop()
+	j := getJob()
+	j.Type = agscheduler.TYPE_DATETIME
+	j.StartAt = "2023-09-22 07:30:08"
+
+	s.Pause()
+
+	j, err := s.AddJob(j)
+	assert.NoError(t, err)
+
+	time.Sleep(50 * time.Millisecond)
+
+	_, err = s.GetJob(j.Id)
+	assert.NoError(t, err)
+
+	s.Resume()
+
+	time.Sleep(50 * time.Millisecond)
+
+	_, err = s.GetJob(j.Id)
+	assert.ErrorIs(t, err, agscheduler.JobNotFoundError(j.Id))
+}
+
+func TestSchedulerShutdown(t *testing.T) {
+	s := getSchedulerWithStore()
+	j := getJob()
+	j.Func = runSchedulerSleep
+
+	j, err := s.AddJob(j)
+	assert.NoError(t, err)
+	s.Stop()
+
+	err = s.RunJob(j)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err = s.Shutdown(ctx)
+	assert.NoError(t, err)
+
+	select {
+	case <-runSchedulerSleepDone:
+	default:
+		t.Error("running job was not drained")
+	}
+
+	s.Start()
+	err = s.RunJob(j)
+	assert.NoError(t, err)
+	time.Sleep(250 * time.Millisecond)
+	assert.Len(t, runSchedulerSleepDone, 0)
+}
+
+func TestSchedulerShutdownCancel(t *testing.T) {
+	s := getSchedulerWithStore()
+	j := getJob()
+	j.Func = runSchedulerWait
+
+	j, err := s.AddJob(j)
+	assert.NoError(t, err)
+	s.Stop()
+
+	err = s.RunJob(j)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = s.Shutdown(ctx)
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
 func TestCalcNextRunTimeTimezone(t *testing.T) {
 	j := agscheduler.Job{
 		Name:     "Job",
@@ -453,3 +1780,81 @@ func TestCalcNextRunTimeIntervalError(t *testing.T) {
 	_, err := agscheduler.CalcNextRunTime(j)
 	assert.Error(t, err)
 }
+
+func TestCalcNextRunTimeIntervalAnchored(t *testing.T) {
+	j := agscheduler.Job{
+		Name:     "Job",
+		Type:     agscheduler.TYPE_INTERVAL,
+		Interval: "1h",
+		StartAt:  "2024-01-01 00:30:00",
+		Timezone: "Asia/Shanghai",
+	}
+
+	timezone, err := time.LoadLocation(j.Timezone)
+	assert.NoError(t, err)
+	s := getSchedulerWithStore()
+	ts, err := s.PreviewRunTimes(j, 2, time.Date(2023, 12, 1, 0, 0, 0, 0, timezone))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 30, 0, 0, timezone), ts[0])
+
+	// Late runs do not drift off the grid.
+	ts, err = s.PreviewRunTimes(j, 2, time.Date(2024, 1, 2, 5, 47, 13, 0, timezone))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 2, 6, 30, 0, 0, timezone), ts[0])
+	assert.Equal(t, time.Date(2024, 1, 2, 7, 30, 0, 0, timezone), ts[1])
+
+	j.Interval = "500ms"
+	ts, err = s.PreviewRunTimes(j, 3, time.Date(2024, 1, 2, 5, 47, 13, 200*int(time.Millisecond), timezone))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 2, 5, 47, 13, 500*int(time.Millisecond), timezone), ts[0])
+	assert.Equal(t, time.Date(2024, 1, 2, 5, 47, 14, 0, timezone), ts[1])
+	assert.Equal(t, time.Date(2024, 1, 2, 5, 47, 14, 500*int(time.Millisecond), timezone), ts[2])
+
+	j.Interval = "-1h"
+	_, err = agscheduler.CalcNextRunTime(j)
+	scheduleErr := &agscheduler.JobScheduleError{}
+	assert.ErrorAs(t, err, &scheduleErr)
+	assert.Equal(t, "interval", scheduleErr.Field)
+}
+
+func TestCalcNextRunTimeJitter(t *testing.T) {
+	j := agscheduler.Job{
+		Name:     "Job",
+		Type:     agscheduler.TYPE_CRON,
+		CronExpr: "0 9 * * *",
+		Jitter:   "10m",
+		Timezone: "UTC",
+	}
+	s := getSchedulerWithStore()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	nine := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	offsets := make(map[time.Duration]bool)
+	for i := 0; i < 20; i++ {
+		j.Id = fmt.Sprintf("job%d", i)
+		ts, err := s.PreviewRunTimes(j, 2, from)
+		assert.NoError(t, err)
+		assert.False(t, ts[0].Before(nine))
+		assert.False(t, ts[0].After(nine.Add(10*time.Minute)))
+		assert.Equal(t, 9, ts[1].Hour())
+		offsets[ts[0].Sub(nine)] = true
+
+		// Stable for the same job and run time.
+		ts2, err := s.PreviewRunTimes(j, 1, from)
+		assert.NoError(t, err)
+		assert.Equal(t, ts[0], ts2[0])
+	}
+	assert.Greater(t, len(offsets), 1)
+
+	j.Type = agscheduler.TYPE_INTERVAL
+	j.Interval = "5m"
+	_, err := agscheduler.CalcNextRunTime(j)
+	scheduleErr := &agscheduler.JobScheduleError{}
+	assert.ErrorAs(t, err, &scheduleErr)
+	assert.Equal(t, "jitter", scheduleErr.Field)
+
+	j.Jitter = "-1s"
+	_, err = agscheduler.CalcNextRunTime(j)
+	assert.ErrorAs(t, err, &scheduleErr)
+	assert.Equal(t, "jitter", scheduleErr.Field)
+}
@@ -2,32 +2,57 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 
 	"github.com/kurtloong/agscheduler"
 )
 
+// In cluster mode, the nodes follow the paused state of the main node,
+// so the scheduler of a node is paused through `ClusterNode.Pause`, which pauses the entire cluster.
+func pauseScheduler(s *agscheduler.Scheduler) error {
+	if cn := agscheduler.GetClusterNode(s); cn != nil {
+		return cn.Pause()
+	}
+
+	s.Pause()
+	return nil
+}
+
+func resumeScheduler(s *agscheduler.Scheduler) error {
+	if cn := agscheduler.GetClusterNode(s); cn != nil {
+		return cn.Resume()
+	}
+
+	s.Resume()
+	return nil
+}
+
 type ClusterService struct {
 	Cn *agscheduler.ClusterNode
+
+	srservice *SchedulerRPCService
+	crservice *clusterRPCService
+	chservice *clusterHTTPService
 }
 
 func (s *ClusterService) Start() error {
-	srservice := &SchedulerRPCService{Scheduler: s.Cn.Scheduler}
-	srservice.Address = s.Cn.SchedulerEndpoint
-	err := srservice.Start()
+	s.srservice = &SchedulerRPCService{Scheduler: s.Cn.Scheduler}
+	s.srservice.Address = s.Cn.SchedulerEndpoint
+	err := s.srservice.Start()
 	if err != nil {
 		return err
 	}
 
-	crservice := &clusterRPCService{Cn: s.Cn}
-	err = crservice.Start()
+	s.crservice = &clusterRPCService{Cn: s.Cn}
+	err = s.crservice.Start()
 	if err != nil {
 		return err
 	}
 
-	chservice := &clusterHTTPService{Cn: s.Cn}
-	err = chservice.Start()
+	s.chservice = &clusterHTTPService{Cn: s.Cn}
+	err = s.chservice.Start()
 	if err != nil {
 		return err
 	}
@@ -43,3 +68,21 @@ func (s *ClusterService) Start() error {
 
 	return nil
 }
+
+// Shutdown the cluster node gracefully, then close all listeners.
+// Errors are collected, so that every service gets a chance to shutdown.
+func (s *ClusterService) Shutdown(ctx context.Context) error {
+	errs := []error{s.Cn.Shutdown(ctx)}
+
+	if s.srservice != nil {
+		errs = append(errs, s.srservice.Shutdown(ctx))
+	}
+	if s.crservice != nil {
+		errs = append(errs, s.crservice.Shutdown(ctx))
+	}
+	if s.chservice != nil {
+		errs = append(errs, s.chservice.Shutdown(ctx))
+	}
+
+	return errors.Join(errs...)
+}
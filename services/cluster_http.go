@@ -1,8 +1,10 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"net/http"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -14,16 +16,38 @@ type cHTTPService struct {
 	cn *agscheduler.ClusterNode
 }
 
+func (chs *cHTTPService) handleErr(err error) string {
+	if err != nil {
+		return err.Error()
+	} else {
+		return ""
+	}
+}
+
 func (chs *cHTTPService) nodes(c *gin.Context) {
 	c.JSON(200, gin.H{"data": chs.cn.NodeMap(), "error": ""})
 }
 
+func (chs *cHTTPService) pause(c *gin.Context) {
+	err := chs.cn.Pause()
+	c.JSON(200, gin.H{"data": nil, "error": chs.handleErr(err)})
+}
+
+func (chs *cHTTPService) resume(c *gin.Context) {
+	err := chs.cn.Resume()
+	c.JSON(200, gin.H{"data": nil, "error": chs.handleErr(err)})
+}
+
 type clusterHTTPService struct {
 	Cn *agscheduler.ClusterNode
+
+	srv *http.Server
 }
 
 func (s *clusterHTTPService) registerRoutes(r *gin.Engine, shs *cHTTPService) {
 	r.GET("/cluster/nodes", shs.nodes)
+	r.POST("/cluster/pause", shs.pause)
+	r.POST("/cluster/resume", shs.resume)
 }
 
 func (s *clusterHTTPService) Start() error {
@@ -35,11 +59,21 @@ func (s *clusterHTTPService) Start() error {
 
 	slog.Info(fmt.Sprintf("cluster HTTP Service listening at: %s", s.Cn.EndpointHTTP))
 
+	s.srv = &http.Server{Addr: s.Cn.EndpointHTTP, Handler: r}
+
 	go func() {
-		if err := r.Run(s.Cn.EndpointHTTP); err != nil {
+		if err := s.srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			slog.Error(fmt.Sprintf("Cluster HTTP Service Unavailable: %s", err))
 		}
 	}()
 
 	return nil
 }
+
+func (s *clusterHTTPService) Shutdown(ctx context.Context) error {
+	if s.srv == nil {
+		return nil
+	}
+
+	return s.srv.Shutdown(ctx)
+}
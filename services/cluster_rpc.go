@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"encoding/gob"
 	"fmt"
 	"log/slog"
@@ -26,6 +27,26 @@ func (crs *CRPCService) Ping(args *agscheduler.Node, reply *agscheduler.Node) er
 	return nil
 }
 
+func (crs *CRPCService) Deregister(args *agscheduler.Node, reply *agscheduler.Node) error {
+	crs.cn.RPCDeregister(args, reply)
+	return nil
+}
+
+func (crs *CRPCService) Pause(args *agscheduler.Node, reply *agscheduler.Node) error {
+	crs.cn.RPCPause(args, reply)
+	return nil
+}
+
+func (crs *CRPCService) Resume(args *agscheduler.Node, reply *agscheduler.Node) error {
+	crs.cn.RPCResume(args, reply)
+	return nil
+}
+
+func (crs *CRPCService) JobFinished(args *agscheduler.JobFinished, reply *agscheduler.Node) error {
+	crs.cn.RPCJobFinished(args, reply)
+	return nil
+}
+
 func (crs *CRPCService) Nodes(filters map[string]any, reply *map[string]map[string]map[string]any) error {
 	*reply = crs.cn.NodeMap()
 	return nil
@@ -33,22 +54,41 @@ func (crs *CRPCService) Nodes(filters map[string]any, reply *map[string]map[stri
 
 type clusterRPCService struct {
 	Cn *agscheduler.ClusterNode
+
+	srv *http.Server
 }
 
 func (s *clusterRPCService) Start() error {
 	gob.Register(time.Time{})
+	// The results of the runs reported to the main node.
+	gob.Register(map[string]any{})
+	gob.Register([]any{})
 
-	crs := &CRPCService{cn: s.Cn}
-	rpc.Register(crs)
-	rpc.HandleHTTP()
+	// Use its own RPC server instead of the default one,
+	// so that multiple cluster nodes can run in the same process.
+	rs := rpc.NewServer()
+	if err := rs.Register(&CRPCService{cn: s.Cn}); err != nil {
+		return fmt.Errorf("cluster RPC Service register failure: %s", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle(rpc.DefaultRPCPath, rs)
 
 	lis, err := net.Listen("tcp", s.Cn.Endpoint)
 	if err != nil {
 		return fmt.Errorf("cluster RPC Service listen failure: %s", err)
 	}
 
-	go http.Serve(lis, nil)
+	s.srv = &http.Server{Handler: mux}
+	go s.srv.Serve(lis)
 	slog.Info(fmt.Sprintf("Cluster RPC Service listening at: %s", lis.Addr()))
 
 	return nil
 }
+
+func (s *clusterRPCService) Shutdown(ctx context.Context) error {
+	if s.srv == nil {
+		return nil
+	}
+
+	return s.srv.Shutdown(ctx)
+}
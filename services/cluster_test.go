@@ -4,14 +4,19 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"net"
 	"net/http"
 	"net/rpc"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/emptypb"
 
 	"github.com/kurtloong/agscheduler"
+	pb "github.com/kurtloong/agscheduler/services/proto"
 	"github.com/kurtloong/agscheduler/stores"
 )
 
@@ -60,6 +65,15 @@ func TestClusterService(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, rJ.Data.(map[string]any), 2)
 
+	resp, err = http.Post("http://"+cnMain.EndpointHTTP+"/cluster/pause", CONTENT_TYPE, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.True(t, scheduler.IsPaused())
+	resp, err = http.Post("http://"+cnMain.EndpointHTTP+"/cluster/resume", CONTENT_TYPE, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.False(t, scheduler.IsPaused())
+
 	var nodeMap map[string]map[string]map[string]any
 	rClient, err := rpc.DialHTTP("tcp", cnMain.Endpoint)
 	assert.NoError(t, err)
@@ -69,4 +83,141 @@ func TestClusterService(t *testing.T) {
 	assert.Len(t, nodeMap, 2)
 
 	time.Sleep(200 * time.Millisecond)
+
+	err = cn.Shutdown(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, cnMain.NodeMap(), 1)
+
+	err = cservice.Shutdown(ctx)
+	assert.NoError(t, err)
+}
+
+// An ephemeral port chosen by the system,
+// so that the test does not conflict with the services listening on the fixed ports.
+func getFreeAddr(t *testing.T) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+
+	return lis.Addr().String()
+}
+
+func TestClusterServiceMultiple(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cservices := make([]ClusterService, 0)
+	defer func() {
+		for _, cservice := range cservices {
+			err := cservice.Shutdown(ctx)
+			assert.NoError(t, err)
+		}
+	}()
+	for i := 0; i < 2; i++ {
+		endpoint := getFreeAddr(t)
+		cn := &agscheduler.ClusterNode{
+			MainEndpoint:      endpoint,
+			Endpoint:          endpoint,
+			EndpointHTTP:      getFreeAddr(t),
+			SchedulerEndpoint: getFreeAddr(t),
+		}
+		scheduler := &agscheduler.Scheduler{}
+		scheduler.SetFuncRegistry(agscheduler.NewFuncRegistry())
+		err := scheduler.SetStore(&stores.MemoryStore{})
+		assert.NoError(t, err)
+		err = scheduler.SetClusterNode(ctx, cn)
+		assert.NoError(t, err)
+
+		cservice := ClusterService{Cn: cn}
+		err = cservice.Start()
+		// Shut down also when it is started partially.
+		cservices = append(cservices, cservice)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, cservice := range cservices {
+		var nodeMap map[string]map[string]map[string]any
+		rClient, err := rpc.DialHTTP("tcp", cservice.Cn.Endpoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = rClient.Call("CRPCService.Nodes", make(map[string]any), &nodeMap)
+		rClient.Close()
+		assert.NoError(t, err)
+		assert.Len(t, nodeMap, 1)
+		assert.Contains(t, nodeMap["default"], cservice.Cn.Id)
+	}
+
+	cservices[0].Cn.Scheduler.Pause()
+	assert.False(t, cservices[1].Cn.Scheduler.IsPaused())
+}
+
+// Pausing a worker node pauses the entire cluster, so the pings do not undo it.
+func TestClusterServicePauseWorker(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mainEndpoint := getFreeAddr(t)
+	cservices := make([]ClusterService, 0)
+	defer func() {
+		for i := len(cservices) - 1; i >= 0; i-- {
+			err := cservices[i].Shutdown(ctx)
+			assert.NoError(t, err)
+		}
+	}()
+	for i := 0; i < 2; i++ {
+		cn := &agscheduler.ClusterNode{
+			MainEndpoint:      mainEndpoint,
+			Endpoint:          getFreeAddr(t),
+			EndpointHTTP:      getFreeAddr(t),
+			SchedulerEndpoint: getFreeAddr(t),
+		}
+		if i == 0 {
+			cn.Endpoint = mainEndpoint
+		}
+		scheduler := &agscheduler.Scheduler{}
+		err := scheduler.SetStore(&stores.MemoryStore{})
+		assert.NoError(t, err)
+		err = scheduler.SetClusterNode(ctx, cn)
+		assert.NoError(t, err)
+
+		cservice := ClusterService{Cn: cn}
+		err = cservice.Start()
+		cservices = append(cservices, cservice)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	main, worker := cservices[0].Cn.Scheduler, cservices[1].Cn.Scheduler
+
+	conn, err := grpc.Dial(cservices[1].Cn.SchedulerEndpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := pb.NewSchedulerClient(conn)
+
+	_, err = client.Pause(ctx, &emptypb.Empty{})
+	assert.NoError(t, err)
+	// After the pings of the worker node.
+	time.Sleep(500 * time.Millisecond)
+	assert.True(t, main.IsPaused())
+	assert.True(t, worker.IsPaused())
+
+	_, err = client.ResumeCluster(ctx, &emptypb.Empty{})
+	assert.NoError(t, err)
+	time.Sleep(500 * time.Millisecond)
+	assert.False(t, main.IsPaused())
+	assert.False(t, worker.IsPaused())
+
+	_, err = client.PauseCluster(ctx, &emptypb.Empty{})
+	assert.NoError(t, err)
+	assert.True(t, main.IsPaused())
+	_, err = client.Resume(ctx, &emptypb.Empty{})
+	assert.NoError(t, err)
+	assert.False(t, main.IsPaused())
 }
@@ -1,9 +1,13 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -15,11 +19,34 @@ type sHTTPService struct {
 }
 
 func (shs *sHTTPService) handleJob(j agscheduler.Job, err error) gin.H {
+	var h gin.H
 	if j.Id == "" {
-		return gin.H{"data": nil, "error": shs.handleErr(err)}
+		h = gin.H{"data": nil, "error": shs.handleErr(err)}
 	} else {
-		return gin.H{"data": j, "error": shs.handleErr(err)}
+		h = gin.H{"data": j, "error": shs.handleErr(err)}
 	}
+
+	// The invalid fields, so that clients do not parse the error message.
+	if fields := fieldErrors(err); fields != nil {
+		h["details"] = fields
+	}
+
+	return h
+}
+
+// The invalid fields of a job reported by `err`, or nil.
+func fieldErrors(err error) []agscheduler.ArgsFieldError {
+	var argsErr *agscheduler.JobArgsError
+	if errors.As(err, &argsErr) {
+		return argsErr.Fields
+	}
+
+	var scheduleErr *agscheduler.JobScheduleError
+	if errors.As(err, &scheduleErr) {
+		return []agscheduler.ArgsFieldError{{Field: scheduleErr.Field, Description: scheduleErr.Err.Error()}}
+	}
+
+	return nil
 }
 
 func (shs *sHTTPService) handleErr(err error) string {
@@ -96,6 +123,169 @@ func (shs *sHTTPService) runJob(c *gin.Context) {
 	c.JSON(200, gin.H{"data": nil, "error": shs.handleErr(err)})
 }
 
+func (shs *sHTTPService) pause(c *gin.Context) {
+	err := pauseScheduler(shs.scheduler)
+	c.JSON(200, gin.H{"data": nil, "error": shs.handleErr(err)})
+}
+
+func (shs *sHTTPService) resume(c *gin.Context) {
+	err := resumeScheduler(shs.scheduler)
+	c.JSON(200, gin.H{"data": nil, "error": shs.handleErr(err)})
+}
+
+func (shs *sHTTPService) getRecords(c *gin.Context) {
+	c.JSON(200, gin.H{"data": shs.scheduler.GetRecords(c.Param("id")), "error": ""})
+}
+
+func (shs *sHTTPService) getAllRecords(c *gin.Context) {
+	c.JSON(200, gin.H{"data": shs.scheduler.GetRecords(""), "error": ""})
+}
+
+func (shs *sHTTPService) getWorkflowRun(c *gin.Context) {
+	run, err := shs.scheduler.GetWorkflowRun(c.Param("id"))
+	c.JSON(200, gin.H{"data": run, "error": shs.handleErr(err)})
+}
+
+// Query `n`: default `10`
+// Query `from`: RFC 3339, default now
+func (shs *sHTTPService) previewRunTimes(c *gin.Context) {
+	j := agscheduler.Job{}
+	err := c.BindJSON(&j)
+	if err != nil {
+		c.JSON(400, gin.H{"data": nil, "error": shs.handleErr(err)})
+		return
+	}
+
+	n := 10
+	if c.Query("n") != "" {
+		n, err = strconv.Atoi(c.Query("n"))
+		if err != nil {
+			c.JSON(400, gin.H{"data": nil, "error": shs.handleErr(err)})
+			return
+		}
+	}
+	var from time.Time
+	if c.Query("from") != "" {
+		from, err = time.Parse(time.RFC3339, c.Query("from"))
+		if err != nil {
+			c.JSON(400, gin.H{"data": nil, "error": shs.handleErr(err)})
+			return
+		}
+	}
+
+	ts, err := shs.scheduler.PreviewRunTimes(j, n, from)
+	if err != nil {
+		h := gin.H{"data": nil, "error": shs.handleErr(err)}
+		if fields := fieldErrors(err); fields != nil {
+			h["details"] = fields
+		}
+		c.JSON(200, h)
+		return
+	}
+
+	runTimes := make([]gin.H, 0, len(ts))
+	for _, t := range ts {
+		runTimes = append(runTimes, gin.H{"utc": t.UTC(), "local": t.Format(time.RFC3339Nano)})
+	}
+	timezone := j.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	c.JSON(200, gin.H{"data": gin.H{"timezone": timezone, "run_times": runTimes}, "error": ""})
+}
+
+func (shs *sHTTPService) setCalendar(c *gin.Context) {
+	cal := agscheduler.Calendar{}
+	err := c.BindJSON(&cal)
+	if err != nil {
+		c.JSON(400, gin.H{"data": nil, "error": shs.handleErr(err)})
+		return
+	}
+
+	cal, err = shs.scheduler.SetCalendar(cal)
+	c.JSON(200, gin.H{"data": cal, "error": shs.handleErr(err)})
+}
+
+func (shs *sHTTPService) getCalendar(c *gin.Context) {
+	cal, err := shs.scheduler.GetCalendar(c.Param("name"))
+	c.JSON(200, gin.H{"data": cal, "error": shs.handleErr(err)})
+}
+
+func (shs *sHTTPService) getAllCalendars(c *gin.Context) {
+	cs, err := shs.scheduler.GetAllCalendars()
+	c.JSON(200, gin.H{"data": cs, "error": shs.handleErr(err)})
+}
+
+func (shs *sHTTPService) deleteCalendar(c *gin.Context) {
+	err := shs.scheduler.DeleteCalendar(c.Param("name"))
+	c.JSON(200, gin.H{"data": nil, "error": shs.handleErr(err)})
+}
+
+// The body is the content of an ICS file.
+func (shs *sHTTPService) importCalendarICS(c *gin.Context) {
+	cal, err := shs.scheduler.ImportCalendarICS(c.Param("name"), c.Request.Body)
+	c.JSON(200, gin.H{"data": cal, "error": shs.handleErr(err)})
+}
+
+func (shs *sHTTPService) jobSelector(c *gin.Context) agscheduler.JobSelector {
+	return agscheduler.JobSelector{
+		Ids:      c.QueryArray("id"),
+		Names:    c.QueryArray("name"),
+		Queue:    c.Query("queue"),
+		FuncName: c.Query("func_name"),
+		Status:   c.Query("status"),
+		Tags:     c.QueryArray("tag"),
+		All:      c.Query("all") == "true",
+	}
+}
+
+// An empty selector is a bad request.
+func (shs *sHTTPService) bulkResults(c *gin.Context, rs []agscheduler.BulkResult, err error) {
+	if errors.Is(err, agscheduler.ErrEmptyJobSelector) {
+		c.JSON(400, gin.H{"data": nil, "error": shs.handleErr(err)})
+		return
+	}
+
+	c.JSON(200, gin.H{"data": rs, "error": shs.handleErr(err)})
+}
+
+func (shs *sHTTPService) pauseJobs(c *gin.Context) {
+	rs, err := shs.scheduler.PauseJobs(shs.jobSelector(c))
+	shs.bulkResults(c, rs, err)
+}
+
+func (shs *sHTTPService) resumeJobs(c *gin.Context) {
+	rs, err := shs.scheduler.ResumeJobs(shs.jobSelector(c))
+	shs.bulkResults(c, rs, err)
+}
+
+func (shs *sHTTPService) deleteJobs(c *gin.Context) {
+	rs, err := shs.scheduler.DeleteJobs(shs.jobSelector(c))
+	shs.bulkResults(c, rs, err)
+}
+
+func (shs *sHTTPService) runJobsNow(c *gin.Context) {
+	rs, err := shs.scheduler.RunJobsNow(shs.jobSelector(c))
+	shs.bulkResults(c, rs, err)
+}
+
+func (shs *sHTTPService) listFuncs(c *gin.Context) {
+	c.JSON(200, gin.H{"data": shs.scheduler.ListFuncs(), "error": ""})
+}
+
+func (shs *sHTTPService) funcSchema(c *gin.Context) {
+	schema, err := shs.scheduler.FuncSchema(c.Query("func_name"))
+	c.JSON(200, gin.H{"data": schema, "error": shs.handleErr(err)})
+}
+
+func (shs *sHTTPService) poolStats(c *gin.Context) {
+	c.JSON(200, gin.H{"data": shs.scheduler.PoolStats(), "error": ""})
+}
+
+func (shs *sHTTPService) throttleStats(c *gin.Context) {
+	c.JSON(200, gin.H{"data": shs.scheduler.ThrottleStats(), "error": ""})
+}
+
 func (shs *sHTTPService) start(c *gin.Context) {
 	shs.scheduler.Start()
 	c.JSON(200, gin.H{"data": nil, "error": ""})
@@ -120,6 +310,8 @@ type SchedulerHTTPService struct {
 	ExtraRoutes  []func(r *gin.Engine) // New field for additional routes
 	HTMLRoutes   []HTMLRoute           // New field for HTML routes
 	HTMLGlobPath string                // New field for HTML glob path
+
+	srv *http.Server
 }
 
 func (s *SchedulerHTTPService) registerRoutes(r *gin.Engine, shs *sHTTPService) {
@@ -132,8 +324,27 @@ func (s *SchedulerHTTPService) registerRoutes(r *gin.Engine, shs *sHTTPService)
 	r.POST("/scheduler/job/:id/pause", shs.pauseJob)
 	r.POST("/scheduler/job/:id/resume", shs.resumeJob)
 	r.POST("/scheduler/job/run", shs.runJob)
+	r.GET("/scheduler/job/:id/records", shs.getRecords)
+	r.GET("/scheduler/records", shs.getAllRecords)
+	r.GET("/scheduler/workflow/:id", shs.getWorkflowRun)
+	r.POST("/scheduler/job/preview", shs.previewRunTimes)
+	r.POST("/scheduler/calendar", shs.setCalendar)
+	r.GET("/scheduler/calendar/:name", shs.getCalendar)
+	r.GET("/scheduler/calendars", shs.getAllCalendars)
+	r.DELETE("/scheduler/calendar/:name", shs.deleteCalendar)
+	r.POST("/scheduler/calendar/:name/ics", shs.importCalendarICS)
+	r.POST("/scheduler/jobs/pause", shs.pauseJobs)
+	r.POST("/scheduler/jobs/resume", shs.resumeJobs)
+	r.POST("/scheduler/jobs/delete", shs.deleteJobs)
+	r.POST("/scheduler/jobs/run", shs.runJobsNow)
+	r.GET("/scheduler/funcs", shs.listFuncs)
+	r.GET("/scheduler/func/schema", shs.funcSchema)
+	r.GET("/scheduler/pool", shs.poolStats)
+	r.GET("/scheduler/throttle", shs.throttleStats)
 	r.POST("/scheduler/start", shs.start)
 	r.POST("/scheduler/stop", shs.stop)
+	r.POST("/scheduler/pause", shs.pause)
+	r.POST("/scheduler/resume", shs.resume)
 }
 
 // New method to add static paths
@@ -188,8 +399,10 @@ func (s *SchedulerHTTPService) Start() error {
 		s.serveHTML(r, route.URLPath, route.HTMLFile)
 	}
 
+	s.srv = &http.Server{Addr: s.Address, Handler: r}
+
 	go func() {
-		if err := r.Run(s.Address); err != nil {
+		if err := s.srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			slog.Error(fmt.Sprintf("Scheduler HTTP Service Unavailable: %s", err))
 		}
 	}()
@@ -197,6 +410,17 @@ func (s *SchedulerHTTPService) Start() error {
 	return nil
 }
 
+// Close the listener and wait for the active requests until `ctx` is done.
+func (s *SchedulerHTTPService) Shutdown(ctx context.Context) error {
+	if s.srv == nil {
+		return nil
+	}
+
+	slog.Info(fmt.Sprintf("Scheduler HTTP Service shutdown: %s", s.Address))
+
+	return s.srv.Shutdown(ctx)
+}
+
 // Helper method to serve an HTML file
 func (s *SchedulerHTTPService) serveHTML(r *gin.Engine, urlPath, htmlFile string) {
 	r.GET(urlPath, func(c *gin.Context) {
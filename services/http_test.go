@@ -7,6 +7,7 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
@@ -36,6 +37,336 @@ func panicRunHTTP(ctx context.Context, j agscheduler.Job) {
 	panic("panic")
 }
 
+type typedArgsHTTP struct {
+	Url   string `json:"url"`
+	Times int    `json:"times,omitempty"`
+}
+
+func typedRunHTTP(ctx context.Context, j agscheduler.Job, args typedArgsHTTP) error { return nil }
+
+func testTypedHTTP(t *testing.T, baseUrl string) {
+	mJ := map[string]any{
+		"name":      "Job",
+		"type":      agscheduler.TYPE_INTERVAL,
+		"interval":  "1s",
+		"func_name": "typedRunHTTP",
+		"args":      map[string]any{"times": "1"},
+	}
+	bJ, err := json.Marshal(mJ)
+	assert.NoError(t, err)
+	resp, err := http.Post(baseUrl+"/scheduler/job", CONTENT_TYPE, bytes.NewReader(bJ))
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	rE := &struct {
+		result
+		Details []agscheduler.ArgsFieldError `json:"details"`
+	}{}
+	err = json.Unmarshal(body, &rE)
+	assert.NoError(t, err)
+	assert.Nil(t, rE.Data)
+	assert.NotEmpty(t, rE.Error)
+	assert.ElementsMatch(t, []agscheduler.ArgsFieldError{
+		{Field: "times", Description: "must be integer, not string"},
+		{Field: "url", Description: "is required"},
+	}, rE.Details)
+
+	mJ["args"] = map[string]any{"url": "http://127.0.0.1", "times": 1}
+	bJ, err = json.Marshal(mJ)
+	assert.NoError(t, err)
+	resp, err = http.Post(baseUrl+"/scheduler/job", CONTENT_TYPE, bytes.NewReader(bJ))
+	assert.NoError(t, err)
+	body, err = io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	rJ := &result{}
+	err = json.Unmarshal(body, &rJ)
+	assert.NoError(t, err)
+	assert.Empty(t, rJ.Error)
+
+	resp, err = http.Get(baseUrl + "/scheduler/func/schema?func_name=typedRunHTTP")
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	body, err = io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	rJ = &result{}
+	err = json.Unmarshal(body, &rJ)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"url"}, rJ.Data.(map[string]any)["required"])
+
+	resp, err = http.Get(baseUrl + "/scheduler/funcs")
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	body, err = io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	rFs := &struct {
+		Data  []agscheduler.FuncInfo `json:"data"`
+		Error string                 `json:"error"`
+	}{}
+	err = json.Unmarshal(body, &rFs)
+	assert.NoError(t, err)
+	names := make([]string, 0)
+	for _, info := range rFs.Data {
+		names = append(names, info.Name)
+		if info.Name == "typedRunHTTP" {
+			assert.Len(t, info.Args, 2)
+		}
+	}
+	assert.Contains(t, names, "typedRunHTTP")
+	assert.Contains(t, names, "github.com/kurtloong/agscheduler/services.dryRunHTTP")
+
+	resp, err = http.Get(baseUrl + "/scheduler/func/schema?func_name=unknown")
+	assert.NoError(t, err)
+	body, err = io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	rJ = &result{}
+	err = json.Unmarshal(body, &rJ)
+	assert.NoError(t, err)
+	assert.Equal(t, agscheduler.FuncUnregisteredError("unknown").Error(), rJ.Error)
+}
+
+func testRecordsHTTP(t *testing.T, baseUrl string) {
+	mJ := map[string]any{
+		"id":        "shell",
+		"name":      "Job",
+		"type":      agscheduler.TYPE_INTERVAL,
+		"interval":  "1s",
+		"timeout":   "1s",
+		"func_name": agscheduler.FUNC_SHELL,
+		"args":      map[string]any{"argv": []string{"echo", "shell"}},
+	}
+	bJ, err := json.Marshal(mJ)
+	assert.NoError(t, err)
+	resp, err := http.Post(baseUrl+"/scheduler/job/run", CONTENT_TYPE, bytes.NewReader(bJ))
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	time.Sleep(200 * time.Millisecond)
+
+	resp, err = http.Get(baseUrl + "/scheduler/job/shell/records")
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	rRs := &struct {
+		Data  []agscheduler.Record `json:"data"`
+		Error string               `json:"error"`
+	}{}
+	err = json.Unmarshal(body, &rRs)
+	assert.NoError(t, err)
+	assert.Len(t, rRs.Data, 1)
+	assert.Equal(t, agscheduler.RECORD_SUCCEEDED, rRs.Data[0].Status)
+	assert.Equal(t, "shell\n", rRs.Data[0].Result["stdout"])
+	assert.Equal(t, float64(0), rRs.Data[0].Result["exit_code"])
+
+	resp, err = http.Get(baseUrl + "/scheduler/records")
+	assert.NoError(t, err)
+	body, err = io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	err = json.Unmarshal(body, &rRs)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, rRs.Data)
+
+	resp, err = http.Get(baseUrl + "/scheduler/workflow/unknown")
+	assert.NoError(t, err)
+	body, err = io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	rR := &result{}
+	err = json.Unmarshal(body, &rR)
+	assert.NoError(t, err)
+	assert.Equal(t, agscheduler.WorkflowRunNotFoundError("unknown").Error(), rR.Error)
+
+	resp, err = http.Get(baseUrl + "/scheduler/pool")
+	assert.NoError(t, err)
+	body, err = io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	rP := &struct {
+		Data  agscheduler.PoolStats `json:"data"`
+		Error string                `json:"error"`
+	}{}
+	err = json.Unmarshal(body, &rP)
+	assert.NoError(t, err)
+	assert.Equal(t, agscheduler.PoolStats{}, rP.Data)
+
+	resp, err = http.Get(baseUrl + "/scheduler/throttle")
+	assert.NoError(t, err)
+	body, err = io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	rT := &struct {
+		Data  map[string]agscheduler.ThrottleStats `json:"data"`
+		Error string                               `json:"error"`
+	}{}
+	err = json.Unmarshal(body, &rT)
+	assert.NoError(t, err)
+	assert.Empty(t, rT.Data)
+}
+
+func testPreviewHTTP(t *testing.T, baseUrl string) {
+	mJ := map[string]any{
+		"name":      "Job",
+		"type":      agscheduler.TYPE_CRON,
+		"cron_expr": "0 9 * * *",
+		"timezone":  "Asia/Shanghai",
+	}
+	bJ, err := json.Marshal(mJ)
+	assert.NoError(t, err)
+	resp, err := http.Post(baseUrl+"/scheduler/job/preview?n=2&from=2024-01-01T00:00:00Z", CONTENT_TYPE, bytes.NewReader(bJ))
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	rP := &struct {
+		Data struct {
+			Timezone string `json:"timezone"`
+			RunTimes []struct {
+				UTC   time.Time `json:"utc"`
+				Local string    `json:"local"`
+			} `json:"run_times"`
+		} `json:"data"`
+		Error string `json:"error"`
+	}{}
+	err = json.Unmarshal(body, &rP)
+	assert.NoError(t, err)
+	assert.Empty(t, rP.Error)
+	assert.Equal(t, "Asia/Shanghai", rP.Data.Timezone)
+	assert.Len(t, rP.Data.RunTimes, 2)
+	assert.Equal(t, time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC), rP.Data.RunTimes[0].UTC)
+	assert.Equal(t, "2024-01-02T09:00:00+08:00", rP.Data.RunTimes[1].Local)
+
+	mJR := map[string]any{
+		"name":     "Job",
+		"type":     agscheduler.TYPE_RRULE,
+		"rrule":    "DTSTART:20240101T090000\nRRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR;COUNT=3",
+		"timezone": "Asia/Shanghai",
+	}
+	bJR, err := json.Marshal(mJR)
+	assert.NoError(t, err)
+	resp, err = http.Post(baseUrl+"/scheduler/job/preview?n=5&from=2024-01-01T00:00:00Z", CONTENT_TYPE, bytes.NewReader(bJR))
+	assert.NoError(t, err)
+	body, err = io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	err = json.Unmarshal(body, &rP)
+	assert.NoError(t, err)
+	assert.Empty(t, rP.Error)
+	assert.Len(t, rP.Data.RunTimes, 3)
+	assert.Equal(t, "2024-01-15T09:00:00+08:00", rP.Data.RunTimes[2].Local)
+
+	mJC := map[string]any{
+		"name":     "Job",
+		"type":     agscheduler.TYPE_COMBINED,
+		"operator": agscheduler.OPERATOR_AND,
+		"triggers": []map[string]any{
+			{"type": agscheduler.TYPE_CRON, "cron_expr": "0 9 * * *"},
+			{
+				"type": agscheduler.TYPE_COMBINED, "operator": agscheduler.OPERATOR_OR,
+				"triggers": []map[string]any{
+					{"type": agscheduler.TYPE_DATETIME, "start_at": "2024-01-02 00:00:00", "end_at": "2024-01-02 23:59:59"},
+					{"type": agscheduler.TYPE_DATETIME, "start_at": "2024-01-05 00:00:00", "end_at": "2024-01-05 23:59:59"},
+				},
+			},
+		},
+		"timezone": "Asia/Shanghai",
+	}
+	bJC, err := json.Marshal(mJC)
+	assert.NoError(t, err)
+	resp, err = http.Post(baseUrl+"/scheduler/job/preview?n=5&from=2024-01-01T00:00:00Z", CONTENT_TYPE, bytes.NewReader(bJC))
+	assert.NoError(t, err)
+	body, err = io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	err = json.Unmarshal(body, &rP)
+	assert.NoError(t, err)
+	assert.Empty(t, rP.Error)
+	assert.Len(t, rP.Data.RunTimes, 2)
+	assert.Equal(t, "2024-01-05T09:00:00+08:00", rP.Data.RunTimes[1].Local)
+
+	mJ["cron_expr"] = "* * *"
+	bJ, err = json.Marshal(mJ)
+	assert.NoError(t, err)
+	resp, err = http.Post(baseUrl+"/scheduler/job/preview", CONTENT_TYPE, bytes.NewReader(bJ))
+	assert.NoError(t, err)
+	body, err = io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	rE := &struct {
+		result
+		Details []agscheduler.ArgsFieldError `json:"details"`
+	}{}
+	err = json.Unmarshal(body, &rE)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, rE.Error)
+	assert.Len(t, rE.Details, 1)
+	assert.Equal(t, "cron_expr", rE.Details[0].Field)
+
+	resp, err = http.Post(baseUrl+"/scheduler/job/preview?n=x", CONTENT_TYPE, bytes.NewReader(bJ))
+	assert.NoError(t, err)
+	assert.Equal(t, 400, resp.StatusCode)
+}
+
+func testCalendarHTTP(t *testing.T, baseUrl string) {
+	client := &http.Client{}
+
+	bC, err := json.Marshal(agscheduler.Calendar{Name: "holidays", Dates: []string{"2024-01-01"}})
+	assert.NoError(t, err)
+	resp, err := http.Post(baseUrl+"/scheduler/calendar", CONTENT_TYPE, bytes.NewReader(bC))
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	ics := "BEGIN:VEVENT\nDTSTART;VALUE=DATE:20241225\nRRULE:FREQ=YEARLY\nEND:VEVENT\n"
+	resp, err = http.Post(baseUrl+"/scheduler/calendar/christmas/ics", "text/calendar", strings.NewReader(ics))
+	assert.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	rC := &struct {
+		Data  agscheduler.Calendar `json:"data"`
+		Error string               `json:"error"`
+	}{}
+	err = json.Unmarshal(body, &rC)
+	assert.NoError(t, err)
+	assert.Empty(t, rC.Error)
+	assert.Equal(t, []agscheduler.CalendarRule{{Months: []int{12}, Days: []int{25}}}, rC.Data.Rules)
+
+	resp, err = http.Get(baseUrl + "/scheduler/calendar/holidays")
+	assert.NoError(t, err)
+	body, err = io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	err = json.Unmarshal(body, &rC)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2024-01-01"}, rC.Data.Dates)
+
+	resp, err = http.Get(baseUrl + "/scheduler/calendars")
+	assert.NoError(t, err)
+	body, err = io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	rCs := &struct {
+		Data  []agscheduler.Calendar `json:"data"`
+		Error string                 `json:"error"`
+	}{}
+	err = json.Unmarshal(body, &rCs)
+	assert.NoError(t, err)
+	assert.Len(t, rCs.Data, 2)
+
+	for _, name := range []string{"holidays", "christmas"} {
+		req, err := http.NewRequest(http.MethodDelete, baseUrl+"/scheduler/calendar/"+name, nil)
+		assert.NoError(t, err)
+		resp, err = client.Do(req)
+		assert.NoError(t, err)
+		body, err = io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		rR := &result{}
+		err = json.Unmarshal(body, &rR)
+		assert.NoError(t, err)
+		assert.Empty(t, rR.Error)
+	}
+
+	resp, err = http.Get(baseUrl + "/scheduler/calendar/holidays")
+	assert.NoError(t, err)
+	body, err = io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	rR := &result{}
+	err = json.Unmarshal(body, &rR)
+	assert.NoError(t, err)
+	assert.Equal(t, agscheduler.CalendarNotFoundError("holidays").Error(), rR.Error)
+}
+
 func testAGSchedulerHTTP(t *testing.T, baseUrl string) {
 	client := &http.Client{}
 
@@ -112,6 +443,41 @@ func testAGSchedulerHTTP(t *testing.T, baseUrl string) {
 	assert.NoError(t, err)
 	assert.NotEqual(t, nextRunTimeMax.Unix(), nextRunTime.Unix())
 
+	resp, err = http.Post(baseUrl+"/scheduler/jobs/pause?id="+id+"&name=Job", CONTENT_TYPE, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	body, err = io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	rJs := &result{}
+	err = json.Unmarshal(body, &rJs)
+	assert.NoError(t, err)
+	assert.Len(t, rJs.Data.([]any), 1)
+	assert.Equal(t, id, rJs.Data.([]any)[0].(map[string]any)["id"].(string))
+
+	resp, err = http.Post(baseUrl+"/scheduler/jobs/resume?status="+agscheduler.STATUS_PAUSED, CONTENT_TYPE, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	body, err = io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	rJs = &result{}
+	err = json.Unmarshal(body, &rJs)
+	assert.NoError(t, err)
+	assert.Len(t, rJs.Data.([]any), 1)
+
+	resp, err = http.Post(baseUrl+"/scheduler/jobs/delete", CONTENT_TYPE, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 400, resp.StatusCode)
+
+	resp, err = http.Post(baseUrl+"/scheduler/jobs/run?tag=other", CONTENT_TYPE, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	body, err = io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	rJs = &result{}
+	err = json.Unmarshal(body, &rJs)
+	assert.NoError(t, err)
+	assert.Empty(t, rJs.Data)
+
 	bJ, err = json.Marshal(rJ.Data.(map[string]any))
 	assert.NoError(t, err)
 	resp, err = http.Post(baseUrl+"/scheduler/job/run", CONTENT_TYPE, bytes.NewReader(bJ))
@@ -145,11 +511,18 @@ func testAGSchedulerHTTP(t *testing.T, baseUrl string) {
 	assert.Equal(t, 200, resp.StatusCode)
 	body, err = io.ReadAll(resp.Body)
 	assert.NoError(t, err)
-	rJs := &result{}
+	rJs = &result{}
 	err = json.Unmarshal(body, &rJs)
 	assert.NoError(t, err)
 	assert.Empty(t, rJs.Data)
 
+	resp, err = http.Post(baseUrl+"/scheduler/pause", CONTENT_TYPE, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	resp, err = http.Post(baseUrl+"/scheduler/resume", CONTENT_TYPE, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
 	_, err = http.Post(baseUrl+"/scheduler/stop", CONTENT_TYPE, nil)
 	assert.NoError(t, err)
 }
@@ -158,6 +531,8 @@ func TestHTTPService(t *testing.T) {
 	agscheduler.RegisterFuncs(dryRunHTTP)
 	agscheduler.RegisterFuncs(errorRunHTTP)
 	agscheduler.RegisterFuncs(panicRunHTTP)
+	agscheduler.RegisterTyped("typedRunHTTP", typedRunHTTP)
+	agscheduler.RegisterShell()
 
 	store := &stores.MemoryStore{}
 
@@ -176,6 +551,15 @@ func TestHTTPService(t *testing.T) {
 	baseUrl := "http://" + shservice.Address
 
 	testAGSchedulerHTTP(t, baseUrl)
+	testTypedHTTP(t, baseUrl)
+	testRecordsHTTP(t, baseUrl)
+	testPreviewHTTP(t, baseUrl)
+	testCalendarHTTP(t, baseUrl)
+
+	err := shservice.Shutdown(ctx)
+	assert.NoError(t, err)
+	_, err = http.Get(baseUrl + "/scheduler/jobs")
+	assert.Error(t, err)
 
 	store.Clear()
 }
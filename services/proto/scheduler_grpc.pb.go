@@ -12,6 +12,7 @@ import (
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
 	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	structpb "google.golang.org/protobuf/types/known/structpb"
 )
 
 // This is a compile-time assertion to ensure that this generated file
@@ -20,17 +21,35 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	Scheduler_AddJob_FullMethodName        = "/scheduler.Scheduler/AddJob"
-	Scheduler_GetJob_FullMethodName        = "/scheduler.Scheduler/GetJob"
-	Scheduler_GetAllJobs_FullMethodName    = "/scheduler.Scheduler/GetAllJobs"
-	Scheduler_UpdateJob_FullMethodName     = "/scheduler.Scheduler/UpdateJob"
-	Scheduler_DeleteJob_FullMethodName     = "/scheduler.Scheduler/DeleteJob"
-	Scheduler_DeleteAllJobs_FullMethodName = "/scheduler.Scheduler/DeleteAllJobs"
-	Scheduler_PauseJob_FullMethodName      = "/scheduler.Scheduler/PauseJob"
-	Scheduler_ResumeJob_FullMethodName     = "/scheduler.Scheduler/ResumeJob"
-	Scheduler_RunJob_FullMethodName        = "/scheduler.Scheduler/RunJob"
-	Scheduler_Start_FullMethodName         = "/scheduler.Scheduler/Start"
-	Scheduler_Stop_FullMethodName          = "/scheduler.Scheduler/Stop"
+	Scheduler_AddJob_FullMethodName            = "/scheduler.Scheduler/AddJob"
+	Scheduler_GetJob_FullMethodName            = "/scheduler.Scheduler/GetJob"
+	Scheduler_GetAllJobs_FullMethodName        = "/scheduler.Scheduler/GetAllJobs"
+	Scheduler_UpdateJob_FullMethodName         = "/scheduler.Scheduler/UpdateJob"
+	Scheduler_DeleteJob_FullMethodName         = "/scheduler.Scheduler/DeleteJob"
+	Scheduler_DeleteAllJobs_FullMethodName     = "/scheduler.Scheduler/DeleteAllJobs"
+	Scheduler_PauseJob_FullMethodName          = "/scheduler.Scheduler/PauseJob"
+	Scheduler_ResumeJob_FullMethodName         = "/scheduler.Scheduler/ResumeJob"
+	Scheduler_RunJob_FullMethodName            = "/scheduler.Scheduler/RunJob"
+	Scheduler_GetRecords_FullMethodName        = "/scheduler.Scheduler/GetRecords"
+	Scheduler_GetWorkflowRun_FullMethodName    = "/scheduler.Scheduler/GetWorkflowRun"
+	Scheduler_PreviewRunTimes_FullMethodName   = "/scheduler.Scheduler/PreviewRunTimes"
+	Scheduler_SetCalendar_FullMethodName       = "/scheduler.Scheduler/SetCalendar"
+	Scheduler_GetCalendar_FullMethodName       = "/scheduler.Scheduler/GetCalendar"
+	Scheduler_GetAllCalendars_FullMethodName   = "/scheduler.Scheduler/GetAllCalendars"
+	Scheduler_DeleteCalendar_FullMethodName    = "/scheduler.Scheduler/DeleteCalendar"
+	Scheduler_ImportCalendarICS_FullMethodName = "/scheduler.Scheduler/ImportCalendarICS"
+	Scheduler_PauseJobs_FullMethodName         = "/scheduler.Scheduler/PauseJobs"
+	Scheduler_ResumeJobs_FullMethodName        = "/scheduler.Scheduler/ResumeJobs"
+	Scheduler_DeleteJobs_FullMethodName        = "/scheduler.Scheduler/DeleteJobs"
+	Scheduler_RunJobsNow_FullMethodName        = "/scheduler.Scheduler/RunJobsNow"
+	Scheduler_GetFuncSchema_FullMethodName     = "/scheduler.Scheduler/GetFuncSchema"
+	Scheduler_ListFuncs_FullMethodName         = "/scheduler.Scheduler/ListFuncs"
+	Scheduler_Start_FullMethodName             = "/scheduler.Scheduler/Start"
+	Scheduler_Stop_FullMethodName              = "/scheduler.Scheduler/Stop"
+	Scheduler_Pause_FullMethodName             = "/scheduler.Scheduler/Pause"
+	Scheduler_Resume_FullMethodName            = "/scheduler.Scheduler/Resume"
+	Scheduler_PauseCluster_FullMethodName      = "/scheduler.Scheduler/PauseCluster"
+	Scheduler_ResumeCluster_FullMethodName     = "/scheduler.Scheduler/ResumeCluster"
 )
 
 // SchedulerClient is the client API for Scheduler service.
@@ -46,8 +65,26 @@ type SchedulerClient interface {
 	PauseJob(ctx context.Context, in *JobId, opts ...grpc.CallOption) (*Job, error)
 	ResumeJob(ctx context.Context, in *JobId, opts ...grpc.CallOption) (*Job, error)
 	RunJob(ctx context.Context, in *Job, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	GetRecords(ctx context.Context, in *JobId, opts ...grpc.CallOption) (*Records, error)
+	GetWorkflowRun(ctx context.Context, in *WorkflowRunId, opts ...grpc.CallOption) (*WorkflowRun, error)
+	PreviewRunTimes(ctx context.Context, in *PreviewRequest, opts ...grpc.CallOption) (*RunTimes, error)
+	SetCalendar(ctx context.Context, in *Calendar, opts ...grpc.CallOption) (*Calendar, error)
+	GetCalendar(ctx context.Context, in *CalendarName, opts ...grpc.CallOption) (*Calendar, error)
+	GetAllCalendars(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*Calendars, error)
+	DeleteCalendar(ctx context.Context, in *CalendarName, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ImportCalendarICS(ctx context.Context, in *CalendarICS, opts ...grpc.CallOption) (*Calendar, error)
+	PauseJobs(ctx context.Context, in *JobSelector, opts ...grpc.CallOption) (*BulkResults, error)
+	ResumeJobs(ctx context.Context, in *JobSelector, opts ...grpc.CallOption) (*BulkResults, error)
+	DeleteJobs(ctx context.Context, in *JobSelector, opts ...grpc.CallOption) (*BulkResults, error)
+	RunJobsNow(ctx context.Context, in *JobSelector, opts ...grpc.CallOption) (*BulkResults, error)
+	GetFuncSchema(ctx context.Context, in *FuncName, opts ...grpc.CallOption) (*structpb.Struct, error)
+	ListFuncs(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*Funcs, error)
 	Start(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	Stop(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	Pause(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	Resume(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	PauseCluster(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ResumeCluster(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
 }
 
 type schedulerClient struct {
@@ -139,6 +176,132 @@ func (c *schedulerClient) RunJob(ctx context.Context, in *Job, opts ...grpc.Call
 	return out, nil
 }
 
+func (c *schedulerClient) GetRecords(ctx context.Context, in *JobId, opts ...grpc.CallOption) (*Records, error) {
+	out := new(Records)
+	err := c.cc.Invoke(ctx, Scheduler_GetRecords_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *schedulerClient) GetWorkflowRun(ctx context.Context, in *WorkflowRunId, opts ...grpc.CallOption) (*WorkflowRun, error) {
+	out := new(WorkflowRun)
+	err := c.cc.Invoke(ctx, Scheduler_GetWorkflowRun_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *schedulerClient) PreviewRunTimes(ctx context.Context, in *PreviewRequest, opts ...grpc.CallOption) (*RunTimes, error) {
+	out := new(RunTimes)
+	err := c.cc.Invoke(ctx, Scheduler_PreviewRunTimes_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *schedulerClient) SetCalendar(ctx context.Context, in *Calendar, opts ...grpc.CallOption) (*Calendar, error) {
+	out := new(Calendar)
+	err := c.cc.Invoke(ctx, Scheduler_SetCalendar_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *schedulerClient) GetCalendar(ctx context.Context, in *CalendarName, opts ...grpc.CallOption) (*Calendar, error) {
+	out := new(Calendar)
+	err := c.cc.Invoke(ctx, Scheduler_GetCalendar_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *schedulerClient) GetAllCalendars(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*Calendars, error) {
+	out := new(Calendars)
+	err := c.cc.Invoke(ctx, Scheduler_GetAllCalendars_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *schedulerClient) DeleteCalendar(ctx context.Context, in *CalendarName, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Scheduler_DeleteCalendar_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *schedulerClient) ImportCalendarICS(ctx context.Context, in *CalendarICS, opts ...grpc.CallOption) (*Calendar, error) {
+	out := new(Calendar)
+	err := c.cc.Invoke(ctx, Scheduler_ImportCalendarICS_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *schedulerClient) PauseJobs(ctx context.Context, in *JobSelector, opts ...grpc.CallOption) (*BulkResults, error) {
+	out := new(BulkResults)
+	err := c.cc.Invoke(ctx, Scheduler_PauseJobs_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *schedulerClient) ResumeJobs(ctx context.Context, in *JobSelector, opts ...grpc.CallOption) (*BulkResults, error) {
+	out := new(BulkResults)
+	err := c.cc.Invoke(ctx, Scheduler_ResumeJobs_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *schedulerClient) DeleteJobs(ctx context.Context, in *JobSelector, opts ...grpc.CallOption) (*BulkResults, error) {
+	out := new(BulkResults)
+	err := c.cc.Invoke(ctx, Scheduler_DeleteJobs_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *schedulerClient) RunJobsNow(ctx context.Context, in *JobSelector, opts ...grpc.CallOption) (*BulkResults, error) {
+	out := new(BulkResults)
+	err := c.cc.Invoke(ctx, Scheduler_RunJobsNow_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *schedulerClient) GetFuncSchema(ctx context.Context, in *FuncName, opts ...grpc.CallOption) (*structpb.Struct, error) {
+	out := new(structpb.Struct)
+	err := c.cc.Invoke(ctx, Scheduler_GetFuncSchema_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *schedulerClient) ListFuncs(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*Funcs, error) {
+	out := new(Funcs)
+	err := c.cc.Invoke(ctx, Scheduler_ListFuncs_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *schedulerClient) Start(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	out := new(emptypb.Empty)
 	err := c.cc.Invoke(ctx, Scheduler_Start_FullMethodName, in, out, opts...)
@@ -157,6 +320,42 @@ func (c *schedulerClient) Stop(ctx context.Context, in *emptypb.Empty, opts ...g
 	return out, nil
 }
 
+func (c *schedulerClient) Pause(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Scheduler_Pause_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *schedulerClient) Resume(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Scheduler_Resume_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *schedulerClient) PauseCluster(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Scheduler_PauseCluster_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *schedulerClient) ResumeCluster(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Scheduler_ResumeCluster_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SchedulerServer is the server API for Scheduler service.
 // All implementations must embed UnimplementedSchedulerServer
 // for forward compatibility
@@ -170,8 +369,26 @@ type SchedulerServer interface {
 	PauseJob(context.Context, *JobId) (*Job, error)
 	ResumeJob(context.Context, *JobId) (*Job, error)
 	RunJob(context.Context, *Job) (*emptypb.Empty, error)
+	GetRecords(context.Context, *JobId) (*Records, error)
+	GetWorkflowRun(context.Context, *WorkflowRunId) (*WorkflowRun, error)
+	PreviewRunTimes(context.Context, *PreviewRequest) (*RunTimes, error)
+	SetCalendar(context.Context, *Calendar) (*Calendar, error)
+	GetCalendar(context.Context, *CalendarName) (*Calendar, error)
+	GetAllCalendars(context.Context, *emptypb.Empty) (*Calendars, error)
+	DeleteCalendar(context.Context, *CalendarName) (*emptypb.Empty, error)
+	ImportCalendarICS(context.Context, *CalendarICS) (*Calendar, error)
+	PauseJobs(context.Context, *JobSelector) (*BulkResults, error)
+	ResumeJobs(context.Context, *JobSelector) (*BulkResults, error)
+	DeleteJobs(context.Context, *JobSelector) (*BulkResults, error)
+	RunJobsNow(context.Context, *JobSelector) (*BulkResults, error)
+	GetFuncSchema(context.Context, *FuncName) (*structpb.Struct, error)
+	ListFuncs(context.Context, *emptypb.Empty) (*Funcs, error)
 	Start(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
 	Stop(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
+	Pause(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
+	Resume(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
+	PauseCluster(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
+	ResumeCluster(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
 	mustEmbedUnimplementedSchedulerServer()
 }
 
@@ -206,12 +423,66 @@ func (UnimplementedSchedulerServer) ResumeJob(context.Context, *JobId) (*Job, er
 func (UnimplementedSchedulerServer) RunJob(context.Context, *Job) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RunJob not implemented")
 }
+func (UnimplementedSchedulerServer) GetRecords(context.Context, *JobId) (*Records, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRecords not implemented")
+}
+func (UnimplementedSchedulerServer) GetWorkflowRun(context.Context, *WorkflowRunId) (*WorkflowRun, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWorkflowRun not implemented")
+}
+func (UnimplementedSchedulerServer) PreviewRunTimes(context.Context, *PreviewRequest) (*RunTimes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PreviewRunTimes not implemented")
+}
+func (UnimplementedSchedulerServer) SetCalendar(context.Context, *Calendar) (*Calendar, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetCalendar not implemented")
+}
+func (UnimplementedSchedulerServer) GetCalendar(context.Context, *CalendarName) (*Calendar, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCalendar not implemented")
+}
+func (UnimplementedSchedulerServer) GetAllCalendars(context.Context, *emptypb.Empty) (*Calendars, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAllCalendars not implemented")
+}
+func (UnimplementedSchedulerServer) DeleteCalendar(context.Context, *CalendarName) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteCalendar not implemented")
+}
+func (UnimplementedSchedulerServer) ImportCalendarICS(context.Context, *CalendarICS) (*Calendar, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportCalendarICS not implemented")
+}
+func (UnimplementedSchedulerServer) PauseJobs(context.Context, *JobSelector) (*BulkResults, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PauseJobs not implemented")
+}
+func (UnimplementedSchedulerServer) ResumeJobs(context.Context, *JobSelector) (*BulkResults, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumeJobs not implemented")
+}
+func (UnimplementedSchedulerServer) DeleteJobs(context.Context, *JobSelector) (*BulkResults, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteJobs not implemented")
+}
+func (UnimplementedSchedulerServer) RunJobsNow(context.Context, *JobSelector) (*BulkResults, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RunJobsNow not implemented")
+}
+func (UnimplementedSchedulerServer) GetFuncSchema(context.Context, *FuncName) (*structpb.Struct, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFuncSchema not implemented")
+}
+func (UnimplementedSchedulerServer) ListFuncs(context.Context, *emptypb.Empty) (*Funcs, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFuncs not implemented")
+}
 func (UnimplementedSchedulerServer) Start(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Start not implemented")
 }
 func (UnimplementedSchedulerServer) Stop(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Stop not implemented")
 }
+func (UnimplementedSchedulerServer) Pause(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Pause not implemented")
+}
+func (UnimplementedSchedulerServer) Resume(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Resume not implemented")
+}
+func (UnimplementedSchedulerServer) PauseCluster(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PauseCluster not implemented")
+}
+func (UnimplementedSchedulerServer) ResumeCluster(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumeCluster not implemented")
+}
 func (UnimplementedSchedulerServer) mustEmbedUnimplementedSchedulerServer() {}
 
 // UnsafeSchedulerServer may be embedded to opt out of forward compatibility for this service.
@@ -387,6 +658,258 @@ func _Scheduler_RunJob_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Scheduler_GetRecords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobId)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SchedulerServer).GetRecords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Scheduler_GetRecords_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SchedulerServer).GetRecords(ctx, req.(*JobId))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Scheduler_GetWorkflowRun_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WorkflowRunId)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SchedulerServer).GetWorkflowRun(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Scheduler_GetWorkflowRun_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SchedulerServer).GetWorkflowRun(ctx, req.(*WorkflowRunId))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Scheduler_PreviewRunTimes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PreviewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SchedulerServer).PreviewRunTimes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Scheduler_PreviewRunTimes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SchedulerServer).PreviewRunTimes(ctx, req.(*PreviewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Scheduler_SetCalendar_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Calendar)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SchedulerServer).SetCalendar(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Scheduler_SetCalendar_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SchedulerServer).SetCalendar(ctx, req.(*Calendar))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Scheduler_GetCalendar_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CalendarName)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SchedulerServer).GetCalendar(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Scheduler_GetCalendar_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SchedulerServer).GetCalendar(ctx, req.(*CalendarName))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Scheduler_GetAllCalendars_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SchedulerServer).GetAllCalendars(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Scheduler_GetAllCalendars_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SchedulerServer).GetAllCalendars(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Scheduler_DeleteCalendar_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CalendarName)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SchedulerServer).DeleteCalendar(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Scheduler_DeleteCalendar_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SchedulerServer).DeleteCalendar(ctx, req.(*CalendarName))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Scheduler_ImportCalendarICS_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CalendarICS)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SchedulerServer).ImportCalendarICS(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Scheduler_ImportCalendarICS_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SchedulerServer).ImportCalendarICS(ctx, req.(*CalendarICS))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Scheduler_PauseJobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobSelector)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SchedulerServer).PauseJobs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Scheduler_PauseJobs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SchedulerServer).PauseJobs(ctx, req.(*JobSelector))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Scheduler_ResumeJobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobSelector)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SchedulerServer).ResumeJobs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Scheduler_ResumeJobs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SchedulerServer).ResumeJobs(ctx, req.(*JobSelector))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Scheduler_DeleteJobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobSelector)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SchedulerServer).DeleteJobs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Scheduler_DeleteJobs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SchedulerServer).DeleteJobs(ctx, req.(*JobSelector))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Scheduler_RunJobsNow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobSelector)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SchedulerServer).RunJobsNow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Scheduler_RunJobsNow_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SchedulerServer).RunJobsNow(ctx, req.(*JobSelector))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Scheduler_GetFuncSchema_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FuncName)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SchedulerServer).GetFuncSchema(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Scheduler_GetFuncSchema_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SchedulerServer).GetFuncSchema(ctx, req.(*FuncName))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Scheduler_ListFuncs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SchedulerServer).ListFuncs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Scheduler_ListFuncs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SchedulerServer).ListFuncs(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Scheduler_Start_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(emptypb.Empty)
 	if err := dec(in); err != nil {
@@ -423,6 +946,78 @@ func _Scheduler_Stop_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Scheduler_Pause_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SchedulerServer).Pause(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Scheduler_Pause_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SchedulerServer).Pause(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Scheduler_Resume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SchedulerServer).Resume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Scheduler_Resume_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SchedulerServer).Resume(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Scheduler_PauseCluster_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SchedulerServer).PauseCluster(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Scheduler_PauseCluster_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SchedulerServer).PauseCluster(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Scheduler_ResumeCluster_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SchedulerServer).ResumeCluster(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Scheduler_ResumeCluster_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SchedulerServer).ResumeCluster(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Scheduler_ServiceDesc is the grpc.ServiceDesc for Scheduler service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -466,6 +1061,62 @@ var Scheduler_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RunJob",
 			Handler:    _Scheduler_RunJob_Handler,
 		},
+		{
+			MethodName: "GetRecords",
+			Handler:    _Scheduler_GetRecords_Handler,
+		},
+		{
+			MethodName: "GetWorkflowRun",
+			Handler:    _Scheduler_GetWorkflowRun_Handler,
+		},
+		{
+			MethodName: "PreviewRunTimes",
+			Handler:    _Scheduler_PreviewRunTimes_Handler,
+		},
+		{
+			MethodName: "SetCalendar",
+			Handler:    _Scheduler_SetCalendar_Handler,
+		},
+		{
+			MethodName: "GetCalendar",
+			Handler:    _Scheduler_GetCalendar_Handler,
+		},
+		{
+			MethodName: "GetAllCalendars",
+			Handler:    _Scheduler_GetAllCalendars_Handler,
+		},
+		{
+			MethodName: "DeleteCalendar",
+			Handler:    _Scheduler_DeleteCalendar_Handler,
+		},
+		{
+			MethodName: "ImportCalendarICS",
+			Handler:    _Scheduler_ImportCalendarICS_Handler,
+		},
+		{
+			MethodName: "PauseJobs",
+			Handler:    _Scheduler_PauseJobs_Handler,
+		},
+		{
+			MethodName: "ResumeJobs",
+			Handler:    _Scheduler_ResumeJobs_Handler,
+		},
+		{
+			MethodName: "DeleteJobs",
+			Handler:    _Scheduler_DeleteJobs_Handler,
+		},
+		{
+			MethodName: "RunJobsNow",
+			Handler:    _Scheduler_RunJobsNow_Handler,
+		},
+		{
+			MethodName: "GetFuncSchema",
+			Handler:    _Scheduler_GetFuncSchema_Handler,
+		},
+		{
+			MethodName: "ListFuncs",
+			Handler:    _Scheduler_ListFuncs_Handler,
+		},
 		{
 			MethodName: "Start",
 			Handler:    _Scheduler_Start_Handler,
@@ -474,6 +1125,22 @@ var Scheduler_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Stop",
 			Handler:    _Scheduler_Stop_Handler,
 		},
+		{
+			MethodName: "Pause",
+			Handler:    _Scheduler_Pause_Handler,
+		},
+		{
+			MethodName: "Resume",
+			Handler:    _Scheduler_Resume_Handler,
+		},
+		{
+			MethodName: "PauseCluster",
+			Handler:    _Scheduler_PauseCluster_Handler,
+		},
+		{
+			MethodName: "ResumeCluster",
+			Handler:    _Scheduler_ResumeCluster_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "scheduler.proto",
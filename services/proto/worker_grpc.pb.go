@@ -0,0 +1,141 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.24.4
+// source: worker.proto
+
+package scheduler
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Worker_Connect_FullMethodName = "/scheduler.Worker/Connect"
+)
+
+// WorkerClient is the client API for Worker service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type WorkerClient interface {
+	Connect(ctx context.Context, opts ...grpc.CallOption) (Worker_ConnectClient, error)
+}
+
+type workerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewWorkerClient(cc grpc.ClientConnInterface) WorkerClient {
+	return &workerClient{cc}
+}
+
+func (c *workerClient) Connect(ctx context.Context, opts ...grpc.CallOption) (Worker_ConnectClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Worker_ServiceDesc.Streams[0], Worker_Connect_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &workerConnectClient{stream}
+	return x, nil
+}
+
+type Worker_ConnectClient interface {
+	Send(*WorkerMessage) error
+	Recv() (*WorkerRun, error)
+	grpc.ClientStream
+}
+
+type workerConnectClient struct {
+	grpc.ClientStream
+}
+
+func (x *workerConnectClient) Send(m *WorkerMessage) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *workerConnectClient) Recv() (*WorkerRun, error) {
+	m := new(WorkerRun)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// WorkerServer is the server API for Worker service.
+// All implementations must embed UnimplementedWorkerServer
+// for forward compatibility
+type WorkerServer interface {
+	Connect(Worker_ConnectServer) error
+	mustEmbedUnimplementedWorkerServer()
+}
+
+// UnimplementedWorkerServer must be embedded to have forward compatible implementations.
+type UnimplementedWorkerServer struct {
+}
+
+func (UnimplementedWorkerServer) Connect(Worker_ConnectServer) error {
+	return status.Errorf(codes.Unimplemented, "method Connect not implemented")
+}
+func (UnimplementedWorkerServer) mustEmbedUnimplementedWorkerServer() {}
+
+// UnsafeWorkerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to WorkerServer will
+// result in compilation errors.
+type UnsafeWorkerServer interface {
+	mustEmbedUnimplementedWorkerServer()
+}
+
+func RegisterWorkerServer(s grpc.ServiceRegistrar, srv WorkerServer) {
+	s.RegisterService(&Worker_ServiceDesc, srv)
+}
+
+func _Worker_Connect_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(WorkerServer).Connect(&workerConnectServer{stream})
+}
+
+type Worker_ConnectServer interface {
+	Send(*WorkerRun) error
+	Recv() (*WorkerMessage, error)
+	grpc.ServerStream
+}
+
+type workerConnectServer struct {
+	grpc.ServerStream
+}
+
+func (x *workerConnectServer) Send(m *WorkerRun) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *workerConnectServer) Recv() (*WorkerMessage, error) {
+	m := new(WorkerMessage)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Worker_ServiceDesc is the grpc.ServiceDesc for Worker service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Worker_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "scheduler.Worker",
+	HandlerType: (*WorkerServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Connect",
+			Handler:       _Worker_Connect_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "worker.proto",
+}
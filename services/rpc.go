@@ -2,12 +2,19 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net"
+	"strings"
+	"time"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
 
 	"github.com/kurtloong/agscheduler"
 	pb "github.com/kurtloong/agscheduler/services/proto"
@@ -19,10 +26,38 @@ type sRPCService struct {
 	scheduler *agscheduler.Scheduler
 }
 
+// The invalid fields of `Args` are returned as `InvalidArgument` with `BadRequest` details,
+// so is an empty job selector, other errors are returned as is.
+func (srs *sRPCService) handleErr(err error) error {
+	if errors.Is(err, agscheduler.ErrEmptyJobSelector) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	fields := fieldErrors(err)
+	if fields == nil {
+		return err
+	}
+
+	br := &errdetails.BadRequest{}
+	for _, f := range fields {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       f.Field,
+			Description: f.Description,
+		})
+	}
+
+	st, sErr := status.New(codes.InvalidArgument, err.Error()).WithDetails(br)
+	if sErr != nil {
+		return err
+	}
+
+	return st.Err()
+}
+
 func (srs *sRPCService) AddJob(ctx context.Context, pbJob *pb.Job) (*pb.Job, error) {
 	j := agscheduler.PbJobPtrToJob(pbJob)
 	j, err := srs.scheduler.AddJob(j)
-	return agscheduler.JobToPbJobPtr(j), err
+	return agscheduler.JobToPbJobPtr(j), srs.handleErr(err)
 }
 
 func (srs *sRPCService) GetJob(ctx context.Context, jobId *pb.JobId) (*pb.Job, error) {
@@ -38,7 +73,7 @@ func (srs *sRPCService) GetAllJobs(ctx context.Context, in *emptypb.Empty) (*pb.
 func (srs *sRPCService) UpdateJob(ctx context.Context, pbJob *pb.Job) (*pb.Job, error) {
 	j := agscheduler.PbJobPtrToJob(pbJob)
 	j, err := srs.scheduler.UpdateJob(j)
-	return agscheduler.JobToPbJobPtr(j), err
+	return agscheduler.JobToPbJobPtr(j), srs.handleErr(err)
 }
 
 func (srs *sRPCService) DeleteJob(ctx context.Context, jobId *pb.JobId) (*emptypb.Empty, error) {
@@ -74,6 +109,101 @@ func (srs *sRPCService) RunJob(ctx context.Context, pbJob *pb.Job) (*emptypb.Emp
 	return &emptypb.Empty{}, err
 }
 
+// All records are returned when the id is empty.
+func (srs *sRPCService) GetRecords(ctx context.Context, jobId *pb.JobId) (*pb.Records, error) {
+	return agscheduler.RecordsToPbRecordsPtr(srs.scheduler.GetRecords(jobId.GetId())), nil
+}
+
+func (srs *sRPCService) GetWorkflowRun(ctx context.Context, runId *pb.WorkflowRunId) (*pb.WorkflowRun, error) {
+	run, err := srs.scheduler.GetWorkflowRun(runId.GetId())
+	return agscheduler.WorkflowRunToPbWorkflowRunPtr(run), err
+}
+
+func (srs *sRPCService) PreviewRunTimes(ctx context.Context, req *pb.PreviewRequest) (*pb.RunTimes, error) {
+	j := agscheduler.PbJobPtrToJob(req.GetJob())
+
+	var from time.Time
+	if req.GetFrom() != nil {
+		from = req.GetFrom().AsTime()
+	}
+
+	// Default: `10`
+	n := int(req.GetN())
+	if n == 0 {
+		n = 10
+	}
+
+	ts, err := srs.scheduler.PreviewRunTimes(j, n, from)
+	if err != nil {
+		return nil, srs.handleErr(err)
+	}
+
+	timezone := j.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+
+	return agscheduler.RunTimesToPbRunTimesPtr(ts, timezone), nil
+}
+
+func (srs *sRPCService) SetCalendar(ctx context.Context, pbC *pb.Calendar) (*pb.Calendar, error) {
+	c, err := srs.scheduler.SetCalendar(agscheduler.PbCalendarPtrToCalendar(pbC))
+	return agscheduler.CalendarToPbCalendarPtr(c), err
+}
+
+func (srs *sRPCService) GetCalendar(ctx context.Context, name *pb.CalendarName) (*pb.Calendar, error) {
+	c, err := srs.scheduler.GetCalendar(name.GetName())
+	return agscheduler.CalendarToPbCalendarPtr(c), err
+}
+
+func (srs *sRPCService) GetAllCalendars(ctx context.Context, in *emptypb.Empty) (*pb.Calendars, error) {
+	cs, err := srs.scheduler.GetAllCalendars()
+	return agscheduler.CalendarsToPbCalendarsPtr(cs), err
+}
+
+func (srs *sRPCService) DeleteCalendar(ctx context.Context, name *pb.CalendarName) (*emptypb.Empty, error) {
+	err := srs.scheduler.DeleteCalendar(name.GetName())
+	return &emptypb.Empty{}, err
+}
+
+func (srs *sRPCService) ImportCalendarICS(ctx context.Context, ics *pb.CalendarICS) (*pb.Calendar, error) {
+	c, err := srs.scheduler.ImportCalendarICS(ics.GetName(), strings.NewReader(ics.GetIcs()))
+	return agscheduler.CalendarToPbCalendarPtr(c), err
+}
+
+func (srs *sRPCService) PauseJobs(ctx context.Context, pbSel *pb.JobSelector) (*pb.BulkResults, error) {
+	rs, err := srs.scheduler.PauseJobs(agscheduler.PbJobSelectorPtrToJobSelector(pbSel))
+	return agscheduler.BulkResultsToPbBulkResultsPtr(rs), srs.handleErr(err)
+}
+
+func (srs *sRPCService) ResumeJobs(ctx context.Context, pbSel *pb.JobSelector) (*pb.BulkResults, error) {
+	rs, err := srs.scheduler.ResumeJobs(agscheduler.PbJobSelectorPtrToJobSelector(pbSel))
+	return agscheduler.BulkResultsToPbBulkResultsPtr(rs), srs.handleErr(err)
+}
+
+func (srs *sRPCService) DeleteJobs(ctx context.Context, pbSel *pb.JobSelector) (*pb.BulkResults, error) {
+	rs, err := srs.scheduler.DeleteJobs(agscheduler.PbJobSelectorPtrToJobSelector(pbSel))
+	return agscheduler.BulkResultsToPbBulkResultsPtr(rs), srs.handleErr(err)
+}
+
+func (srs *sRPCService) RunJobsNow(ctx context.Context, pbSel *pb.JobSelector) (*pb.BulkResults, error) {
+	rs, err := srs.scheduler.RunJobsNow(agscheduler.PbJobSelectorPtrToJobSelector(pbSel))
+	return agscheduler.BulkResultsToPbBulkResultsPtr(rs), srs.handleErr(err)
+}
+
+func (srs *sRPCService) GetFuncSchema(ctx context.Context, fn *pb.FuncName) (*structpb.Struct, error) {
+	schema, err := srs.scheduler.FuncSchema(fn.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	return structpb.NewStruct(schema)
+}
+
+func (srs *sRPCService) ListFuncs(ctx context.Context, in *emptypb.Empty) (*pb.Funcs, error) {
+	return agscheduler.FuncInfosToPbFuncsPtr(srs.scheduler.ListFuncs()), nil
+}
+
 func (srs *sRPCService) Start(ctx context.Context, in *emptypb.Empty) (*emptypb.Empty, error) {
 	srs.scheduler.Start()
 	return &emptypb.Empty{}, nil
@@ -84,6 +214,36 @@ func (srs *sRPCService) Stop(ctx context.Context, in *emptypb.Empty) (*emptypb.E
 	return &emptypb.Empty{}, nil
 }
 
+func (srs *sRPCService) Pause(ctx context.Context, in *emptypb.Empty) (*emptypb.Empty, error) {
+	err := pauseScheduler(srs.scheduler)
+	return &emptypb.Empty{}, err
+}
+
+func (srs *sRPCService) Resume(ctx context.Context, in *emptypb.Empty) (*emptypb.Empty, error) {
+	err := resumeScheduler(srs.scheduler)
+	return &emptypb.Empty{}, err
+}
+
+// Used in cluster mode, as the HTTP API `/cluster/pause`.
+func (srs *sRPCService) PauseCluster(ctx context.Context, in *emptypb.Empty) (*emptypb.Empty, error) {
+	cn := agscheduler.GetClusterNode(srs.scheduler)
+	if cn == nil {
+		return nil, status.Error(codes.FailedPrecondition, "scheduler is not in cluster mode")
+	}
+
+	return &emptypb.Empty{}, cn.Pause()
+}
+
+// Used in cluster mode, as the HTTP API `/cluster/resume`.
+func (srs *sRPCService) ResumeCluster(ctx context.Context, in *emptypb.Empty) (*emptypb.Empty, error) {
+	cn := agscheduler.GetClusterNode(srs.scheduler)
+	if cn == nil {
+		return nil, status.Error(codes.FailedPrecondition, "scheduler is not in cluster mode")
+	}
+
+	return &emptypb.Empty{}, cn.Resume()
+}
+
 func panicInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
 	defer func() {
 		if err := recover(); err != nil {
@@ -100,6 +260,13 @@ type SchedulerRPCService struct {
 
 	// Default: `127.0.0.1:36360`
 	Address string
+	// Workers connected to the `Worker` service are disconnected
+	// when nothing is received from them within this time.
+	// Default: `30s`
+	WorkerHeartbeatTimeout time.Duration
+
+	srv *grpc.Server
+	wrs *wRPCService
 }
 
 func (s *SchedulerRPCService) Start() error {
@@ -112,15 +279,54 @@ func (s *SchedulerRPCService) Start() error {
 		return fmt.Errorf("scheduler RPC Service listen failure: %s", err)
 	}
 
-	srv := grpc.NewServer(grpc.UnaryInterceptor(panicInterceptor))
-	pb.RegisterSchedulerServer(srv, &sRPCService{scheduler: s.Scheduler})
+	s.srv = grpc.NewServer(grpc.UnaryInterceptor(panicInterceptor))
+	pb.RegisterSchedulerServer(s.srv, &sRPCService{scheduler: s.Scheduler})
+	if s.WorkerHeartbeatTimeout == 0 {
+		s.WorkerHeartbeatTimeout = 30 * time.Second
+	}
+	s.wrs = &wRPCService{
+		scheduler:        s.Scheduler,
+		heartbeatTimeout: s.WorkerHeartbeatTimeout,
+		quit:             make(chan struct{}),
+	}
+	pb.RegisterWorkerServer(s.srv, s.wrs)
 	slog.Info(fmt.Sprintf("Scheduler RPC Service listening at: %s", lis.Addr()))
 
 	go func() {
-		if err := srv.Serve(lis); err != nil {
+		if err := s.srv.Serve(lis); err != nil {
 			slog.Error(fmt.Sprintf("Scheduler RPC Service Unavailable: %s", err))
 		}
 	}()
 
 	return nil
 }
+
+// Close the listener and wait for the pending RPCs,
+// when `ctx` is done, they are canceled.
+func (s *SchedulerRPCService) Shutdown(ctx context.Context) error {
+	if s.srv == nil {
+		return nil
+	}
+
+	slog.Info(fmt.Sprintf("Scheduler RPC Service shutdown: %s", s.Address))
+
+	select {
+	case <-s.wrs.quit:
+	default:
+		close(s.wrs.quit)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.srv.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.srv.Stop()
+		return ctx.Err()
+	}
+}
@@ -6,9 +6,14 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/kurtloong/agscheduler"
 	pb "github.com/kurtloong/agscheduler/services/proto"
@@ -19,6 +24,232 @@ var ctx = context.Background()
 
 func dryRunRPC(ctx context.Context, j agscheduler.Job) {}
 
+type typedArgsRPC struct {
+	Times int `json:"times"`
+}
+
+func typedRunRPC(ctx context.Context, j agscheduler.Job, args typedArgsRPC) error { return nil }
+
+func testTypedRPC(t *testing.T, c pb.SchedulerClient) {
+	j := agscheduler.Job{
+		Name:     "Job",
+		Type:     agscheduler.TYPE_INTERVAL,
+		Interval: "1s",
+		FuncName: "typedRunRPC",
+		Args:     map[string]any{"times": 1.5},
+	}
+	_, err := c.AddJob(ctx, agscheduler.JobToPbJobPtr(j))
+	assert.Error(t, err)
+	st := status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Len(t, st.Details(), 1)
+	br := st.Details()[0].(*errdetails.BadRequest)
+	assert.Equal(t, "times", br.GetFieldViolations()[0].GetField())
+
+	j.Args = map[string]any{"times": 2}
+	pbJ, err := c.AddJob(ctx, agscheduler.JobToPbJobPtr(j))
+	assert.NoError(t, err)
+	_, err = c.DeleteJob(ctx, &pb.JobId{Id: pbJ.GetId()})
+	assert.NoError(t, err)
+
+	schema, err := c.GetFuncSchema(ctx, &pb.FuncName{Name: "typedRunRPC"})
+	assert.NoError(t, err)
+	assert.Equal(t, "object", schema.AsMap()["type"])
+	_, err = c.GetFuncSchema(ctx, &pb.FuncName{Name: "unknown"})
+	assert.Error(t, err)
+
+	pbFs, err := c.ListFuncs(ctx, &emptypb.Empty{})
+	assert.NoError(t, err)
+	infos := agscheduler.PbFuncsPtrToFuncInfos(pbFs)
+	names := make([]string, 0)
+	for _, info := range infos {
+		names = append(names, info.Name)
+	}
+	assert.Contains(t, names, "typedRunRPC")
+}
+
+func testRecordsRPC(t *testing.T, c pb.SchedulerClient) {
+	j := agscheduler.Job{
+		Id:       "records",
+		Name:     "Job",
+		Timeout:  "1s",
+		FuncName: "github.com/kurtloong/agscheduler/services.dryRunRPC",
+	}
+	_, err := c.RunJob(ctx, agscheduler.JobToPbJobPtr(j))
+	assert.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+
+	pbRs, err := c.GetRecords(ctx, &pb.JobId{Id: j.Id})
+	assert.NoError(t, err)
+	records := agscheduler.PbRecordsPtrToRecords(pbRs)
+	assert.Len(t, records, 1)
+	assert.Equal(t, agscheduler.RECORD_SUCCEEDED, records[0].Status)
+
+	pbRs, err = c.GetRecords(ctx, &pb.JobId{})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, pbRs.GetRecords())
+
+	_, err = c.GetWorkflowRun(ctx, &pb.WorkflowRunId{Id: "unknown"})
+	assert.Error(t, err)
+}
+
+func testPreviewRPC(t *testing.T, c pb.SchedulerClient) {
+	j := agscheduler.Job{
+		Name:     "Job",
+		Type:     agscheduler.TYPE_INTERVAL,
+		Interval: "1h",
+		Timezone: "Asia/Shanghai",
+	}
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	pbRTs, err := c.PreviewRunTimes(ctx, &pb.PreviewRequest{
+		Job: agscheduler.JobToPbJobPtr(j), N: 2, From: timestamppb.New(from),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Asia/Shanghai", pbRTs.GetTimezone())
+	assert.Len(t, pbRTs.GetRunTimes(), 2)
+	assert.Equal(t, from.Add(2*time.Hour), pbRTs.GetRunTimes()[1].GetUtc().AsTime())
+	assert.Equal(t, "2024-01-01T10:00:00+08:00", pbRTs.GetRunTimes()[1].GetLocal())
+
+	jR := agscheduler.Job{
+		Name:     "Job",
+		Type:     agscheduler.TYPE_RRULE,
+		StartAt:  "2024-01-01 09:00:00",
+		RRule:    "RRULE:FREQ=MONTHLY;BYDAY=-1FR\nRDATE:20240102T120000",
+		Timezone: "Asia/Shanghai",
+	}
+	pbRTs, err = c.PreviewRunTimes(ctx, &pb.PreviewRequest{
+		Job: agscheduler.JobToPbJobPtr(jR), N: 2, From: timestamppb.New(from),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-01-02T12:00:00+08:00", pbRTs.GetRunTimes()[0].GetLocal())
+	assert.Equal(t, "2024-01-26T09:00:00+08:00", pbRTs.GetRunTimes()[1].GetLocal())
+
+	jC := agscheduler.Job{
+		Name:     "Job",
+		Type:     agscheduler.TYPE_COMBINED,
+		Operator: agscheduler.OPERATOR_OR,
+		Triggers: []agscheduler.Trigger{
+			{Type: agscheduler.TYPE_CRON, CronExpr: "0 9 * * MON"},
+			{Type: agscheduler.TYPE_RRULE, RRule: "FREQ=MONTHLY;BYMONTHDAY=-1", StartAt: "2024-01-01 18:00:00"},
+		},
+		Timezone: "Asia/Shanghai",
+	}
+	pbRTs, err = c.PreviewRunTimes(ctx, &pb.PreviewRequest{
+		Job: agscheduler.JobToPbJobPtr(jC), N: 3, From: timestamppb.New(from.AddDate(0, 0, 26)),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-01-29T09:00:00+08:00", pbRTs.GetRunTimes()[0].GetLocal())
+	assert.Equal(t, "2024-01-31T18:00:00+08:00", pbRTs.GetRunTimes()[1].GetLocal())
+	assert.Equal(t, "2024-02-05T09:00:00+08:00", pbRTs.GetRunTimes()[2].GetLocal())
+
+	jR.RRule = "RRULE:FREQ=DAILY;UNTIL=x"
+	_, err = c.PreviewRunTimes(ctx, &pb.PreviewRequest{Job: agscheduler.JobToPbJobPtr(jR)})
+	st := status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Equal(t, "rrule", st.Details()[0].(*errdetails.BadRequest).GetFieldViolations()[0].GetField())
+
+	j.Interval = "2"
+	_, err = c.PreviewRunTimes(ctx, &pb.PreviewRequest{Job: agscheduler.JobToPbJobPtr(j)})
+	st = status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Len(t, st.Details(), 1)
+	br := st.Details()[0].(*errdetails.BadRequest)
+	assert.Equal(t, "interval", br.GetFieldViolations()[0].GetField())
+}
+
+func testCalendarRPC(t *testing.T, c pb.SchedulerClient) {
+	cal := agscheduler.Calendar{Name: "holidays", Dates: []string{"2024-01-01"}}
+	_, err := c.SetCalendar(ctx, agscheduler.CalendarToPbCalendarPtr(cal))
+	assert.NoError(t, err)
+
+	ics := "BEGIN:VEVENT\nDTSTART;VALUE=DATE:20241225\nRRULE:FREQ=YEARLY\nEND:VEVENT\n"
+	pbC, err := c.ImportCalendarICS(ctx, &pb.CalendarICS{Name: "christmas", Ics: ics})
+	assert.NoError(t, err)
+	assert.Len(t, pbC.GetRules(), 1)
+
+	pbC, err = c.GetCalendar(ctx, &pb.CalendarName{Name: "holidays"})
+	assert.NoError(t, err)
+	assert.Equal(t, cal, agscheduler.PbCalendarPtrToCalendar(pbC))
+
+	pbCs, err := c.GetAllCalendars(ctx, &emptypb.Empty{})
+	assert.NoError(t, err)
+	assert.Len(t, agscheduler.PbCalendarsPtrToCalendars(pbCs), 2)
+
+	for _, name := range []string{"holidays", "christmas"} {
+		_, err = c.DeleteCalendar(ctx, &pb.CalendarName{Name: name})
+		assert.NoError(t, err)
+	}
+	_, err = c.GetCalendar(ctx, &pb.CalendarName{Name: "holidays"})
+	assert.Error(t, err)
+}
+
+func testWorkerRPC(t *testing.T, c pb.SchedulerClient, wc pb.WorkerClient) {
+	stream, err := wc.Connect(ctx)
+	assert.NoError(t, err)
+	err = stream.Send(&pb.WorkerMessage{Message: &pb.WorkerMessage_Hello{
+		Hello: &pb.WorkerHello{Id: "w1", Funcs: []string{"worker.echo"}},
+	}})
+	assert.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+
+	j := agscheduler.Job{
+		Id:       "worker",
+		Name:     "Job",
+		Timeout:  "1s",
+		FuncName: "worker.echo",
+		Args:     map[string]any{"msg": "hi"},
+	}
+	_, err = c.RunJob(ctx, agscheduler.JobToPbJobPtr(j))
+	assert.NoError(t, err)
+
+	run, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, "worker", run.GetJob().GetId())
+	result, err := structpb.NewStruct(run.GetJob().GetArgs().AsMap())
+	assert.NoError(t, err)
+	err = stream.Send(&pb.WorkerMessage{Message: &pb.WorkerMessage_Result{
+		Result: &pb.WorkerResult{RunId: run.GetRunId(), Result: result},
+	}})
+	assert.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+
+	pbRs, err := c.GetRecords(ctx, &pb.JobId{Id: j.Id})
+	assert.NoError(t, err)
+	records := agscheduler.PbRecordsPtrToRecords(pbRs)
+	assert.Len(t, records, 1)
+	assert.Equal(t, agscheduler.RECORD_SUCCEEDED, records[0].Status)
+	assert.Equal(t, map[string]any{"msg": "hi"}, records[0].Result)
+
+	err = stream.CloseSend()
+	assert.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Error(t, err)
+}
+
+func testWorkerHeartbeatRPC(t *testing.T, wc pb.WorkerClient) {
+	stream, err := wc.Connect(ctx)
+	assert.NoError(t, err)
+	err = stream.Send(&pb.WorkerMessage{Message: &pb.WorkerMessage_Heartbeat{}})
+	assert.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	stream, err = wc.Connect(ctx)
+	assert.NoError(t, err)
+	err = stream.Send(&pb.WorkerMessage{Message: &pb.WorkerMessage_Hello{
+		Hello: &pb.WorkerHello{Funcs: []string{"worker.echo"}},
+	}})
+	assert.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		time.Sleep(100 * time.Millisecond)
+		err = stream.Send(&pb.WorkerMessage{Message: &pb.WorkerMessage_Heartbeat{Heartbeat: &pb.WorkerHeartbeat{}}})
+		assert.NoError(t, err)
+	}
+	_, err = stream.Recv()
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
+
 func testAGSchedulerRPC(t *testing.T, c pb.SchedulerClient) {
 	_, err := c.Start(ctx, &emptypb.Empty{})
 	assert.NoError(t, err)
@@ -63,11 +294,27 @@ func testAGSchedulerRPC(t *testing.T, c pb.SchedulerClient) {
 	_, err = c.RunJob(ctx, pbJ)
 	assert.NoError(t, err)
 
+	pbRs, err := c.PauseJobs(ctx, &pb.JobSelector{Ids: []string{j.Id}})
+	assert.NoError(t, err)
+	assert.Len(t, pbRs.Results, 1)
+	pbRs, err = c.ResumeJobs(ctx, &pb.JobSelector{Status: agscheduler.STATUS_PAUSED})
+	assert.NoError(t, err)
+	assert.Len(t, pbRs.Results, 1)
+	_, err = c.DeleteJobs(ctx, &pb.JobSelector{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	pbRs, err = c.RunJobsNow(ctx, &pb.JobSelector{Tags: []string{"other"}})
+	assert.NoError(t, err)
+	assert.Len(t, pbRs.Results, 0)
+
 	_, err = c.DeleteJob(ctx, &pb.JobId{Id: j.Id})
 	assert.NoError(t, err)
 	_, err = c.GetJob(ctx, &pb.JobId{Id: j.Id})
 	assert.Contains(t, err.Error(), agscheduler.JobNotFoundError(j.Id).Error())
 
+	pbRs, err = c.DeleteJobs(ctx, &pb.JobSelector{Ids: []string{j.Id}})
+	assert.NoError(t, err)
+	assert.Len(t, pbRs.Results, 0)
+
 	_, err = c.DeleteAllJobs(ctx, &emptypb.Empty{})
 	assert.NoError(t, err)
 	pbJs, err := c.GetAllJobs(ctx, &emptypb.Empty{})
@@ -75,12 +322,22 @@ func testAGSchedulerRPC(t *testing.T, c pb.SchedulerClient) {
 	js := agscheduler.PbJobsPtrToJobs(pbJs)
 	assert.Len(t, js, 0)
 
+	_, err = c.Pause(ctx, &emptypb.Empty{})
+	assert.NoError(t, err)
+	_, err = c.RunJob(ctx, pbJ)
+	assert.Contains(t, err.Error(), agscheduler.ErrSchedulerPaused.Error())
+	_, err = c.Resume(ctx, &emptypb.Empty{})
+	assert.NoError(t, err)
+	_, err = c.PauseCluster(ctx, &emptypb.Empty{})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
 	_, err = c.Stop(ctx, &emptypb.Empty{})
 	assert.NoError(t, err)
 }
 
 func TestRPCService(t *testing.T) {
 	agscheduler.RegisterFuncs(dryRunRPC)
+	agscheduler.RegisterTyped("typedRunRPC", typedRunRPC)
 
 	store := &stores.MemoryStore{}
 
@@ -91,6 +348,7 @@ func TestRPCService(t *testing.T) {
 	srservice := SchedulerRPCService{
 		Scheduler: scheduler,
 		// Address:   "127.0.0.1:36360",
+		WorkerHeartbeatTimeout: 200 * time.Millisecond,
 	}
 	srservice.Start()
 
@@ -100,6 +358,17 @@ func TestRPCService(t *testing.T) {
 	client := pb.NewSchedulerClient(conn)
 
 	testAGSchedulerRPC(t, client)
+	testTypedRPC(t, client)
+	testRecordsRPC(t, client)
+	testPreviewRPC(t, client)
+	testCalendarRPC(t, client)
+
+	workerClient := pb.NewWorkerClient(conn)
+	testWorkerRPC(t, client, workerClient)
+	testWorkerHeartbeatRPC(t, workerClient)
+
+	err = srservice.Shutdown(ctx)
+	assert.NoError(t, err)
 
 	err = store.Clear()
 	assert.NoError(t, err)
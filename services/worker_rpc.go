@@ -0,0 +1,100 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kurtloong/agscheduler"
+	pb "github.com/kurtloong/agscheduler/services/proto"
+)
+
+type wRPCService struct {
+	pb.UnimplementedWorkerServer
+
+	scheduler *agscheduler.Scheduler
+
+	// A worker is disconnected when nothing is received from it within this time.
+	heartbeatTimeout time.Duration
+	// Closed by `Shutdown`, so that the connected workers do not block it.
+	quit chan struct{}
+}
+
+// A worker says hello with its functions first, then the runs are streamed to it,
+// while it streams back the results and heartbeats.
+func (wrs *wRPCService) Connect(stream pb.Worker_ConnectServer) error {
+	msg, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	hello := msg.GetHello()
+	if hello == nil {
+		return status.Error(codes.InvalidArgument, "the first message must be `hello`")
+	}
+	if len(hello.GetFuncs()) == 0 {
+		return status.Error(codes.InvalidArgument, "`hello` must advertise at least one function")
+	}
+
+	w := wrs.scheduler.ConnectWorker(hello.GetId(), hello.GetFuncs())
+	defer wrs.scheduler.DisconnectWorker(w)
+
+	seenChan := make(chan struct{}, 1)
+	errChan := make(chan error, 1)
+	go func() {
+		for {
+			msg, err := stream.Recv()
+			if err != nil {
+				errChan <- err
+				return
+			}
+
+			select {
+			case seenChan <- struct{}{}:
+			default:
+			}
+
+			if r := msg.GetResult(); r != nil {
+				w.Report(agscheduler.WorkerResult{
+					RunId:  r.GetRunId(),
+					Result: r.GetResult().AsMap(),
+					Error:  r.GetError(),
+				})
+			}
+		}
+	}()
+
+	timer := time.NewTimer(wrs.heartbeatTimeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case run := <-w.Runs():
+			pbR := &pb.WorkerRun{RunId: run.Id, Job: agscheduler.JobToPbJobPtr(run.Job)}
+			if err := stream.Send(pbR); err != nil {
+				return err
+			}
+		case <-seenChan:
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(wrs.heartbeatTimeout)
+		case <-timer.C:
+			return status.Errorf(codes.DeadlineExceeded, "worker `%s` heartbeat timeout", w.Id)
+		case err := <-errChan:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		case <-wrs.quit:
+			return status.Error(codes.Unavailable, "scheduler RPC Service is shutting down")
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
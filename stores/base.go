@@ -1,5 +1,28 @@
 package stores
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"time"
+)
 
 var ctx = context.Background()
+
+// Run times in store indexes are Unix milliseconds,
+// values below it are Unix seconds written by older versions,
+// as milliseconds they would be before 2001.
+const UNIX_MILLI_MIN = 1e12
+
+// Convert a run time in a store index, either Unix milliseconds or Unix seconds written by older versions.
+func runTimeFromUnix(v int64) time.Time {
+	if v < UNIX_MILLI_MIN {
+		return time.Unix(v, 0).UTC()
+	}
+
+	return time.UnixMilli(v).UTC()
+}
+
+// Run times in etcd are sorted as strings, so they are padded to the same length.
+func formatEtcdRunTime(t time.Time) string {
+	return fmt.Sprintf("%016d", t.UTC().UnixMilli())
+}
@@ -12,6 +12,90 @@ import (
 
 func dryRunStores(ctx context.Context, j agscheduler.Job) {}
 
+// Run times in store indexes keep milliseconds.
+func testRunTimeMillis(t *testing.T, store agscheduler.Store) {
+	nextRunTime := time.Date(2099, 1, 1, 0, 0, 0, 250*int(time.Millisecond), time.UTC)
+	j := agscheduler.Job{Id: "millis", Name: "Job", Type: agscheduler.TYPE_DATETIME, NextRunTime: nextRunTime}
+
+	err := store.AddJob(j)
+	assert.NoError(t, err)
+	nextRunTimeMin, err := store.GetNextRunTime()
+	assert.NoError(t, err)
+	assert.True(t, nextRunTime.Equal(nextRunTimeMin), nextRunTimeMin)
+
+	err = store.DeleteJob(j.Id)
+	assert.NoError(t, err)
+}
+
+// The slots of a semaphore are limited, refreshed by their holders and freed when they expire.
+func testSemaphores(t *testing.T, store agscheduler.SemaphoreStore) {
+	ttl := 2 * time.Second
+
+	ok, err := store.AcquireSemaphore("table", "h1", 2, ttl)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = store.AcquireSemaphore("table", "h2", 2, ttl)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = store.AcquireSemaphore("table", "h3", 2, ttl)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = store.AcquireSemaphore("table", "h1", 2, ttl)
+	assert.NoError(t, err)
+	assert.True(t, ok, "refreshed")
+	ok, err = store.AcquireSemaphore("other", "h3", 1, ttl)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	err = store.ReleaseSemaphore("table", "h2")
+	assert.NoError(t, err)
+	ok, err = store.AcquireSemaphore("table", "h3", 2, ttl)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// Freed when they expire.
+	time.Sleep(ttl + time.Second)
+	ok, err = store.AcquireSemaphore("other", "h4", 1, ttl)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	for _, h := range []string{"h1", "h3"} {
+		err = store.ReleaseSemaphore("table", h)
+		assert.NoError(t, err)
+	}
+	err = store.ReleaseSemaphore("other", "h4")
+	assert.NoError(t, err)
+}
+
+// The tokens beyond the burst are reserved at the rate.
+func testRateLimits(t *testing.T, store agscheduler.TokenStore) {
+	for i, expected := range []time.Duration{0, 0, 100 * time.Millisecond, 200 * time.Millisecond} {
+		wait, err := store.ReserveToken("api", 10, 2)
+		assert.NoError(t, err)
+		assert.InDelta(t, expected, wait, float64(50*time.Millisecond), i)
+	}
+
+	wait, err := store.ReserveToken("other", 10, 2)
+	assert.NoError(t, err)
+	assert.Zero(t, wait)
+
+	// The token given back is reserved by the next run.
+	err = store.ReturnToken("api", 10)
+	assert.NoError(t, err)
+	wait, err = store.ReserveToken("api", 10, 2)
+	assert.NoError(t, err)
+	assert.InDelta(t, 200*time.Millisecond, wait, float64(50*time.Millisecond))
+	err = store.ReturnToken("none", 10)
+	assert.NoError(t, err)
+}
+
+func TestRunTimeFromUnix(t *testing.T) {
+	runTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, runTime, runTimeFromUnix(runTime.Unix()))
+	assert.Equal(t, runTime.Add(time.Millisecond), runTimeFromUnix(runTime.UnixMilli()+1))
+	assert.Equal(t, "0001704067200000", formatEtcdRunTime(runTime))
+}
+
 func testAGScheduler(t *testing.T, s *agscheduler.Scheduler) {
 	agscheduler.RegisterFuncs(dryRunStores)
 
@@ -66,5 +150,22 @@ func testAGScheduler(t *testing.T, s *agscheduler.Scheduler) {
 	assert.NoError(t, err)
 	assert.Len(t, js, 0)
 
+	c := agscheduler.Calendar{Name: "holidays", Dates: []string{"2024-01-01"}}
+	_, err = s.SetCalendar(c)
+	assert.NoError(t, err)
+	c.Rules = []agscheduler.CalendarRule{{Months: []int{12}, Days: []int{25}}}
+	_, err = s.SetCalendar(c)
+	assert.NoError(t, err)
+	c, err = s.GetCalendar("holidays")
+	assert.NoError(t, err)
+	assert.Len(t, c.Rules, 1)
+	cs, err := s.GetAllCalendars()
+	assert.NoError(t, err)
+	assert.Len(t, cs, 1)
+	err = s.DeleteCalendar("holidays")
+	assert.NoError(t, err)
+	_, err = s.GetCalendar("holidays")
+	assert.ErrorIs(t, err, agscheduler.CalendarNotFoundError("holidays"))
+
 	s.Stop()
 }
@@ -1,10 +1,13 @@
 package stores
 
 import (
+	"encoding/json"
+	"fmt"
 	"path"
 	"strconv"
 	"time"
 
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	clientv3 "go.etcd.io/etcd/client/v3"
 
 	"github.com/kurtloong/agscheduler"
@@ -13,13 +16,21 @@ import (
 const (
 	JOBS_PATH      = "/agscheduler/jobs"
 	RUN_TIMES_PATH = "/agscheduler/run_times"
+	CALENDARS_PATH = "/agscheduler/calendars"
+	// The slots of a semaphore are the keys `SEMAPHORES_PATH/name/holder`, bound to leases.
+	SEMAPHORES_PATH = "/agscheduler/semaphores"
+	// The token buckets are the keys `RATE_LIMITS_PATH/name`, see `agscheduler.ReserveTokenAt`.
+	RATE_LIMITS_PATH = "/agscheduler/rate_limits"
 )
 
 // Stores jobs in a etcd.
 type EtcdStore struct {
-	Cli          *clientv3.Client
-	JobsPath     string
-	RunTimesPath string
+	Cli            *clientv3.Client
+	JobsPath       string
+	RunTimesPath   string
+	CalendarsPath  string
+	SemaphoresPath string
+	RateLimitsPath string
 }
 
 func (s *EtcdStore) Init() error {
@@ -29,6 +40,44 @@ func (s *EtcdStore) Init() error {
 	if s.RunTimesPath == "" {
 		s.RunTimesPath = RUN_TIMES_PATH
 	}
+	if s.CalendarsPath == "" {
+		s.CalendarsPath = CALENDARS_PATH
+	}
+	if s.SemaphoresPath == "" {
+		s.SemaphoresPath = SEMAPHORES_PATH
+	}
+	if s.RateLimitsPath == "" {
+		s.RateLimitsPath = RATE_LIMITS_PATH
+	}
+
+	return s.migrateRunTimes()
+}
+
+// Convert the run times in Unix seconds written by older versions to padded Unix milliseconds.
+func (s *EtcdStore) migrateRunTimes() error {
+	resp, err := s.Cli.Get(ctx, s.RunTimesPath, clientv3.WithPrefix())
+	if err != nil {
+		return fmt.Errorf("failed to migrate run times: %s", err)
+	}
+
+	for _, kv := range resp.Kvs {
+		v, err := strconv.ParseInt(string(kv.Value), 10, 64)
+		if err != nil {
+			return fmt.Errorf("failed to migrate run time `%s`: %s", kv.Key, err)
+		}
+		value := formatEtcdRunTime(runTimeFromUnix(v))
+		if value == string(kv.Value) {
+			continue
+		}
+
+		// Skipped when the run time has been updated meanwhile.
+		txn := s.Cli.Txn(ctx).If(clientv3.Compare(clientv3.ModRevision(string(kv.Key)), "=", kv.ModRevision)).Then(
+			clientv3.OpPut(string(kv.Key), value),
+		)
+		if _, err := txn.Commit(); err != nil {
+			return fmt.Errorf("failed to migrate run time `%s`: %s", kv.Key, err)
+		}
+	}
 
 	return nil
 }
@@ -44,7 +93,7 @@ func (s *EtcdStore) AddJob(j agscheduler.Job) error {
 
 	txn := s.Cli.Txn(ctx).If().Then(
 		clientv3.OpPut(jPath, string(state)),
-		clientv3.OpPut(rPath, strconv.Itoa(int(j.NextRunTime.UTC().Unix()))),
+		clientv3.OpPut(rPath, formatEtcdRunTime(j.NextRunTime)),
 	)
 	if _, err := txn.Commit(); err != nil {
 		return err
@@ -97,7 +146,7 @@ func (s *EtcdStore) UpdateJob(j agscheduler.Job) error {
 
 	txn := s.Cli.Txn(ctx).If(clientv3.Compare(clientv3.Version(jPath), ">", 0)).Then(
 		clientv3.OpPut(jPath, string(state)),
-		clientv3.OpPut(rPath, strconv.Itoa(int(j.NextRunTime.UTC().Unix()))),
+		clientv3.OpPut(rPath, formatEtcdRunTime(j.NextRunTime)),
 	)
 	if _, err := txn.Commit(); err != nil {
 		return err
@@ -143,15 +192,193 @@ func (s *EtcdStore) GetNextRunTime() (time.Time, error) {
 		return time.Time{}, err
 	}
 
-	nextRunTimeMinUnix, err := strconv.Atoi(string(resp.Kvs[0].Value))
+	nextRunTimeMinUnix, err := strconv.ParseInt(string(resp.Kvs[0].Value), 10, 64)
 	if err != nil {
 		return time.Time{}, err
 	}
 
-	nextRunTimeMin := time.Unix(int64(nextRunTimeMinUnix), 0).UTC()
+	nextRunTimeMin := runTimeFromUnix(nextRunTimeMinUnix)
 	return nextRunTimeMin, nil
 }
 
+func (s *EtcdStore) SetCalendar(c agscheduler.Calendar) error {
+	state, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Cli.Put(ctx, path.Join(s.CalendarsPath, c.Name), string(state))
+	return err
+}
+
+func (s *EtcdStore) GetCalendar(name string) (agscheduler.Calendar, error) {
+	resp, err := s.Cli.Get(ctx, path.Join(s.CalendarsPath, name))
+	if err != nil {
+		return agscheduler.Calendar{}, err
+	}
+	if len(resp.Kvs) == 0 {
+		return agscheduler.Calendar{}, agscheduler.CalendarNotFoundError(name)
+	}
+
+	var c agscheduler.Calendar
+	err = json.Unmarshal(resp.Kvs[0].Value, &c)
+	return c, err
+}
+
+func (s *EtcdStore) GetAllCalendars() ([]agscheduler.Calendar, error) {
+	resp, err := s.Cli.Get(ctx, s.CalendarsPath, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+
+	var calendarList []agscheduler.Calendar
+	for _, kv := range resp.Kvs {
+		var c agscheduler.Calendar
+		if err := json.Unmarshal(kv.Value, &c); err != nil {
+			return nil, err
+		}
+		calendarList = append(calendarList, c)
+	}
+
+	return calendarList, nil
+}
+
+func (s *EtcdStore) DeleteCalendar(name string) error {
+	_, err := s.Cli.Delete(ctx, path.Join(s.CalendarsPath, name))
+	return err
+}
+
+// The holders are ranked by the creation of their keys, which is kept when a slot is refreshed,
+// a holder has a slot when it is ranked below the limit.
+// Each slot has its own lease, kept alive when the slot is refreshed and revoked when it is released.
+func (s *EtcdStore) AcquireSemaphore(name string, holder string, limit int, ttl time.Duration) (bool, error) {
+	sPath := path.Join(s.SemaphoresPath, name) + "/"
+	hPath := sPath + holder
+
+	resp, err := s.Cli.Get(ctx, hPath)
+	if err != nil {
+		return false, err
+	}
+	refreshed := false
+	if len(resp.Kvs) > 0 && resp.Kvs[0].Lease != 0 {
+		// The lease may have expired meanwhile, then the slot is taken again.
+		_, err := s.Cli.KeepAliveOnce(ctx, clientv3.LeaseID(resp.Kvs[0].Lease))
+		refreshed = err == nil
+	}
+	if !refreshed {
+		lease, err := s.Cli.Grant(ctx, max(int64(ttl.Seconds()), 1))
+		if err != nil {
+			return false, err
+		}
+		if _, err := s.Cli.Put(ctx, hPath, holder, clientv3.WithLease(lease.ID)); err != nil {
+			s.Cli.Revoke(ctx, lease.ID)
+			return false, err
+		}
+	}
+
+	resp, err = s.Cli.Get(ctx, sPath, clientv3.WithPrefix(), clientv3.WithKeysOnly(),
+		clientv3.WithSort(clientv3.SortByCreateRevision, clientv3.SortAscend),
+	)
+	if err != nil {
+		return false, err
+	}
+	for i, kv := range resp.Kvs {
+		if string(kv.Key) == hPath && i < limit {
+			return true, nil
+		}
+	}
+
+	return false, s.ReleaseSemaphore(name, holder)
+}
+
+// The lease of the slot is revoked, which deletes its key.
+func (s *EtcdStore) ReleaseSemaphore(name string, holder string) error {
+	hPath := path.Join(s.SemaphoresPath, name, holder)
+	resp, err := s.Cli.Get(ctx, hPath)
+	if err != nil {
+		return err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil
+	}
+	if resp.Kvs[0].Lease == 0 {
+		_, err = s.Cli.Delete(ctx, hPath)
+		return err
+	}
+
+	_, err = s.Cli.Revoke(ctx, clientv3.LeaseID(resp.Kvs[0].Lease))
+	if rpctypes.Error(err) == rpctypes.ErrLeaseNotFound {
+		return nil
+	}
+	return err
+}
+
+// Retried until the bucket is not updated by another node meanwhile.
+// etcd has no clock to read, the time of the bucket is the clock of this node,
+// unlike the semaphores which expire by leases.
+func (s *EtcdStore) ReserveToken(name string, rate float64, burst int) (time.Duration, error) {
+	key := path.Join(s.RateLimitsPath, name)
+	for {
+		resp, err := s.Cli.Get(ctx, key)
+		if err != nil {
+			return 0, err
+		}
+		var tat, rev int64
+		if len(resp.Kvs) > 0 {
+			tat, _ = strconv.ParseInt(string(resp.Kvs[0].Value), 10, 64)
+			rev = resp.Kvs[0].ModRevision
+		}
+
+		tat, wait := agscheduler.ReserveTokenAt(tat, time.Now(), rate, burst)
+		txnResp, err := s.Cli.Txn(ctx).If(clientv3.Compare(clientv3.ModRevision(key), "=", rev)).Then(
+			clientv3.OpPut(key, strconv.FormatInt(tat, 10)),
+		).Commit()
+		if err != nil {
+			return 0, err
+		}
+		if txnResp.Succeeded {
+			return wait, nil
+		}
+	}
+}
+
+// Retried until the bucket is not updated by another node meanwhile.
+func (s *EtcdStore) ReturnToken(name string, rate float64) error {
+	key := path.Join(s.RateLimitsPath, name)
+	for {
+		resp, err := s.Cli.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+		if len(resp.Kvs) == 0 {
+			return nil
+		}
+		tat, _ := strconv.ParseInt(string(resp.Kvs[0].Value), 10, 64)
+		rev := resp.Kvs[0].ModRevision
+
+		tat = agscheduler.ReturnTokenAt(tat, time.Now(), rate)
+		txnResp, err := s.Cli.Txn(ctx).If(clientv3.Compare(clientv3.ModRevision(key), "=", rev)).Then(
+			clientv3.OpPut(key, strconv.FormatInt(tat, 10)),
+		).Commit()
+		if err != nil {
+			return err
+		}
+		if txnResp.Succeeded {
+			return nil
+		}
+	}
+}
+
 func (s *EtcdStore) Clear() error {
+	if _, err := s.Cli.Delete(ctx, s.CalendarsPath, clientv3.WithPrefix()); err != nil {
+		return err
+	}
+	if _, err := s.Cli.Delete(ctx, s.SemaphoresPath, clientv3.WithPrefix()); err != nil {
+		return err
+	}
+	if _, err := s.Cli.Delete(ctx, s.RateLimitsPath, clientv3.WithPrefix()); err != nil {
+		return err
+	}
+
 	return s.DeleteAllJobs()
-}
\ No newline at end of file
+}
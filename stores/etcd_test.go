@@ -1,6 +1,7 @@
 package stores
 
 import (
+	"strconv"
 	"testing"
 	"time"
 
@@ -18,17 +19,52 @@ func TestEtcdStore(t *testing.T) {
 	assert.NoError(t, err)
 	defer cli.Close()
 	store := &EtcdStore{
-		Cli:          cli,
-		JobsPath:     "/agscheduler/test_jobs",
-		RunTimesPath: "/agscheduler/test_run_times",
+		Cli:           cli,
+		JobsPath:      "/agscheduler/test_jobs",
+		RunTimesPath:  "/agscheduler/test_run_times",
+		CalendarsPath: "/agscheduler/test_calendars",
 	}
 
 	scheduler := &agscheduler.Scheduler{}
 	err = scheduler.SetStore(store)
 	assert.NoError(t, err)
 
+	testRunTimeMillis(t, store)
+	testSemaphores(t, store)
+	testRateLimits(t, store)
 	testAGScheduler(t, scheduler)
 
 	err = store.Clear()
 	assert.NoError(t, err)
 }
+
+func TestEtcdStoreMigrateRunTimes(t *testing.T) {
+	cli, err := clientv3.New(clientv3.Config{
+		Endpoints:   []string{"127.0.0.1:2379"},
+		DialTimeout: 5 * time.Second,
+	})
+	assert.NoError(t, err)
+	defer cli.Close()
+	store := &EtcdStore{
+		Cli:           cli,
+		JobsPath:      "/agscheduler/test_jobs",
+		RunTimesPath:  "/agscheduler/test_run_times",
+		CalendarsPath: "/agscheduler/test_calendars",
+	}
+
+	runTime := time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err = cli.Put(ctx, store.RunTimesPath+"/old", strconv.FormatInt(runTime.Unix(), 10))
+	assert.NoError(t, err)
+
+	err = store.Init()
+	assert.NoError(t, err)
+	resp, err := cli.Get(ctx, store.RunTimesPath+"/old")
+	assert.NoError(t, err)
+	assert.Equal(t, "0004070908800000", string(resp.Kvs[0].Value))
+	nextRunTimeMin, err := store.GetNextRunTime()
+	assert.NoError(t, err)
+	assert.Equal(t, runTime, nextRunTimeMin)
+
+	err = store.Clear()
+	assert.NoError(t, err)
+}
@@ -1,38 +1,88 @@
 package stores
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/kurtloong/agscheduler"
 )
 
-const TABLE_NAME = "jobs"
+const (
+	TABLE_NAME             = "jobs"
+	CALENDARS_TABLE_NAME   = "calendars"
+	SEMAPHORES_TABLE_NAME  = "semaphores"
+	RATE_LIMITS_TABLE_NAME = "rate_limits"
+)
 
-// GORM table
+// GORM table, `NextRunTime` keeps milliseconds,
+// the column of older versions is altered by `AutoMigrate` in `Init`.
 type Jobs struct {
 	ID          string    `gorm:"size:64;primaryKey"`
-	NextRunTime time.Time `gorm:"index"`
+	NextRunTime time.Time `gorm:"index;precision:3"`
 	State       []byte    `gorm:"type:bytes;not null"`
 }
 
+// GORM table, `State` is the calendar in JSON.
+type Calendars struct {
+	Name  string `gorm:"size:64;primaryKey"`
+	State []byte `gorm:"type:bytes;not null"`
+}
+
+// GORM table, a row is a taken slot of a semaphore,
+// the primary key lets a single holder take each slot.
+type Semaphores struct {
+	Name     string    `gorm:"size:64;primaryKey"`
+	Slot     int       `gorm:"primaryKey;autoIncrement:false"`
+	Holder   string    `gorm:"size:128;index"`
+	ExpireAt time.Time `gorm:"precision:3"`
+}
+
+// GORM table, a row is a token bucket, see `agscheduler.ReserveTokenAt`.
+type RateLimits struct {
+	Name string `gorm:"size:64;primaryKey"`
+	Tat  int64  `gorm:"not null"`
+}
+
 // Stores jobs in a database table using GORM.
-// The table will be created if it doesn't exist in the database.
+// The tables will be created if they don't exist in the database.
 type GORMStore struct {
-	DB        *gorm.DB
-	TableName string
+	DB                  *gorm.DB
+	TableName           string
+	CalendarsTableName  string
+	SemaphoresTableName string
+	RateLimitsTableName string
 }
 
 func (s *GORMStore) Init() error {
 	if s.TableName == "" {
 		s.TableName = TABLE_NAME
 	}
+	if s.CalendarsTableName == "" {
+		s.CalendarsTableName = CALENDARS_TABLE_NAME
+	}
+	if s.SemaphoresTableName == "" {
+		s.SemaphoresTableName = SEMAPHORES_TABLE_NAME
+	}
+	if s.RateLimitsTableName == "" {
+		s.RateLimitsTableName = RATE_LIMITS_TABLE_NAME
+	}
 
 	if err := s.DB.Table(s.TableName).AutoMigrate(&Jobs{}); err != nil {
 		return fmt.Errorf("failed to create table: %s", err)
 	}
+	if err := s.DB.Table(s.CalendarsTableName).AutoMigrate(&Calendars{}); err != nil {
+		return fmt.Errorf("failed to create table: %s", err)
+	}
+	if err := s.DB.Table(s.SemaphoresTableName).AutoMigrate(&Semaphores{}); err != nil {
+		return fmt.Errorf("failed to create table: %s", err)
+	}
+	if err := s.DB.Table(s.RateLimitsTableName).AutoMigrate(&RateLimits{}); err != nil {
+		return fmt.Errorf("failed to create table: %s", err)
+	}
 
 	return nil
 }
@@ -115,6 +165,175 @@ func (s *GORMStore) GetNextRunTime() (time.Time, error) {
 	return nextRunTimeMin, nil
 }
 
+func (s *GORMStore) SetCalendar(c agscheduler.Calendar) error {
+	state, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	cs := Calendars{Name: c.Name, State: state}
+
+	return s.DB.Table(s.CalendarsTableName).Save(cs).Error
+}
+
+func (s *GORMStore) GetCalendar(name string) (agscheduler.Calendar, error) {
+	var cs Calendars
+
+	result := s.DB.Table(s.CalendarsTableName).Where("name = ?", name).Limit(1).Find(&cs)
+	if result.Error != nil {
+		return agscheduler.Calendar{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return agscheduler.Calendar{}, agscheduler.CalendarNotFoundError(name)
+	}
+
+	var c agscheduler.Calendar
+	err := json.Unmarshal(cs.State, &c)
+	return c, err
+}
+
+func (s *GORMStore) GetAllCalendars() ([]agscheduler.Calendar, error) {
+	var csList []*Calendars
+	err := s.DB.Table(s.CalendarsTableName).Find(&csList).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var calendarList []agscheduler.Calendar
+	for _, cs := range csList {
+		var c agscheduler.Calendar
+		if err := json.Unmarshal(cs.State, &c); err != nil {
+			return nil, err
+		}
+		calendarList = append(calendarList, c)
+	}
+
+	return calendarList, nil
+}
+
+func (s *GORMStore) DeleteCalendar(name string) error {
+	return s.DB.Table(s.CalendarsTableName).Where("name = ?", name).Delete(&Calendars{}).Error
+}
+
+// The time of the database, so that the nodes of a cluster agree on it whatever their clocks.
+// The clock of this node is used for the dialects without a known query.
+func (s *GORMStore) now() (time.Time, error) {
+	var query string
+	switch s.DB.Dialector.Name() {
+	case "mysql":
+		query = "SELECT CAST(UNIX_TIMESTAMP(NOW(6)) * 1000000 AS SIGNED)"
+	case "postgres":
+		query = "SELECT CAST(EXTRACT(EPOCH FROM CLOCK_TIMESTAMP()) * 1000000 AS BIGINT)"
+	case "sqlite":
+		query = "SELECT CAST((JULIANDAY('now') - 2440587.5) * 86400000000 AS INTEGER)"
+	case "sqlserver":
+		query = "SELECT DATEDIFF_BIG(MICROSECOND, '1970-01-01', SYSUTCDATETIME())"
+	default:
+		return time.Now(), nil
+	}
+
+	var micros int64
+	if err := s.DB.Raw(query).Scan(&micros).Error; err != nil {
+		return time.Time{}, err
+	}
+
+	return time.UnixMicro(micros), nil
+}
+
+func (s *GORMStore) AcquireSemaphore(name string, holder string, limit int, ttl time.Duration) (bool, error) {
+	now, err := s.now()
+	if err != nil {
+		return false, err
+	}
+	err = s.DB.Table(s.SemaphoresTableName).Where("name = ? AND expire_at <= ?", name, now).Delete(&Semaphores{}).Error
+	if err != nil {
+		return false, err
+	}
+
+	result := s.DB.Table(s.SemaphoresTableName).Where("name = ? AND holder = ?", name, holder).Update("expire_at", now.Add(ttl))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+
+	for slot := 0; slot < limit; slot++ {
+		ss := Semaphores{Name: name, Slot: slot, Holder: holder, ExpireAt: now.Add(ttl)}
+		result := s.DB.Table(s.SemaphoresTableName).Clauses(clause.OnConflict{DoNothing: true}).Create(&ss)
+		if result.Error != nil {
+			return false, result.Error
+		}
+		if result.RowsAffected > 0 {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (s *GORMStore) ReleaseSemaphore(name string, holder string) error {
+	return s.DB.Table(s.SemaphoresTableName).Where("name = ? AND holder = ?", name, holder).Delete(&Semaphores{}).Error
+}
+
+// Retried until the bucket is not updated by another node meanwhile.
+func (s *GORMStore) ReserveToken(name string, rate float64, burst int) (time.Duration, error) {
+	for {
+		var rls RateLimits
+		result := s.DB.Table(s.RateLimitsTableName).Where("name = ?", name).Limit(1).Find(&rls)
+		if result.Error != nil {
+			return 0, result.Error
+		}
+
+		now, err := s.now()
+		if err != nil {
+			return 0, err
+		}
+		tat, wait := agscheduler.ReserveTokenAt(rls.Tat, now, rate, burst)
+		if result.RowsAffected == 0 {
+			result = s.DB.Table(s.RateLimitsTableName).Clauses(clause.OnConflict{DoNothing: true}).Create(&RateLimits{Name: name, Tat: tat})
+		} else {
+			result = s.DB.Table(s.RateLimitsTableName).Where("name = ? AND tat = ?", name, rls.Tat).Update("tat", tat)
+		}
+		if result.Error != nil {
+			return 0, result.Error
+		}
+		if result.RowsAffected > 0 {
+			return wait, nil
+		}
+	}
+}
+
+// Retried until the bucket is not updated by another node meanwhile.
+func (s *GORMStore) ReturnToken(name string, rate float64) error {
+	for {
+		var rls RateLimits
+		result := s.DB.Table(s.RateLimitsTableName).Where("name = ?", name).Limit(1).Find(&rls)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		now, err := s.now()
+		if err != nil {
+			return err
+		}
+		tat := agscheduler.ReturnTokenAt(rls.Tat, now, rate)
+		if tat == rls.Tat {
+			return nil
+		}
+		result = s.DB.Table(s.RateLimitsTableName).Where("name = ? AND tat = ?", name, rls.Tat).Update("tat", tat)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			return nil
+		}
+	}
+}
+
 func (s *GORMStore) Clear() error {
-	return s.DB.Migrator().DropTable(s.TableName)
+	return s.DB.Migrator().DropTable(s.TableName, s.CalendarsTableName, s.SemaphoresTableName, s.RateLimitsTableName)
 }
@@ -14,12 +14,15 @@ func TestGORMStore(t *testing.T) {
 	dsn := "root:123456@tcp(127.0.0.1:3306)/agscheduler?charset=utf8mb4&parseTime=True&loc=UTC"
 	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
 	assert.NoError(t, err)
-	store := &GORMStore{DB: db, TableName: "test_jobs"}
+	store := &GORMStore{DB: db, TableName: "test_jobs", CalendarsTableName: "test_calendars"}
 
 	scheduler := &agscheduler.Scheduler{}
 	err = scheduler.SetStore(store)
 	assert.NoError(t, err)
 
+	testRunTimeMillis(t, store)
+	testSemaphores(t, store)
+	testRateLimits(t, store)
 	testAGScheduler(t, scheduler)
 
 	err = store.Clear()
@@ -2,6 +2,7 @@ package stores
 
 import (
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/kurtloong/agscheduler"
@@ -9,7 +10,17 @@ import (
 
 // Stores jobs in an array in RAM. Provides no persistence support.
 type MemoryStore struct {
-	jobs []agscheduler.Job
+	jobs      []agscheduler.Job
+	calendars []agscheduler.Calendar
+
+	// The expiration of the slots by holder, by semaphore name,
+	// guarded by a mutex as the runs take them concurrently.
+	semaphoresMu sync.Mutex
+	semaphores   map[string]map[string]time.Time
+
+	// The token buckets, by name.
+	rateLimitsMu sync.Mutex
+	rateLimits   map[string]int64
 }
 
 func (s *MemoryStore) Init() error {
@@ -74,6 +85,114 @@ func (s *MemoryStore) GetNextRunTime() (time.Time, error) {
 	return nextRunTimeMin, nil
 }
 
+func (s *MemoryStore) SetCalendar(c agscheduler.Calendar) error {
+	for i, sc := range s.calendars {
+		if sc.Name == c.Name {
+			s.calendars[i] = c
+			return nil
+		}
+	}
+
+	s.calendars = append(s.calendars, c)
+	return nil
+}
+
+func (s *MemoryStore) GetCalendar(name string) (agscheduler.Calendar, error) {
+	for _, c := range s.calendars {
+		if c.Name == name {
+			return c, nil
+		}
+	}
+	return agscheduler.Calendar{}, agscheduler.CalendarNotFoundError(name)
+}
+
+func (s *MemoryStore) GetAllCalendars() ([]agscheduler.Calendar, error) {
+	return s.calendars, nil
+}
+
+func (s *MemoryStore) DeleteCalendar(name string) error {
+	for i, c := range s.calendars {
+		if c.Name == name {
+			s.calendars = append(s.calendars[:i], s.calendars[i+1:]...)
+			return nil
+		}
+	}
+	return agscheduler.CalendarNotFoundError(name)
+}
+
+func (s *MemoryStore) AcquireSemaphore(name string, holder string, limit int, ttl time.Duration) (bool, error) {
+	defer s.semaphoresMu.Unlock()
+
+	s.semaphoresMu.Lock()
+
+	if s.semaphores == nil {
+		s.semaphores = make(map[string]map[string]time.Time)
+	}
+	slots, ok := s.semaphores[name]
+	if !ok {
+		slots = make(map[string]time.Time)
+		s.semaphores[name] = slots
+	}
+
+	now := time.Now()
+	for h, expireAt := range slots {
+		if !expireAt.After(now) {
+			delete(slots, h)
+		}
+	}
+	if _, ok := slots[holder]; !ok && len(slots) >= limit {
+		return false, nil
+	}
+
+	slots[holder] = now.Add(ttl)
+	return true, nil
+}
+
+func (s *MemoryStore) ReleaseSemaphore(name string, holder string) error {
+	defer s.semaphoresMu.Unlock()
+
+	s.semaphoresMu.Lock()
+
+	delete(s.semaphores[name], holder)
+	return nil
+}
+
+func (s *MemoryStore) ReserveToken(name string, rate float64, burst int) (time.Duration, error) {
+	defer s.rateLimitsMu.Unlock()
+
+	s.rateLimitsMu.Lock()
+
+	if s.rateLimits == nil {
+		s.rateLimits = make(map[string]int64)
+	}
+	tat, wait := agscheduler.ReserveTokenAt(s.rateLimits[name], time.Now(), rate, burst)
+	s.rateLimits[name] = tat
+
+	return wait, nil
+}
+
+func (s *MemoryStore) ReturnToken(name string, rate float64) error {
+	defer s.rateLimitsMu.Unlock()
+
+	s.rateLimitsMu.Lock()
+
+	if tat, ok := s.rateLimits[name]; ok {
+		s.rateLimits[name] = agscheduler.ReturnTokenAt(tat, time.Now(), rate)
+	}
+
+	return nil
+}
+
 func (s *MemoryStore) Clear() error {
+	s.calendars = nil
+
+	s.semaphoresMu.Lock()
+	s.semaphores = nil
+	s.semaphoresMu.Unlock()
+
+	s.rateLimitsMu.Lock()
+	s.rateLimits = nil
+	s.rateLimitsMu.Unlock()
+
 	return s.DeleteAllJobs()
 }
@@ -15,6 +15,9 @@ func TestMemoryStore(t *testing.T) {
 	err := scheduler.SetStore(store)
 	assert.NoError(t, err)
 
+	testRunTimeMillis(t, store)
+	testSemaphores(t, store)
+	testRateLimits(t, store)
 	testAGScheduler(t, scheduler)
 
 	err = store.Clear()
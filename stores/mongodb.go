@@ -1,6 +1,7 @@
 package stores
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -13,16 +14,28 @@ import (
 )
 
 const (
-	DATABASE   = "agscheduler"
-	COLLECTION = "jobs"
+	DATABASE             = "agscheduler"
+	COLLECTION           = "jobs"
+	CALENDARS_COLLECTION = "calendars"
+	// A document is a taken slot of a semaphore, its `_id` is `name:slot`
+	// so that a single holder takes each slot.
+	SEMAPHORES_COLLECTION = "semaphores"
+	// A document is a token bucket, see `agscheduler.ReserveTokenAt`.
+	RATE_LIMITS_COLLECTION = "rate_limits"
 )
 
 // Stores jobs in a MongoDB database.
 type MongoDBStore struct {
-	Client     *mongo.Client
-	Database   string
-	Collection string
-	coll       *mongo.Collection
+	Client               *mongo.Client
+	Database             string
+	Collection           string
+	CalendarsCollection  string
+	SemaphoresCollection string
+	RateLimitsCollection string
+	coll                 *mongo.Collection
+	calendarsColl        *mongo.Collection
+	semaphoresColl       *mongo.Collection
+	rateLimitsColl       *mongo.Collection
 }
 
 func (s *MongoDBStore) Init() error {
@@ -32,8 +45,20 @@ func (s *MongoDBStore) Init() error {
 	if s.Collection == "" {
 		s.Collection = COLLECTION
 	}
+	if s.CalendarsCollection == "" {
+		s.CalendarsCollection = CALENDARS_COLLECTION
+	}
+	if s.SemaphoresCollection == "" {
+		s.SemaphoresCollection = SEMAPHORES_COLLECTION
+	}
+	if s.RateLimitsCollection == "" {
+		s.RateLimitsCollection = RATE_LIMITS_COLLECTION
+	}
 
 	s.coll = s.Client.Database(s.Database).Collection(s.Collection)
+	s.calendarsColl = s.Client.Database(s.Database).Collection(s.CalendarsCollection)
+	s.semaphoresColl = s.Client.Database(s.Database).Collection(s.SemaphoresCollection)
+	s.rateLimitsColl = s.Client.Database(s.Database).Collection(s.RateLimitsCollection)
 
 	indexModel := mongo.IndexModel{
 		Keys: bson.M{
@@ -45,6 +70,17 @@ func (s *MongoDBStore) Init() error {
 		return fmt.Errorf("failed to create index: %s", err)
 	}
 
+	// Convert the run times in Unix seconds written by older versions to Unix milliseconds.
+	_, err = s.coll.UpdateMany(ctx,
+		bson.M{"next_run_time": bson.M{"$lt": int64(UNIX_MILLI_MIN)}},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{
+			"next_run_time": bson.M{"$multiply": bson.A{"$next_run_time", int64(1000)}},
+		}}}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to migrate run times: %s", err)
+	}
+
 	return nil
 }
 
@@ -57,7 +93,7 @@ func (s *MongoDBStore) AddJob(j agscheduler.Job) error {
 	_, err = s.coll.InsertOne(ctx,
 		bson.M{
 			"_id":           j.Id,
-			"next_run_time": j.NextRunTime.UTC().Unix(),
+			"next_run_time": j.NextRunTime.UTC().UnixMilli(),
 			"state":         state,
 		},
 	)
@@ -113,7 +149,7 @@ func (s *MongoDBStore) UpdateJob(j agscheduler.Job) error {
 	err = s.coll.FindOneAndReplace(ctx,
 		bson.M{"_id": j.Id},
 		bson.M{
-			"next_run_time": j.NextRunTime.UTC().Unix(),
+			"next_run_time": j.NextRunTime.UTC().UnixMilli(),
 			"state":         state,
 		},
 	).Decode(&result)
@@ -142,10 +178,205 @@ func (s *MongoDBStore) GetNextRunTime() (time.Time, error) {
 		return time.Time{}, nil
 	}
 
-	nextRunTimeMin := time.Unix(result["next_run_time"].(int64), 0).UTC()
+	nextRunTimeMin := runTimeFromUnix(result["next_run_time"].(int64))
 	return nextRunTimeMin, nil
 }
 
+func (s *MongoDBStore) SetCalendar(c agscheduler.Calendar) error {
+	state, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.calendarsColl.ReplaceOne(ctx,
+		bson.M{"_id": c.Name},
+		bson.M{"state": state},
+		options.Replace().SetUpsert(true),
+	)
+
+	return err
+}
+
+func (s *MongoDBStore) GetCalendar(name string) (agscheduler.Calendar, error) {
+	var result bson.M
+	err := s.calendarsColl.FindOne(ctx, bson.M{"_id": name}).Decode(&result)
+	if err == mongo.ErrNoDocuments {
+		return agscheduler.Calendar{}, agscheduler.CalendarNotFoundError(name)
+	}
+	if err != nil {
+		return agscheduler.Calendar{}, err
+	}
+
+	var c agscheduler.Calendar
+	err = json.Unmarshal(result["state"].(primitive.Binary).Data, &c)
+	return c, err
+}
+
+func (s *MongoDBStore) GetAllCalendars() ([]agscheduler.Calendar, error) {
+	cursor, err := s.calendarsColl.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	var calendarList []agscheduler.Calendar
+	for cursor.Next(ctx) {
+		var result bson.M
+		if err := cursor.Decode(&result); err != nil {
+			return nil, err
+		}
+		var c agscheduler.Calendar
+		if err := json.Unmarshal(result["state"].(primitive.Binary).Data, &c); err != nil {
+			return nil, err
+		}
+		calendarList = append(calendarList, c)
+	}
+
+	return calendarList, nil
+}
+
+func (s *MongoDBStore) DeleteCalendar(name string) error {
+	_, err := s.calendarsColl.DeleteOne(ctx, bson.M{"_id": name})
+	return err
+}
+
+// The time of the MongoDB server, so that the nodes of a cluster agree on it whatever their clocks.
+func (s *MongoDBStore) now() (time.Time, error) {
+	var hello struct {
+		LocalTime time.Time `bson:"localTime"`
+	}
+	err := s.Client.Database(s.Database).RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+
+	return hello.LocalTime, err
+}
+
+func (s *MongoDBStore) AcquireSemaphore(name string, holder string, limit int, ttl time.Duration) (bool, error) {
+	t, err := s.now()
+	if err != nil {
+		return false, err
+	}
+	now := t.UnixMilli()
+	expireAt := now + ttl.Milliseconds()
+	_, err = s.semaphoresColl.DeleteMany(ctx, bson.M{"name": name, "expire_at": bson.M{"$lte": now}})
+	if err != nil {
+		return false, err
+	}
+
+	result, err := s.semaphoresColl.UpdateOne(ctx,
+		bson.M{"name": name, "holder": holder},
+		bson.M{"$set": bson.M{"expire_at": expireAt}},
+	)
+	if err != nil {
+		return false, err
+	}
+	if result.MatchedCount > 0 {
+		return true, nil
+	}
+
+	for slot := 0; slot < limit; slot++ {
+		_, err := s.semaphoresColl.InsertOne(ctx,
+			bson.M{
+				"_id":       fmt.Sprintf("%s:%d", name, slot),
+				"name":      name,
+				"holder":    holder,
+				"expire_at": expireAt,
+			},
+		)
+		if mongo.IsDuplicateKeyError(err) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (s *MongoDBStore) ReleaseSemaphore(name string, holder string) error {
+	_, err := s.semaphoresColl.DeleteOne(ctx, bson.M{"name": name, "holder": holder})
+	return err
+}
+
+// Retried until the bucket is not updated by another node meanwhile.
+func (s *MongoDBStore) ReserveToken(name string, rate float64, burst int) (time.Duration, error) {
+	for {
+		now, err := s.now()
+		if err != nil {
+			return 0, err
+		}
+
+		var result bson.M
+		err = s.rateLimitsColl.FindOne(ctx, bson.M{"_id": name}).Decode(&result)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return 0, err
+		}
+
+		if err == mongo.ErrNoDocuments {
+			tat, wait := agscheduler.ReserveTokenAt(0, now, rate, burst)
+			_, err := s.rateLimitsColl.InsertOne(ctx, bson.M{"_id": name, "tat": tat})
+			if mongo.IsDuplicateKeyError(err) {
+				continue
+			}
+			return wait, err
+		}
+
+		old := result["tat"].(int64)
+		tat, wait := agscheduler.ReserveTokenAt(old, now, rate, burst)
+		updateResult, err := s.rateLimitsColl.UpdateOne(ctx,
+			bson.M{"_id": name, "tat": old},
+			bson.M{"$set": bson.M{"tat": tat}},
+		)
+		if err != nil {
+			return 0, err
+		}
+		if updateResult.MatchedCount > 0 {
+			return wait, nil
+		}
+	}
+}
+
+// Retried until the bucket is not updated by another node meanwhile.
+func (s *MongoDBStore) ReturnToken(name string, rate float64) error {
+	for {
+		now, err := s.now()
+		if err != nil {
+			return err
+		}
+
+		var result bson.M
+		err = s.rateLimitsColl.FindOne(ctx, bson.M{"_id": name}).Decode(&result)
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		old := result["tat"].(int64)
+		updateResult, err := s.rateLimitsColl.UpdateOne(ctx,
+			bson.M{"_id": name, "tat": old},
+			bson.M{"$set": bson.M{"tat": agscheduler.ReturnTokenAt(old, now, rate)}},
+		)
+		if err != nil {
+			return err
+		}
+		if updateResult.MatchedCount > 0 {
+			return nil
+		}
+	}
+}
+
 func (s *MongoDBStore) Clear() error {
+	if err := s.Client.Database(s.Database).Collection(s.CalendarsCollection).Drop(ctx); err != nil {
+		return err
+	}
+	if err := s.Client.Database(s.Database).Collection(s.SemaphoresCollection).Drop(ctx); err != nil {
+		return err
+	}
+	if err := s.Client.Database(s.Database).Collection(s.RateLimitsCollection).Drop(ctx); err != nil {
+		return err
+	}
+
 	return s.Client.Database(s.Database).Collection(s.Collection).Drop(ctx)
 }
@@ -3,8 +3,10 @@ package stores
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
@@ -16,14 +18,39 @@ func TestMongoDBStore(t *testing.T) {
 	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(uri))
 	assert.NoError(t, err)
 	defer client.Disconnect(context.Background())
-	store := &MongoDBStore{Client: client, Collection: "test_jobs"}
+	store := &MongoDBStore{Client: client, Collection: "test_jobs", CalendarsCollection: "test_calendars"}
 
 	scheduler := &agscheduler.Scheduler{}
 	err = scheduler.SetStore(store)
 	assert.NoError(t, err)
 
+	testRunTimeMillis(t, store)
+	testSemaphores(t, store)
+	testRateLimits(t, store)
 	testAGScheduler(t, scheduler)
 
 	err = store.Clear()
 	assert.NoError(t, err)
 }
+
+func TestMongoDBStoreMigrateRunTimes(t *testing.T) {
+	uri := "mongodb://127.0.0.1:27017/"
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(uri))
+	assert.NoError(t, err)
+	defer client.Disconnect(context.Background())
+	store := &MongoDBStore{Client: client, Collection: "test_jobs", CalendarsCollection: "test_calendars"}
+
+	runTime := time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+	coll := client.Database(DATABASE).Collection(store.Collection)
+	_, err = coll.InsertOne(ctx, bson.M{"_id": "old", "next_run_time": runTime.Unix(), "state": []byte{}})
+	assert.NoError(t, err)
+
+	err = store.Init()
+	assert.NoError(t, err)
+	nextRunTimeMin, err := store.GetNextRunTime()
+	assert.NoError(t, err)
+	assert.Equal(t, runTime, nextRunTimeMin)
+
+	err = store.Clear()
+	assert.NoError(t, err)
+}
@@ -1,6 +1,8 @@
 package stores
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -11,13 +13,58 @@ import (
 const (
 	JOBS_KEY      = "agscheduler.jobs"
 	RUN_TIMES_KEY = "agscheduler.run_times"
+	CALENDARS_KEY = "agscheduler.calendars"
+	// Prefix of the sorted sets of the semaphore slots, scored by their expiration.
+	SEMAPHORES_KEY = "agscheduler.semaphores"
+	// Hash of the token buckets, see `agscheduler.ReserveTokenAt`.
+	RATE_LIMITS_KEY = "agscheduler.rate_limits"
 )
 
+// Frees the expired slots, then takes or refreshes the slot of the holder when the limit allows it.
+// The time is the time of the Redis server, so that the nodes of a cluster agree on it whatever their clocks.
+var acquireSemaphoreScript = redis.NewScript(`
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now)
+if redis.call("ZSCORE", KEYS[1], ARGV[1]) or redis.call("ZCARD", KEYS[1]) < tonumber(ARGV[2]) then
+	redis.call("ZADD", KEYS[1], now + tonumber(ARGV[3]), ARGV[1])
+	redis.call("PEXPIRE", KEYS[1], ARGV[3])
+	return 1
+end
+return 0
+`)
+
+// Reserves a token of the bucket in the same way as `agscheduler.ReserveTokenAt`, returns the wait in microseconds.
+// The time is the time of the Redis server, as for `acquireSemaphoreScript`.
+var reserveTokenScript = redis.NewScript(`
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+local interval = tonumber(ARGV[2])
+local tat = math.max(tonumber(redis.call("HGET", KEYS[1], ARGV[1]) or 0), now) + interval
+redis.call("HSET", KEYS[1], ARGV[1], tat)
+return math.max(tat - tonumber(ARGV[3]) * interval - now, 0)
+`)
+
+// Gives back a token of the bucket in the same way as `agscheduler.ReturnTokenAt`.
+var returnTokenScript = redis.NewScript(`
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+local tat = tonumber(redis.call("HGET", KEYS[1], ARGV[1]) or 0)
+if tat > now then
+	redis.call("HSET", KEYS[1], ARGV[1], math.max(tat - tonumber(ARGV[2]), now))
+end
+return 0
+`)
+
 // Stores jobs in a Redis database.
 type RedisStore struct {
-	RDB         *redis.Client
-	JobsKey     string
-	RunTimesKey string
+	RDB          *redis.Client
+	JobsKey      string
+	RunTimesKey  string
+	CalendarsKey string
+	// The semaphores are stored in the keys `SemaphoresKey:name`.
+	SemaphoresKey string
+	RateLimitsKey string
 }
 
 func (s *RedisStore) Init() error {
@@ -27,6 +74,40 @@ func (s *RedisStore) Init() error {
 	if s.RunTimesKey == "" {
 		s.RunTimesKey = RUN_TIMES_KEY
 	}
+	if s.CalendarsKey == "" {
+		s.CalendarsKey = CALENDARS_KEY
+	}
+	if s.SemaphoresKey == "" {
+		s.SemaphoresKey = SEMAPHORES_KEY
+	}
+	if s.RateLimitsKey == "" {
+		s.RateLimitsKey = RATE_LIMITS_KEY
+	}
+
+	return s.migrateRunTimes()
+}
+
+// Convert the run times in Unix seconds written by older versions to Unix milliseconds.
+func (s *RedisStore) migrateRunTimes() error {
+	zs, err := s.RDB.ZRangeByScoreWithScores(ctx, s.RunTimesKey, &redis.ZRangeBy{
+		Min: "-inf", Max: fmt.Sprintf("(%d", int64(UNIX_MILLI_MIN)),
+	}).Result()
+	if err != nil {
+		return fmt.Errorf("failed to migrate run times: %s", err)
+	}
+	if len(zs) == 0 {
+		return nil
+	}
+
+	_, err = s.RDB.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, z := range zs {
+			pipe.ZAdd(ctx, s.RunTimesKey, redis.Z{Score: z.Score * 1000, Member: z.Member})
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to migrate run times: %s", err)
+	}
 
 	return nil
 }
@@ -39,7 +120,7 @@ func (s *RedisStore) AddJob(j agscheduler.Job) error {
 
 	_, err = s.RDB.Pipelined(ctx, func(pipe redis.Pipeliner) error {
 		pipe.HSet(ctx, s.JobsKey, j.Id, state)
-		pipe.ZAdd(ctx, s.RunTimesKey, redis.Z{Score: float64(j.NextRunTime.UTC().Unix()), Member: j.Id})
+		pipe.ZAdd(ctx, s.RunTimesKey, redis.Z{Score: float64(j.NextRunTime.UTC().UnixMilli()), Member: j.Id})
 		return nil
 	})
 	if err != nil {
@@ -87,7 +168,7 @@ func (s *RedisStore) UpdateJob(j agscheduler.Job) error {
 
 	_, err = s.RDB.Pipelined(ctx, func(pipe redis.Pipeliner) error {
 		pipe.HSet(ctx, s.JobsKey, j.Id, state)
-		pipe.ZAdd(ctx, s.RunTimesKey, redis.Z{Score: float64(j.NextRunTime.UTC().Unix()), Member: j.Id})
+		pipe.ZAdd(ctx, s.RunTimesKey, redis.Z{Score: float64(j.NextRunTime.UTC().UnixMilli()), Member: j.Id})
 		return nil
 	})
 	if err != nil {
@@ -129,10 +210,105 @@ func (s *RedisStore) GetNextRunTime() (time.Time, error) {
 		return time.Time{}, nil
 	}
 
-	nextRunTimeMin := time.Unix(int64(sliceRunTimes[0].Score), 0).UTC()
+	nextRunTimeMin := runTimeFromUnix(int64(sliceRunTimes[0].Score))
 	return nextRunTimeMin, nil
 }
 
+func (s *RedisStore) SetCalendar(c agscheduler.Calendar) error {
+	state, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	return s.RDB.HSet(ctx, s.CalendarsKey, c.Name, state).Err()
+}
+
+func (s *RedisStore) GetCalendar(name string) (agscheduler.Calendar, error) {
+	state, err := s.RDB.HGet(ctx, s.CalendarsKey, name).Bytes()
+	if err == redis.Nil {
+		return agscheduler.Calendar{}, agscheduler.CalendarNotFoundError(name)
+	}
+	if err != nil {
+		return agscheduler.Calendar{}, err
+	}
+
+	var c agscheduler.Calendar
+	err = json.Unmarshal(state, &c)
+	return c, err
+}
+
+func (s *RedisStore) GetAllCalendars() ([]agscheduler.Calendar, error) {
+	mapStates, err := s.RDB.HGetAll(ctx, s.CalendarsKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var calendarList []agscheduler.Calendar
+	for _, v := range mapStates {
+		var c agscheduler.Calendar
+		if err := json.Unmarshal([]byte(v), &c); err != nil {
+			return nil, err
+		}
+		calendarList = append(calendarList, c)
+	}
+
+	return calendarList, nil
+}
+
+func (s *RedisStore) DeleteCalendar(name string) error {
+	return s.RDB.HDel(ctx, s.CalendarsKey, name).Err()
+}
+
+func (s *RedisStore) semaphoreKey(name string) string {
+	return s.SemaphoresKey + ":" + name
+}
+
+func (s *RedisStore) AcquireSemaphore(name string, holder string, limit int, ttl time.Duration) (bool, error) {
+	ok, err := acquireSemaphoreScript.Run(ctx, s.RDB, []string{s.semaphoreKey(name)},
+		holder, limit, ttl.Milliseconds(),
+	).Int()
+	if err != nil {
+		return false, err
+	}
+
+	return ok == 1, nil
+}
+
+func (s *RedisStore) ReleaseSemaphore(name string, holder string) error {
+	return s.RDB.ZRem(ctx, s.semaphoreKey(name), holder).Err()
+}
+
+func (s *RedisStore) ReserveToken(name string, rate float64, burst int) (time.Duration, error) {
+	interval := int64(float64(time.Second/time.Microsecond) / rate)
+	wait, err := reserveTokenScript.Run(ctx, s.RDB, []string{s.RateLimitsKey},
+		name, interval, max(burst, 1),
+	).Int64()
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(wait) * time.Microsecond, nil
+}
+
+func (s *RedisStore) ReturnToken(name string, rate float64) error {
+	interval := int64(float64(time.Second/time.Microsecond) / rate)
+	return returnTokenScript.Run(ctx, s.RDB, []string{s.RateLimitsKey}, name, interval).Err()
+}
+
 func (s *RedisStore) Clear() error {
+	if err := s.RDB.Del(ctx, s.CalendarsKey, s.RateLimitsKey).Err(); err != nil {
+		return err
+	}
+
+	iter := s.RDB.Scan(ctx, 0, s.semaphoreKey("*"), 0).Iterator()
+	for iter.Next(ctx) {
+		if err := s.RDB.Del(ctx, iter.Val()).Err(); err != nil {
+			return err
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+
 	return s.DeleteAllJobs()
 }
@@ -2,6 +2,7 @@ package stores
 
 import (
 	"testing"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
@@ -16,17 +17,51 @@ func TestRedisStore(t *testing.T) {
 	rdb := redis.NewClient(opt)
 	defer rdb.Close()
 	store := &RedisStore{
-		RDB:         rdb,
-		JobsKey:     "agscheduler.test_jobs",
-		RunTimesKey: "agscheduler.test_run_times",
+		RDB:          rdb,
+		JobsKey:      "agscheduler.test_jobs",
+		RunTimesKey:  "agscheduler.test_run_times",
+		CalendarsKey: "agscheduler.test_calendars",
 	}
 
 	scheduler := &agscheduler.Scheduler{}
 	err = scheduler.SetStore(store)
 	assert.NoError(t, err)
 
+	testRunTimeMillis(t, store)
+	testSemaphores(t, store)
+	testRateLimits(t, store)
 	testAGScheduler(t, scheduler)
 
 	err = store.Clear()
 	assert.NoError(t, err)
 }
+
+func TestRedisStoreMigrateRunTimes(t *testing.T) {
+	url := "redis://127.0.0.1:6379/0"
+	opt, err := redis.ParseURL(url)
+	assert.NoError(t, err)
+	rdb := redis.NewClient(opt)
+	defer rdb.Close()
+	store := &RedisStore{
+		RDB:          rdb,
+		JobsKey:      "agscheduler.test_jobs",
+		RunTimesKey:  "agscheduler.test_run_times",
+		CalendarsKey: "agscheduler.test_calendars",
+	}
+
+	runTime := time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+	err = rdb.ZAdd(ctx, store.RunTimesKey, redis.Z{Score: float64(runTime.Unix()), Member: "old"}).Err()
+	assert.NoError(t, err)
+
+	err = store.Init()
+	assert.NoError(t, err)
+	score, err := rdb.ZScore(ctx, store.RunTimesKey, "old").Result()
+	assert.NoError(t, err)
+	assert.Equal(t, float64(runTime.UnixMilli()), score)
+	nextRunTimeMin, err := store.GetNextRunTime()
+	assert.NoError(t, err)
+	assert.Equal(t, runTime, nextRunTimeMin)
+
+	err = store.Clear()
+	assert.NoError(t, err)
+}
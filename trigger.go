@@ -0,0 +1,271 @@
+package agscheduler
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	pb "github.com/kurtloong/agscheduler/services/proto"
+)
+
+// constant indicating how the child triggers of a combined trigger are joined
+const (
+	// Runs at the run times of any child trigger.
+	OPERATOR_OR = "or"
+	// Runs at the run times matched by all child triggers.
+	OPERATOR_AND = "and"
+)
+
+// The most times the child triggers of `OPERATOR_AND` are asked to agree, before giving up.
+const COMBINED_MAX_ROUNDS = 1000
+
+// A child trigger of a `TYPE_COMBINED` job.
+type Trigger struct {
+	// Optional: `TYPE_DATETIME` | `TYPE_INTERVAL` | `TYPE_CRON` | `TYPE_RRULE` | `TYPE_COMBINED`
+	Type string `json:"type"`
+	// It can be used when Type is `TYPE_DATETIME`,
+	// as the grid start when Type is `TYPE_INTERVAL`,
+	// or as the default `DTSTART` when Type is `TYPE_RRULE`.
+	StartAt string `json:"start_at"`
+	// It can be used when Type is `TYPE_DATETIME`,
+	// every millisecond from `StartAt` to `EndAt` is matched, which is a date window.
+	EndAt string `json:"end_at"`
+	// It can be used when Type is `TYPE_INTERVAL`.
+	Interval string `json:"interval"`
+	// It can be used when Type is `TYPE_CRON`.
+	CronExpr string `json:"cron_expr"`
+	// It can be used when Type is `TYPE_RRULE`.
+	RRule string `json:"rrule"`
+	// It can be used when Type is `TYPE_COMBINED`.
+	// Optional: `OPERATOR_OR` | `OPERATOR_AND`
+	Operator string `json:"operator"`
+	// It can be used when Type is `TYPE_COMBINED`.
+	Triggers []Trigger `json:"triggers"`
+}
+
+// A datetime trigger, which is a single time when `end` equals `start`,
+// otherwise every millisecond from `start` to `end` is matched.
+type datetimeSchedule struct {
+	start, end time.Time
+}
+
+func (ds datetimeSchedule) Next(t time.Time) time.Time {
+	if t.Before(ds.start) {
+		return ds.start
+	}
+	if next := t.Truncate(time.Millisecond).Add(time.Millisecond); !next.After(ds.end) {
+		return next
+	}
+
+	return time.Time{}
+}
+
+// An interval trigger, on the grid of `anchor` when it is set.
+type intervalSchedule struct {
+	d      time.Duration
+	anchor time.Time
+}
+
+func (is intervalSchedule) Next(t time.Time) time.Time {
+	if is.anchor.IsZero() {
+		return t.Add(is.d)
+	}
+	if t.Before(is.anchor) {
+		return is.anchor
+	}
+
+	return is.anchor.Add((t.Sub(is.anchor)/is.d + 1) * is.d)
+}
+
+type combinedSchedule struct {
+	operator string
+	children []cronSchedule
+}
+
+func (cs combinedSchedule) Next(t time.Time) time.Time {
+	if cs.operator == OPERATOR_OR {
+		var next time.Time
+		for _, c := range cs.children {
+			if n := c.Next(t); !n.IsZero() && (next.IsZero() || n.Before(next)) {
+				next = n
+			}
+		}
+		return next
+	}
+
+	// Move to the latest next run time of the children until they all agree.
+	from := t
+	for i := 0; i < COMBINED_MAX_ROUNDS; i++ {
+		var latest time.Time
+		agreed := true
+		for j, c := range cs.children {
+			n := c.Next(from)
+			if n.IsZero() {
+				return time.Time{}
+			}
+			if j > 0 && !n.Equal(latest) {
+				agreed = false
+			}
+			if n.After(latest) {
+				latest = n
+			}
+		}
+		if agreed {
+			return latest
+		}
+		from = latest.Add(-time.Nanosecond)
+	}
+
+	return time.Time{}
+}
+
+func joinTriggerField(prefix string, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// Build the schedule of the trigger in `loc`.
+// `prefix` is the path of the trigger in the job, such as `triggers[1]`,
+// which is prepended to the fields of the returned `JobScheduleError`.
+// `H` items of cron expressions are hashed from the id of `j`, which also gives the DST policies.
+func (t Trigger) schedule(prefix string, j *Job, loc *time.Location) (cronSchedule, error) {
+	newErr := func(name string, value string, err error) error {
+		return &JobScheduleError{Field: joinTriggerField(prefix, name), Value: value, Err: err}
+	}
+
+	switch strings.ToLower(t.Type) {
+	case TYPE_DATETIME:
+		start, err := time.ParseInLocation(time.DateTime, t.StartAt, loc)
+		if err != nil {
+			return nil, newErr("start_at", t.StartAt, err)
+		}
+		if t.EndAt == "" {
+			return datetimeSchedule{start: start, end: start}, nil
+		}
+		end, err := time.ParseInLocation(time.DateTime, t.EndAt, loc)
+		if err != nil {
+			return nil, newErr("end_at", t.EndAt, err)
+		}
+		if end.Before(start) {
+			return nil, newErr("end_at", t.EndAt, errors.New("before `start_at`"))
+		}
+		return datetimeSchedule{start: start, end: end}, nil
+	case TYPE_INTERVAL:
+		d, err := time.ParseDuration(t.Interval)
+		if err != nil {
+			return nil, newErr("interval", t.Interval, err)
+		}
+		if d <= 0 {
+			return nil, newErr("interval", t.Interval, errors.New("must be positive"))
+		}
+		is := intervalSchedule{d: d}
+		if t.StartAt != "" {
+			if is.anchor, err = time.ParseInLocation(time.DateTime, t.StartAt, loc); err != nil {
+				return nil, newErr("start_at", t.StartAt, err)
+			}
+		}
+		return is, nil
+	case TYPE_CRON:
+		expr, err := parseCronExpr(t.CronExpr, j.Id)
+		if err != nil {
+			return nil, newErr("cron_expr", t.CronExpr, err)
+		}
+		return newDSTSchedule(expr, loc, j.DSTGap, j.DSTOverlap), nil
+	case TYPE_RRULE:
+		rs, err := parseRRuleSet(t.RRule, t.StartAt, loc)
+		if err != nil {
+			return nil, newErr("rrule", t.RRule, err)
+		}
+		return rs, nil
+	case TYPE_COMBINED:
+		operator := strings.ToLower(t.Operator)
+		if operator != OPERATOR_OR && operator != OPERATOR_AND {
+			return nil, newErr("operator", t.Operator, errors.New("unknown"))
+		}
+		if len(t.Triggers) == 0 {
+			return nil, newErr("triggers", "", errors.New("at least one trigger is required"))
+		}
+		cs := combinedSchedule{operator: operator}
+		for i, child := range t.Triggers {
+			s, err := child.schedule(joinTriggerField(prefix, fmt.Sprintf("triggers[%d]", i)), j, loc)
+			if err != nil {
+				return nil, err
+			}
+			cs.children = append(cs.children, s)
+		}
+		return cs, nil
+	default:
+		return nil, newErr("type", t.Type, errors.New("unknown"))
+	}
+}
+
+// The combined trigger made of the fields of the job.
+func (j *Job) combinedTrigger() Trigger {
+	return Trigger{Type: TYPE_COMBINED, Operator: j.Operator, Triggers: j.Triggers}
+}
+
+// Build the schedule of a `TYPE_COMBINED` job.
+func (j *Job) combinedSchedule(loc *time.Location) (cronSchedule, error) {
+	s, err := j.combinedTrigger().schedule("", j, loc)
+	if err != nil {
+		if scheduleErr, ok := err.(*JobScheduleError); ok {
+			scheduleErr.FullName = j.FullName()
+		}
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Used to gRPC Protobuf
+func TriggerToPbTriggerPtr(t Trigger) *pb.Trigger {
+	pbT := &pb.Trigger{
+		Type:     t.Type,
+		StartAt:  t.StartAt,
+		EndAt:    t.EndAt,
+		Interval: t.Interval,
+		CronExpr: t.CronExpr,
+		Rrule:    t.RRule,
+		Operator: t.Operator,
+	}
+	for _, child := range t.Triggers {
+		pbT.Triggers = append(pbT.Triggers, TriggerToPbTriggerPtr(child))
+	}
+
+	return pbT
+}
+
+// Used to gRPC Protobuf
+func PbTriggerPtrToTrigger(pbT *pb.Trigger) Trigger {
+	return Trigger{
+		Type:     pbT.GetType(),
+		StartAt:  pbT.GetStartAt(),
+		EndAt:    pbT.GetEndAt(),
+		Interval: pbT.GetInterval(),
+		CronExpr: pbT.GetCronExpr(),
+		RRule:    pbT.GetRrule(),
+		Operator: pbT.GetOperator(),
+		Triggers: pbTriggersPtrToTriggers(pbT.GetTriggers()),
+	}
+}
+
+func triggersToPbTriggersPtr(ts []Trigger) []*pb.Trigger {
+	var pbTs []*pb.Trigger
+	for _, t := range ts {
+		pbTs = append(pbTs, TriggerToPbTriggerPtr(t))
+	}
+
+	return pbTs
+}
+
+func pbTriggersPtrToTriggers(pbTs []*pb.Trigger) []Trigger {
+	var ts []Trigger
+	for _, pbT := range pbTs {
+		ts = append(ts, PbTriggerPtrToTrigger(pbT))
+	}
+
+	return ts
+}
@@ -0,0 +1,167 @@
+package agscheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTriggerSchedule(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, c := range []struct {
+		name    string
+		trigger Trigger
+		nexts   []time.Time
+		// Whether there is no more run time after `nexts`.
+		ends bool
+	}{
+		{
+			"or",
+			Trigger{Type: TYPE_COMBINED, Operator: OPERATOR_OR, Triggers: []Trigger{
+				{Type: TYPE_CRON, CronExpr: "0 9 * * MON"},
+				{Type: TYPE_CRON, CronExpr: "30 17 * * FRI"},
+			}},
+			[]time.Time{
+				time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+				time.Date(2024, 1, 5, 17, 30, 0, 0, time.UTC),
+				time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC),
+			},
+			false,
+		},
+		{
+			"and with a date window",
+			Trigger{Type: TYPE_COMBINED, Operator: OPERATOR_AND, Triggers: []Trigger{
+				{Type: TYPE_CRON, CronExpr: "0 12 * * *"},
+				{Type: TYPE_DATETIME, StartAt: "2024-01-10 00:00:00", EndAt: "2024-01-11 23:59:59"},
+			}},
+			[]time.Time{
+				time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC),
+				time.Date(2024, 1, 11, 12, 0, 0, 0, time.UTC),
+			},
+			true,
+		},
+		{
+			"and with an anchored interval",
+			Trigger{Type: TYPE_COMBINED, Operator: OPERATOR_AND, Triggers: []Trigger{
+				{Type: TYPE_INTERVAL, Interval: "90m", StartAt: "2024-01-01 00:00:00"},
+				{Type: TYPE_CRON, CronExpr: "0 * * * *"},
+			}},
+			[]time.Time{
+				time.Date(2024, 1, 1, 3, 0, 0, 0, time.UTC),
+				time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC),
+				time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+			},
+			false,
+		},
+		{
+			"nested",
+			Trigger{Type: TYPE_COMBINED, Operator: OPERATOR_OR, Triggers: []Trigger{
+				{Type: TYPE_DATETIME, StartAt: "2024-01-01 08:00:00"},
+				{Type: TYPE_COMBINED, Operator: OPERATOR_AND, Triggers: []Trigger{
+					{Type: TYPE_RRULE, RRule: "FREQ=WEEKLY;BYDAY=TU", StartAt: "2024-01-01 10:00:00"},
+					{Type: TYPE_DATETIME, StartAt: "2024-01-01 00:00:00", EndAt: "2024-01-09 23:59:59"},
+				}},
+			}},
+			[]time.Time{
+				time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC),
+				time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC),
+				time.Date(2024, 1, 9, 10, 0, 0, 0, time.UTC),
+			},
+			true,
+		},
+	} {
+		s, err := c.trigger.schedule("", &Job{Id: "seed"}, time.UTC)
+		assert.NoError(t, err, c.name)
+
+		nexts := make([]time.Time, 0)
+		for next := s.Next(from); !next.IsZero() && len(nexts) < len(c.nexts)+1; next = s.Next(next) {
+			nexts = append(nexts, next)
+		}
+		if !c.ends {
+			nexts = nexts[:len(c.nexts)]
+		}
+		assert.Equal(t, c.nexts, nexts, c.name)
+	}
+}
+
+func TestTriggerScheduleError(t *testing.T) {
+	for field, trigger := range map[string]Trigger{
+		"type":     {Type: "weekly"},
+		"operator": {Type: TYPE_COMBINED, Operator: "xor", Triggers: []Trigger{{Type: TYPE_CRON, CronExpr: "* * * * *"}}},
+		"triggers": {Type: TYPE_COMBINED, Operator: OPERATOR_OR},
+		"triggers[1].cron_expr": {Type: TYPE_COMBINED, Operator: OPERATOR_OR, Triggers: []Trigger{
+			{Type: TYPE_CRON, CronExpr: "* * * * *"},
+			{Type: TYPE_CRON, CronExpr: "* *"},
+		}},
+		"triggers[0].triggers[0].end_at": {Type: TYPE_COMBINED, Operator: OPERATOR_AND, Triggers: []Trigger{
+			{Type: TYPE_COMBINED, Operator: OPERATOR_AND, Triggers: []Trigger{
+				{Type: TYPE_DATETIME, StartAt: "2024-01-02 00:00:00", EndAt: "2024-01-01 00:00:00"},
+			}},
+		}},
+		"triggers[0].interval": {Type: TYPE_COMBINED, Operator: OPERATOR_OR, Triggers: []Trigger{
+			{Type: TYPE_INTERVAL, Interval: "0s"},
+		}},
+	} {
+		_, err := trigger.schedule("", &Job{Id: "seed"}, time.UTC)
+		scheduleErr := &JobScheduleError{}
+		assert.ErrorAs(t, err, &scheduleErr, field)
+		assert.Equal(t, field, scheduleErr.Field)
+	}
+}
+
+func TestCalcNextRunTimeCombined(t *testing.T) {
+	j := Job{
+		Name: "Job", Type: TYPE_COMBINED, Timezone: "Asia/Shanghai", Operator: OPERATOR_AND,
+		Triggers: []Trigger{
+			{Type: TYPE_CRON, CronExpr: "0 9 * * *"},
+			{Type: TYPE_DATETIME, StartAt: "2024-01-01 00:00:00", EndAt: "2024-01-01 23:59:59"},
+		},
+	}
+
+	from := time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)
+	nextRunTime, err := calcNextRunTime(j, from, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC), nextRunTime)
+
+	timezone, _ := time.LoadLocation(j.Timezone)
+	nextRunTime, err = calcNextRunTime(j, nextRunTime, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, maxRunTime(timezone), nextRunTime)
+
+	j.Operator = ""
+	_, err = calcNextRunTime(j, from, nil)
+	scheduleErr := &JobScheduleError{}
+	assert.ErrorAs(t, err, &scheduleErr)
+	assert.Equal(t, "operator", scheduleErr.Field)
+	assert.Equal(t, j.FullName(), scheduleErr.FullName)
+}
+
+func TestTriggerToPbTriggerPtr(t *testing.T) {
+	tr := Trigger{Type: TYPE_COMBINED, Operator: OPERATOR_OR, Triggers: []Trigger{
+		{Type: TYPE_CRON, CronExpr: "0 9 * * *"},
+		{Type: TYPE_COMBINED, Operator: OPERATOR_AND, Triggers: []Trigger{
+			{Type: TYPE_RRULE, RRule: "FREQ=DAILY", StartAt: "2024-01-01 00:00:00"},
+			{Type: TYPE_DATETIME, StartAt: "2024-01-01 00:00:00", EndAt: "2024-02-01 00:00:00"},
+		}},
+	}}
+
+	assert.Equal(t, tr, PbTriggerPtrToTrigger(TriggerToPbTriggerPtr(tr)))
+}
+
+func TestJobStateLoadTriggers(t *testing.T) {
+	j := getJob()
+	j.Type = TYPE_COMBINED
+	j.Operator = OPERATOR_OR
+	j.Triggers = []Trigger{
+		{Type: TYPE_CRON, CronExpr: "0 9 * * *"},
+		{Type: TYPE_COMBINED, Operator: OPERATOR_AND, Triggers: []Trigger{{Type: TYPE_INTERVAL, Interval: "1h"}}},
+	}
+
+	state, err := StateDump(j)
+	assert.NoError(t, err)
+	jL, err := StateLoad(state)
+	assert.NoError(t, err)
+	assert.Equal(t, j.Triggers, jL.Triggers)
+}
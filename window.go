@@ -0,0 +1,229 @@
+package agscheduler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// constant indicating how a run due outside the allowed windows of its job is handled
+const (
+	// The run is deferred to the start of the next allowed window.
+	WINDOW_DEFER = "defer"
+	// The run is skipped, the job runs again at its next run time.
+	WINDOW_SKIP = "skip"
+)
+
+// The window starting on `day` in `loc`, when the weekdays of the window match it.
+func (w CalendarWindow) on(day time.Time, loc *time.Location) (time.Time, time.Time, bool) {
+	if len(w.Weekdays) > 0 && !slices.Contains(w.Weekdays, int(day.Weekday())) {
+		return time.Time{}, time.Time{}, false
+	}
+
+	start, _ := parseTimeOfDay(w.Start)
+	end, _ := parseTimeOfDay(w.End)
+	y, m, d := day.Date()
+	windowStart := time.Date(y, m, d, 0, 0, 0, 0, loc).Add(start)
+	windowEnd := time.Date(y, m, d, 0, 0, 0, 0, loc).Add(end)
+	if end <= start {
+		windowEnd = time.Date(y, m, d+1, 0, 0, 0, 0, loc).Add(end)
+	}
+
+	return windowStart, windowEnd, true
+}
+
+// Returns the end of the window `t` is in, when `t` is in any of the windows.
+// Windows ending on the next day may start on the day before.
+func windowsUntil(windows []CalendarWindow, t time.Time, loc *time.Location) (time.Time, bool) {
+	t = t.In(loc)
+	y, m, d := t.Date()
+	dayStart := time.Date(y, m, d, 0, 0, 0, 0, loc)
+
+	for _, w := range windows {
+		for _, day := range []time.Time{dayStart.AddDate(0, 0, -1), dayStart} {
+			start, end, ok := w.on(day, loc)
+			if ok && !t.Before(start) && t.Before(end) {
+				return end, true
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// Returns the start of the first window after `t`, a zero time when there is no window.
+func nextWindowStart(windows []CalendarWindow, t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	y, m, d := t.Date()
+
+	var next time.Time
+	for _, w := range windows {
+		for i := 0; i <= 7; i++ {
+			start, _, ok := w.on(time.Date(y, m, d+i, 0, 0, 0, 0, loc), loc)
+			if ok && start.After(t) {
+				if next.IsZero() || start.Before(next) {
+					next = start
+				}
+				break
+			}
+		}
+	}
+
+	return next
+}
+
+func checkAllowedWindows(windows []CalendarWindow, policy string) (string, error) {
+	for _, w := range windows {
+		if _, err := parseTimeOfDay(w.Start); err != nil {
+			return "allowed_windows", err
+		}
+		if _, err := parseTimeOfDay(w.End); err != nil {
+			return "allowed_windows", err
+		}
+		if err := checkRange("weekday", w.Weekdays, 0, 6); err != nil {
+			return "allowed_windows", err
+		}
+	}
+
+	switch strings.ToLower(policy) {
+	case "", WINDOW_DEFER, WINDOW_SKIP:
+	default:
+		return "window_policy", errors.New("unknown")
+	}
+
+	return "", nil
+}
+
+// Runs deferred to the start of the next allowed window, by job id.
+// The runs of a job deferred to the same window are merged into one.
+type deferredRuns struct {
+	mu sync.Mutex
+
+	timers map[string]*time.Timer
+}
+
+// Returns false when a run of the job is already deferred.
+func (dr *deferredRuns) add(j Job, until time.Time, run func(Job)) bool {
+	defer dr.mu.Unlock()
+
+	dr.mu.Lock()
+
+	if dr.timers == nil {
+		dr.timers = make(map[string]*time.Timer)
+	}
+	if _, ok := dr.timers[j.Id]; ok {
+		return false
+	}
+
+	dr.timers[j.Id] = time.AfterFunc(time.Until(until), func() {
+		dr.mu.Lock()
+		delete(dr.timers, j.Id)
+		dr.mu.Unlock()
+
+		run(j)
+	})
+
+	return true
+}
+
+// Cancel the deferred run of a job, returns false when there is none.
+func (dr *deferredRuns) remove(id string) bool {
+	defer dr.mu.Unlock()
+
+	dr.mu.Lock()
+
+	timer, ok := dr.timers[id]
+	if ok {
+		timer.Stop()
+		delete(dr.timers, id)
+	}
+
+	return ok
+}
+
+func (dr *deferredRuns) stop() {
+	defer dr.mu.Unlock()
+
+	dr.mu.Lock()
+
+	for id, timer := range dr.timers {
+		timer.Stop()
+		delete(dr.timers, id)
+	}
+}
+
+// Returns whether the job is allowed to run now,
+// otherwise the run is deferred or skipped by `WindowPolicy` and recorded.
+func (s *Scheduler) checkAllowedWindows(j Job, now time.Time) bool {
+	if len(j.AllowedWindows) == 0 {
+		return true
+	}
+
+	timezone, err := loadLocation(j.Timezone)
+	if err != nil {
+		timezone = time.UTC
+	}
+	if _, ok := windowsUntil(j.AllowedWindows, now, timezone); ok {
+		return true
+	}
+
+	if strings.ToLower(j.WindowPolicy) != WINDOW_SKIP {
+		if until := nextWindowStart(j.AllowedWindows, now, timezone); !until.IsZero() {
+			if s.deferred.add(j, until, s.runDeferred) {
+				slog.Info(fmt.Sprintf("Job `%s` is outside allowed windows, run deferred to `%s`.\n", j.FullName(), until.Format(time.RFC3339Nano)))
+				s.records.event(j, RECORD_DEFERRED, map[string]any{"deferred_to": until.Format(time.RFC3339Nano)}, s.MaxRecords)
+				return false
+			}
+			slog.Info(fmt.Sprintf("Job `%s` is outside allowed windows, run merged into the deferred run.\n", j.FullName()))
+			s.records.event(j, RECORD_SKIPPED, map[string]any{"reason": "merged into the deferred run"}, s.MaxRecords)
+			s.jobFinished(j, WORKFLOW_SKIPPED, nil)
+			return false
+		}
+	}
+
+	slog.Info(fmt.Sprintf("Job `%s` is outside allowed windows, run skipped.\n", j.FullName()))
+	s.records.event(j, RECORD_SKIPPED, map[string]any{"reason": "outside allowed windows"}, s.MaxRecords)
+	s.jobFinished(j, WORKFLOW_SKIPPED, nil)
+
+	return false
+}
+
+// Called at the start of the window a run is deferred to,
+// the job is read again so that it is not run after being paused or deleted.
+// One-off jobs are removed from the store once due, their deferred run uses the job as deferred,
+// and `DeleteJob` cancels it.
+func (s *Scheduler) runDeferred(j Job) {
+	sJ, err := s.GetJob(j.Id)
+	var nfErr JobNotFoundError
+	if j.Type == TYPE_DATETIME && errors.As(err, &nfErr) {
+		sJ, err = j, nil
+	}
+	if err != nil || sJ.Status == STATUS_PAUSED || s.IsPaused() {
+		slog.Info(fmt.Sprintf("Job `%s` deferred run skipped.\n", j.FullName()))
+		s.jobFinished(j, WORKFLOW_SKIPPED, nil)
+		return
+	}
+	sJ.WorkflowRunId = j.WorkflowRunId
+	sJ.TriggeredBy = j.TriggeredBy
+	sJ.TriggerResult = j.TriggerResult
+
+	slog.Info(fmt.Sprintf("Job `%s` deferred run start.\n", sJ.FullName()))
+
+	if sJ.WorkflowRunId == "" {
+		js, err := s.GetAllJobs()
+		if err != nil {
+			slog.Error(fmt.Sprintf("Scheduler get all jobs error: %s\n", err))
+			return
+		}
+		err = s.scheduleWorkflow(sJ, js)
+	} else if err = s._scheduleJob(sJ); err != nil {
+		s.jobFinished(sJ, RECORD_FAILED, nil)
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("Scheduler schedule job `%s` error: %s\n", sJ.FullName(), err))
+	}
+}
@@ -0,0 +1,116 @@
+package agscheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWindowsUntil(t *testing.T) {
+	ny, _ := loadLocation("America/New_York")
+	windows := []CalendarWindow{
+		{Start: "01:00", End: "05:00"},
+		// Friday and Saturday nights.
+		{Weekdays: []int{5, 6}, Start: "22:00", End: "02:00"},
+	}
+
+	for _, c := range []struct {
+		t     time.Time
+		until time.Time
+		ok    bool
+	}{
+		{time.Date(2024, 1, 3, 3, 0, 0, 0, ny), time.Date(2024, 1, 3, 5, 0, 0, 0, ny), true},
+		{time.Date(2024, 1, 3, 5, 0, 0, 0, ny), time.Time{}, false},
+		{time.Date(2024, 1, 3, 23, 0, 0, 0, ny), time.Time{}, false},
+		{time.Date(2024, 1, 5, 23, 0, 0, 0, ny), time.Date(2024, 1, 6, 2, 0, 0, 0, ny), true},
+		// Saturday 00:30, in the window starting on Friday.
+		{time.Date(2024, 1, 6, 0, 30, 0, 0, ny), time.Date(2024, 1, 6, 2, 0, 0, 0, ny), true},
+	} {
+		until, ok := windowsUntil(windows, c.t, ny)
+		assert.Equal(t, c.ok, ok, c.t.String())
+		assert.True(t, c.until.Equal(until), "%s: %s != %s", c.t, c.until, until)
+	}
+}
+
+func TestNextWindowStart(t *testing.T) {
+	ny, _ := loadLocation("America/New_York")
+	windows := []CalendarWindow{
+		// Mondays.
+		{Weekdays: []int{1}, Start: "01:00", End: "05:00"},
+		{Weekdays: []int{3}, Start: "22:00", End: "23:00"},
+	}
+
+	for t0, next := range map[time.Time]time.Time{
+		// Wednesday.
+		time.Date(2024, 1, 3, 12, 0, 0, 0, ny): time.Date(2024, 1, 3, 22, 0, 0, 0, ny),
+		time.Date(2024, 1, 3, 22, 0, 0, 0, ny): time.Date(2024, 1, 8, 1, 0, 0, 0, ny),
+		// Monday, in the window.
+		time.Date(2024, 1, 8, 2, 0, 0, 0, ny): time.Date(2024, 1, 10, 22, 0, 0, 0, ny),
+	} {
+		assert.True(t, next.Equal(nextWindowStart(windows, t0, ny)), t0.String())
+	}
+
+	assert.True(t, nextWindowStart(nil, time.Now(), ny).IsZero())
+}
+
+func TestCheckAllowedWindows(t *testing.T) {
+	for field, c := range map[string]struct {
+		windows []CalendarWindow
+		policy  string
+	}{
+		"":                {[]CalendarWindow{{Weekdays: []int{0, 6}, Start: "01:00", End: "05:00:30"}}, WINDOW_SKIP},
+		"allowed_windows": {[]CalendarWindow{{Start: "1am", End: "05:00"}}, ""},
+		"window_policy":   {nil, "wait"},
+	} {
+		f, err := checkAllowedWindows(c.windows, c.policy)
+		assert.Equal(t, field, f)
+		assert.Equal(t, field != "", err != nil, field)
+	}
+}
+
+// A window of an hour starting `d` after now, in UTC.
+func getWindowAfter(d time.Duration) CalendarWindow {
+	start := time.Now().UTC().Truncate(time.Second).Add(d)
+	return CalendarWindow{Start: start.Format(time.TimeOnly), End: start.Add(time.Hour).Format(time.TimeOnly)}
+}
+
+func TestSchedulerCheckAllowedWindows(t *testing.T) {
+	s := &Scheduler{}
+	j := Job{Id: "1", Name: "Job", Timezone: "UTC", AllowedWindows: []CalendarWindow{getWindowAfter(-time.Minute)}}
+	assert.True(t, s.checkAllowedWindows(j, time.Now()))
+
+	j.AllowedWindows = []CalendarWindow{getWindowAfter(2 * time.Hour)}
+	j.WindowPolicy = WINDOW_SKIP
+	assert.False(t, s.checkAllowedWindows(j, time.Now()))
+	assert.Equal(t, RECORD_SKIPPED, s.records.get(j.Id)[0].Status)
+
+	j.WindowPolicy = WINDOW_DEFER
+	assert.False(t, s.checkAllowedWindows(j, time.Now()))
+	assert.Equal(t, RECORD_DEFERRED, s.records.get(j.Id)[0].Status)
+	assert.Len(t, s.deferred.timers, 1)
+
+	// Merged into the deferred run.
+	assert.False(t, s.checkAllowedWindows(j, time.Now()))
+	assert.Equal(t, RECORD_SKIPPED, s.records.get(j.Id)[0].Status)
+	assert.Len(t, s.deferred.timers, 1)
+
+	s.deferred.stop()
+	assert.Empty(t, s.deferred.timers)
+}
+
+func TestSchedulerScheduleJobOutsideWindows(t *testing.T) {
+	s := &Scheduler{}
+	j := Job{
+		Id: "1", Name: "Job", Timezone: "UTC", FuncName: "unregistered",
+		AllowedWindows: []CalendarWindow{getWindowAfter(2 * time.Hour)}, WindowPolicy: WINDOW_SKIP,
+	}
+
+	// In standalone mode, as for the chained runs.
+	err := s._scheduleJob(j)
+	assert.NoError(t, err)
+
+	records := s.records.get(j.Id)
+	assert.Len(t, records, 1)
+	assert.Equal(t, RECORD_SKIPPED, records[0].Status)
+}
@@ -0,0 +1,210 @@
+package agscheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// A run of a job sent to a worker.
+type WorkerRun struct {
+	Id  string
+	Job Job
+}
+
+// Reported by a worker when a run is finished,
+// the run failed if `Error` is not empty.
+type WorkerResult struct {
+	RunId  string
+	Result map[string]any
+	Error  string
+}
+
+// An out-of-process worker connected to the scheduler,
+// jobs of the functions it advertised are sent to it
+// unless the functions are registered in the scheduler.
+type Worker struct {
+	Id    string
+	Funcs []string
+
+	runs      chan WorkerRun
+	done      chan struct{}
+	closeOnce sync.Once
+
+	mu sync.Mutex
+
+	// def: map[<run id>]<result chan>
+	pending map[string]chan WorkerResult
+}
+
+// Receive the runs to send to the worker.
+func (w *Worker) Runs() <-chan WorkerRun {
+	return w.runs
+}
+
+// Closed when the worker is disconnected.
+func (w *Worker) Done() <-chan struct{} {
+	return w.done
+}
+
+// Finish a run with the result reported by the worker,
+// results of unknown or finished runs are ignored.
+func (w *Worker) Report(r WorkerResult) {
+	defer w.mu.Unlock()
+
+	w.mu.Lock()
+
+	if ch, ok := w.pending[r.RunId]; ok {
+		delete(w.pending, r.RunId)
+		ch <- r
+	}
+}
+
+func (w *Worker) close() {
+	w.closeOnce.Do(func() { close(w.done) })
+}
+
+// Send a run to the worker and wait for its result.
+func (w *Worker) run(ctx context.Context, j Job) (map[string]any, error) {
+	id := strings.Replace(uuid.New().String(), "-", "", -1)[:16]
+	ch := make(chan WorkerResult, 1)
+
+	w.mu.Lock()
+	w.pending[id] = ch
+	w.mu.Unlock()
+
+	defer func() {
+		w.mu.Lock()
+		delete(w.pending, id)
+		w.mu.Unlock()
+	}()
+
+	select {
+	case w.runs <- WorkerRun{Id: id, Job: j}:
+	case <-w.done:
+		return nil, fmt.Errorf("job `%s` worker `%s` disconnected", j.FullName(), w.Id)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	select {
+	case r := <-ch:
+		if r.Error != "" {
+			return r.Result, fmt.Errorf("job `%s` worker `%s` error: %s", j.FullName(), w.Id, r.Error)
+		}
+		return r.Result, nil
+	case <-w.done:
+		return nil, fmt.Errorf("job `%s` worker `%s` disconnected", j.FullName(), w.Id)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// The workers connected to a scheduler.
+type workerSet struct {
+	mu sync.Mutex
+
+	workers []*Worker
+	// Used to pick the workers of a function in turn.
+	next int
+}
+
+func (ws *workerSet) add(w *Worker) {
+	defer ws.mu.Unlock()
+
+	ws.mu.Lock()
+
+	ws.workers = append(ws.workers, w)
+}
+
+func (ws *workerSet) remove(w *Worker) {
+	defer ws.mu.Unlock()
+
+	ws.mu.Lock()
+
+	ws.workers = slices.DeleteFunc(ws.workers, func(w2 *Worker) bool { return w2 == w })
+}
+
+// Returns a function which runs on one of the workers advertising `name`.
+func (ws *workerSet) get(name string) (registeredFunc, bool) {
+	defer ws.mu.Unlock()
+
+	ws.mu.Lock()
+
+	candidates := make([]*Worker, 0)
+	for _, w := range ws.workers {
+		if slices.Contains(w.Funcs, name) {
+			candidates = append(candidates, w)
+		}
+	}
+	if len(candidates) == 0 {
+		return registeredFunc{}, false
+	}
+
+	ws.next++
+	w := candidates[ws.next%len(candidates)]
+
+	return registeredFunc{
+		run: w.run,
+		info: FuncInfo{
+			Name:   name,
+			Schema: map[string]any{"type": "object"},
+		},
+	}, true
+}
+
+// Returns the names of the functions advertised by the workers.
+func (ws *workerSet) funcNames() []string {
+	defer ws.mu.Unlock()
+
+	ws.mu.Lock()
+
+	names := make([]string, 0)
+	for _, w := range ws.workers {
+		for _, name := range w.Funcs {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// Connect an out-of-process worker which runs the functions `funcs`,
+// a random id is used if `id` is empty.
+//
+// Jobs of these functions are sent to `Worker.Runs`,
+// and finished by `Worker.Report`.
+func (s *Scheduler) ConnectWorker(id string, funcs []string) *Worker {
+	if id == "" {
+		id = strings.Replace(uuid.New().String(), "-", "", -1)[:16]
+	}
+
+	w := &Worker{
+		Id:      id,
+		Funcs:   funcs,
+		runs:    make(chan WorkerRun),
+		done:    make(chan struct{}),
+		pending: make(map[string]chan WorkerResult),
+	}
+	s.workers.add(w)
+
+	slog.Info(fmt.Sprintf("Worker `%s` connected, funcs: %v\n", w.Id, w.Funcs))
+
+	return w
+}
+
+// Disconnect a worker, its unfinished runs fail.
+func (s *Scheduler) DisconnectWorker(w *Worker) {
+	s.workers.remove(w)
+	w.close()
+
+	slog.Info(fmt.Sprintf("Worker `%s` disconnected\n", w.Id))
+}
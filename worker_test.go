@@ -0,0 +1,75 @@
+package agscheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestWorker(id string, funcs ...string) *Worker {
+	return &Worker{
+		Id:      id,
+		Funcs:   funcs,
+		runs:    make(chan WorkerRun),
+		done:    make(chan struct{}),
+		pending: make(map[string]chan WorkerResult),
+	}
+}
+
+func TestWorkerSet(t *testing.T) {
+	ws := workerSet{}
+	w1 := newTestWorker("w1", "a", "b")
+	w2 := newTestWorker("w2", "b")
+	ws.add(w1)
+	ws.add(w2)
+
+	assert.Equal(t, []string{"a", "b"}, ws.funcNames())
+
+	_, ok := ws.get("c")
+	assert.False(t, ok)
+	rf, ok := ws.get("a")
+	assert.True(t, ok)
+	assert.Equal(t, "a", rf.info.Name)
+
+	picked := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		rf, _ := ws.get("b")
+		go rf.run(context.TODO(), Job{})
+		select {
+		case <-w1.Runs():
+			picked["w1"] = true
+		case <-w2.Runs():
+			picked["w2"] = true
+		}
+	}
+	assert.Len(t, picked, 2)
+
+	ws.remove(w1)
+	assert.Equal(t, []string{"b"}, ws.funcNames())
+}
+
+func TestWorkerRun(t *testing.T) {
+	w := newTestWorker("w1", "a")
+
+	go func() {
+		run := <-w.Runs()
+		w.Report(WorkerResult{RunId: "unknown"})
+		w.Report(WorkerResult{RunId: run.Id, Result: map[string]any{"ok": true}, Error: "failed"})
+	}()
+	result, err := w.run(context.TODO(), Job{Id: "1", Name: "job"})
+	assert.Error(t, err)
+	assert.Equal(t, map[string]any{"ok": true}, result)
+	assert.Empty(t, w.pending)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = w.run(ctx, Job{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	w.close()
+	w.close()
+	_, err = w.run(context.TODO(), Job{})
+	assert.Error(t, err)
+}
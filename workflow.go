@@ -0,0 +1,474 @@
+package agscheduler
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/kurtloong/agscheduler/services/proto"
+)
+
+// constant indicating when a downstream job runs
+const (
+	TRIGGER_ALL_SUCCEEDED = "all_succeeded"
+	TRIGGER_ANY_FAILED    = "any_failed"
+	TRIGGER_ALWAYS        = "always"
+)
+
+// constant indicating a job's status in a workflow run,
+// which is one of the record's status once the job runs
+const (
+	WORKFLOW_PENDING = "pending"
+	WORKFLOW_SKIPPED = "skipped"
+)
+
+// A job in a workflow run.
+type WorkflowJob struct {
+	Id          string   `json:"id"`
+	Name        string   `json:"name"`
+	Upstreams   []string `json:"upstreams"`
+	TriggerRule string   `json:"trigger_rule"`
+	// Optional: `WORKFLOW_PENDING` | `WORKFLOW_SKIPPED` | `RECORD_RUNNING` | `RECORD_SUCCEEDED` | `RECORD_FAILED` | `RECORD_TIMEOUT` | `RECORD_CANCELED`
+	Status string `json:"status"`
+}
+
+// A run of a workflow, started when a job with downstream jobs is scheduled.
+type WorkflowRun struct {
+	Id        string `json:"id"`
+	RootJobId string `json:"root_job_id"`
+	// Optional: `RECORD_RUNNING` | `RECORD_SUCCEEDED` | `RECORD_FAILED`
+	Status  string    `json:"status"`
+	StartAt time.Time `json:"start_at"`
+	EndAt   time.Time `json:"end_at"`
+	// The root job first, then the jobs downstream of it.
+	Jobs []WorkflowJob `json:"jobs"`
+}
+
+// Returns the jobs downstream of `id`, directly or not, nearest first.
+func downstreamJobs(id string, js []Job) []Job {
+	downstreams := make([]Job, 0)
+	seen := map[string]bool{id: true}
+	queue := []string{id}
+	for len(queue) > 0 {
+		up := queue[0]
+		queue = queue[1:]
+		for _, j := range js {
+			if !seen[j.Id] && slices.Contains(j.Upstreams, up) {
+				seen[j.Id] = true
+				downstreams = append(downstreams, j)
+				queue = append(queue, j.Id)
+			}
+		}
+	}
+
+	return downstreams
+}
+
+// Returns the cycle going through `j` when its upstreams are set,
+// such as `[a b a]`, or nil.
+// The other jobs have no cycle, since every change is checked.
+func upstreamCycle(j Job, js []Job) []string {
+	upstreams := make(map[string][]string)
+	for _, j2 := range js {
+		upstreams[j2.Id] = j2.Upstreams
+	}
+	upstreams[j.Id] = j.Upstreams
+
+	path := []string{j.Id}
+	visited := make(map[string]bool)
+	var visit func(id string) bool
+	visit = func(id string) bool {
+		for _, up := range upstreams[id] {
+			if up == j.Id {
+				path = append(path, up)
+				return true
+			}
+			if visited[up] {
+				continue
+			}
+			visited[up] = true
+
+			path = append(path, up)
+			if visit(up) {
+				return true
+			}
+			path = path[:len(path)-1]
+		}
+		return false
+	}
+
+	if visit(j.Id) {
+		return path
+	}
+
+	return nil
+}
+
+func isFailedStatus(status string) bool {
+	return status == RECORD_FAILED || status == RECORD_TIMEOUT || status == RECORD_CANCELED
+}
+
+// Whether a job runs when all its upstreams in the workflow run are finished.
+func triggered(rule string, statuses []string) bool {
+	switch rule {
+	case TRIGGER_ANY_FAILED:
+		return slices.ContainsFunc(statuses, isFailedStatus)
+	case TRIGGER_ALWAYS:
+		return true
+	default:
+		return !slices.ContainsFunc(statuses, func(s string) bool { return s != RECORD_SUCCEEDED })
+	}
+}
+
+// Keep the latest workflow runs in memory, the oldest ones are dropped when it is full.
+type workflowStore struct {
+	mu sync.Mutex
+
+	runs []*WorkflowRun
+}
+
+// Default `limit`: 1000
+func (ws *workflowStore) start(root Job, downstreams []Job, limit int) string {
+	defer ws.mu.Unlock()
+
+	ws.mu.Lock()
+
+	run := &WorkflowRun{
+		Id:        strings.Replace(uuid.New().String(), "-", "", -1)[:16],
+		RootJobId: root.Id,
+		Status:    RECORD_RUNNING,
+		StartAt:   time.Now().UTC(),
+	}
+	run.Jobs = append(run.Jobs, WorkflowJob{
+		Id:          root.Id,
+		Name:        root.Name,
+		Upstreams:   root.Upstreams,
+		TriggerRule: root.TriggerRule,
+		Status:      RECORD_RUNNING,
+	})
+	for _, j := range downstreams {
+		run.Jobs = append(run.Jobs, WorkflowJob{
+			Id:          j.Id,
+			Name:        j.Name,
+			Upstreams:   j.Upstreams,
+			TriggerRule: j.TriggerRule,
+			Status:      WORKFLOW_PENDING,
+		})
+	}
+	ws.runs = append(ws.runs, run)
+
+	if limit <= 0 {
+		limit = 1000
+	}
+	if len(ws.runs) > limit {
+		ws.runs = ws.runs[len(ws.runs)-limit:]
+	}
+
+	return run.Id
+}
+
+func (ws *workflowStore) get(id string) (WorkflowRun, bool) {
+	defer ws.mu.Unlock()
+
+	ws.mu.Lock()
+
+	for _, run := range ws.runs {
+		if run.Id == id {
+			r := *run
+			r.Jobs = slices.Clone(run.Jobs)
+			return r, true
+		}
+	}
+
+	return WorkflowRun{}, false
+}
+
+// Set the status of a job in a workflow run,
+// and returns the ids of the downstream jobs which should run now.
+// The jobs not meeting their trigger rules are skipped,
+// upstreams outside the workflow run are not waited for.
+func (ws *workflowStore) finish(runId string, jobId string, status string) []string {
+	defer ws.mu.Unlock()
+
+	ws.mu.Lock()
+
+	i := slices.IndexFunc(ws.runs, func(run *WorkflowRun) bool { return run.Id == runId })
+	if i < 0 {
+		return nil
+	}
+	run := ws.runs[i]
+
+	statuses := make(map[string]string)
+	for _, wj := range run.Jobs {
+		statuses[wj.Id] = wj.Status
+	}
+	if _, ok := statuses[jobId]; !ok {
+		return nil
+	}
+	statuses[jobId] = status
+
+	ids := make([]string, 0)
+	for changed := true; changed; {
+		changed = false
+		for _, wj := range run.Jobs {
+			if statuses[wj.Id] != WORKFLOW_PENDING {
+				continue
+			}
+
+			upStatuses := make([]string, 0)
+			for _, up := range wj.Upstreams {
+				if s, ok := statuses[up]; ok {
+					upStatuses = append(upStatuses, s)
+				}
+			}
+			if slices.Contains(upStatuses, WORKFLOW_PENDING) || slices.Contains(upStatuses, RECORD_RUNNING) {
+				continue
+			}
+
+			if triggered(wj.TriggerRule, upStatuses) {
+				statuses[wj.Id] = RECORD_RUNNING
+				ids = append(ids, wj.Id)
+			} else {
+				statuses[wj.Id] = WORKFLOW_SKIPPED
+			}
+			changed = true
+		}
+	}
+
+	isFinished, isFailed := true, false
+	for k := range run.Jobs {
+		s := statuses[run.Jobs[k].Id]
+		run.Jobs[k].Status = s
+		if s == WORKFLOW_PENDING || s == RECORD_RUNNING {
+			isFinished = false
+		}
+		if isFailedStatus(s) {
+			isFailed = true
+		}
+	}
+	if isFinished {
+		run.Status = RECORD_SUCCEEDED
+		if isFailed {
+			run.Status = RECORD_FAILED
+		}
+		run.EndAt = time.Now().UTC()
+	}
+
+	return ids
+}
+
+// Upstreams must exist and must not form a cycle.
+func (s *Scheduler) checkUpstreams(j Job) error {
+	if len(j.Upstreams) == 0 {
+		return nil
+	}
+
+	js, err := s.GetAllJobs()
+	if err != nil {
+		return err
+	}
+
+	for _, id := range j.Upstreams {
+		if id == j.Id {
+			continue
+		}
+		if !slices.ContainsFunc(js, func(j2 Job) bool { return j2.Id == id }) {
+			return fmt.Errorf("job `%s` Upstream error: %w", j.FullName(), JobNotFoundError(id))
+		}
+	}
+
+	if cycle := upstreamCycle(j, js); cycle != nil {
+		return &WorkflowCycleError{FullName: j.FullName(), Cycle: cycle}
+	}
+
+	return nil
+}
+
+// Start a workflow run when the job has downstream jobs,
+// the returned job carries the id of the workflow run.
+//
+// In cluster mode, workflow runs are tracked by the main node.
+func (s *Scheduler) startWorkflow(j Job, js []Job) Job {
+	if j.WorkflowRunId != "" {
+		return j
+	}
+	if s.clusterNode != nil && !s.clusterNode.isMain() {
+		return j
+	}
+
+	downstreams := downstreamJobs(j.Id, js)
+	if len(downstreams) == 0 {
+		return j
+	}
+
+	j.WorkflowRunId = s.workflows.start(j, downstreams, s.MaxRecords)
+	slog.Info(fmt.Sprintf("Job `%s` start workflow run `%s`.\n", j.FullName(), j.WorkflowRunId))
+
+	return j
+}
+
+// Start a workflow run for the job as `startWorkflow`, then schedule it.
+// A workflow run started here fails at once when the job can not be scheduled,
+// so that it does not stay running and its downstream jobs are resolved.
+func (s *Scheduler) scheduleWorkflow(j Job, js []Job) error {
+	wJ := s.startWorkflow(j, js)
+	err := s._scheduleJob(wJ)
+	if err != nil && wJ.WorkflowRunId != j.WorkflowRunId {
+		s.workflowJobFinished(wJ.WorkflowRunId, wJ.Id, RECORD_FAILED)
+	}
+
+	return err
+}
+
+// Called when a run of a job in a workflow run finishes,
+// the downstream jobs meeting their trigger rules are scheduled.
+func (s *Scheduler) workflowJobFinished(runId string, jobId string, status string) {
+	for _, id := range s.workflows.finish(runId, jobId, status) {
+		j, err := s.GetJob(id)
+		if err != nil || j.Status == STATUS_PAUSED || s.IsPaused() {
+			slog.Info(fmt.Sprintf("Workflow run `%s` job `%s` skipped.\n", runId, id))
+			s.workflowJobFinished(runId, id, WORKFLOW_SKIPPED)
+			continue
+		}
+
+		j.WorkflowRunId = runId
+		if err := s._scheduleJob(j); err != nil {
+			slog.Error(fmt.Sprintf("Workflow run `%s` schedule job `%s` error: %s\n", runId, j.FullName(), err))
+			s.workflowJobFinished(runId, id, RECORD_FAILED)
+		}
+	}
+}
+
+// Run the jobs chained to a finished run, matched by id or name.
+func (s *Scheduler) chainJobs(f JobFinished) {
+	var targets []string
+	switch f.Status {
+	case RECORD_SUCCEEDED:
+		targets = f.Job.OnSuccess
+	case RECORD_FAILED, RECORD_TIMEOUT:
+		targets = f.Job.OnFailure
+	}
+	if len(targets) == 0 {
+		return
+	}
+
+	js, err := s.GetAllJobs()
+	if err != nil {
+		slog.Error(fmt.Sprintf("Job `%s` chain jobs error: %s\n", f.Job.FullName(), err))
+		return
+	}
+
+	for _, target := range targets {
+		isMatched := false
+		for _, j := range js {
+			if j.Id != target && j.Name != target {
+				continue
+			}
+			isMatched = true
+
+			if j.Status == STATUS_PAUSED || s.IsPaused() {
+				slog.Info(fmt.Sprintf("Job `%s` chained by job `%s` skipped.\n", j.FullName(), f.Job.FullName()))
+				continue
+			}
+
+			j.TriggeredBy = f.Job.Id
+			j.TriggerResult = f.Result
+			slog.Info(fmt.Sprintf("Job `%s` chained by job `%s`.\n", j.FullName(), f.Job.FullName()))
+			if err := s.scheduleWorkflow(j, js); err != nil {
+				slog.Error(fmt.Sprintf("Scheduler schedule job `%s` error: %s\n", j.FullName(), err))
+			}
+		}
+		if !isMatched {
+			slog.Warn(fmt.Sprintf("Job `%s` chained job `%s` not found\n", f.Job.FullName(), target))
+		}
+	}
+}
+
+// Called on the node tracking the workflow runs, when a run finishes.
+func (s *Scheduler) handleJobFinished(f JobFinished) {
+	if f.Job.WorkflowRunId != "" {
+		s.workflowJobFinished(f.Job.WorkflowRunId, f.Job.Id, f.Status)
+	}
+	s.chainJobs(f)
+}
+
+// Report a finished run to the node tracking its workflow run,
+// which also runs the chained jobs.
+func (s *Scheduler) jobFinished(j Job, status string, result map[string]any) {
+	if j.WorkflowRunId == "" && len(j.OnSuccess) == 0 && len(j.OnFailure) == 0 {
+		return
+	}
+
+	f := JobFinished{Job: j, Status: status, Result: result}
+	if s.clusterNode != nil && !s.clusterNode.isMain() {
+		go func() {
+			if err := s.clusterNode.reportJobFinished(f); err != nil {
+				slog.Error(fmt.Sprintf("Job `%s` report finished error: %s\n", j.FullName(), err))
+			}
+		}()
+		return
+	}
+
+	s.handleJobFinished(f)
+}
+
+// Returns a workflow run with the status of each job.
+// In cluster mode, it is only available on the main node.
+func (s *Scheduler) GetWorkflowRun(id string) (WorkflowRun, error) {
+	run, ok := s.workflows.get(id)
+	if !ok {
+		return WorkflowRun{}, WorkflowRunNotFoundError(id)
+	}
+
+	return run, nil
+}
+
+// Used to gRPC Protobuf
+func WorkflowRunToPbWorkflowRunPtr(run WorkflowRun) *pb.WorkflowRun {
+	pbRun := &pb.WorkflowRun{
+		Id:        run.Id,
+		RootJobId: run.RootJobId,
+		Status:    run.Status,
+		StartAt:   timestamppb.New(run.StartAt),
+		EndAt:     timestamppb.New(run.EndAt),
+	}
+	for _, wj := range run.Jobs {
+		pbRun.Jobs = append(pbRun.Jobs, &pb.WorkflowJob{
+			Id:          wj.Id,
+			Name:        wj.Name,
+			Upstreams:   wj.Upstreams,
+			TriggerRule: wj.TriggerRule,
+			Status:      wj.Status,
+		})
+	}
+
+	return pbRun
+}
+
+// Used to gRPC Protobuf
+func PbWorkflowRunPtrToWorkflowRun(pbRun *pb.WorkflowRun) WorkflowRun {
+	run := WorkflowRun{
+		Id:        pbRun.GetId(),
+		RootJobId: pbRun.GetRootJobId(),
+		Status:    pbRun.GetStatus(),
+		StartAt:   pbRun.GetStartAt().AsTime(),
+		EndAt:     pbRun.GetEndAt().AsTime(),
+	}
+	for _, pbWj := range pbRun.GetJobs() {
+		run.Jobs = append(run.Jobs, WorkflowJob{
+			Id:          pbWj.GetId(),
+			Name:        pbWj.GetName(),
+			Upstreams:   pbWj.GetUpstreams(),
+			TriggerRule: pbWj.GetTriggerRule(),
+			Status:      pbWj.GetStatus(),
+		})
+	}
+
+	return run
+}
@@ -0,0 +1,111 @@
+package agscheduler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getWorkflowJobs() []Job {
+	return []Job{
+		{Id: "a", Name: "a"},
+		{Id: "b", Name: "b", Upstreams: []string{"a"}, TriggerRule: TRIGGER_ALL_SUCCEEDED},
+		{Id: "c", Name: "c", Upstreams: []string{"a"}, TriggerRule: TRIGGER_ANY_FAILED},
+		{Id: "d", Name: "d", Upstreams: []string{"b", "c"}, TriggerRule: TRIGGER_ALWAYS},
+		{Id: "e", Name: "e", Upstreams: []string{"x"}},
+	}
+}
+
+func TestDownstreamJobs(t *testing.T) {
+	js := getWorkflowJobs()
+
+	ids := make([]string, 0)
+	for _, j := range downstreamJobs("a", js) {
+		ids = append(ids, j.Id)
+	}
+	assert.Equal(t, []string{"b", "c", "d"}, ids)
+	assert.Len(t, downstreamJobs("b", js), 1)
+	assert.Empty(t, downstreamJobs("d", js))
+}
+
+func TestUpstreamCycle(t *testing.T) {
+	js := getWorkflowJobs()
+
+	assert.Nil(t, upstreamCycle(js[3], js))
+	assert.Equal(t, []string{"a", "d", "b", "a"}, upstreamCycle(Job{Id: "a", Upstreams: []string{"d"}}, js))
+	assert.Equal(t, []string{"a", "a"}, upstreamCycle(Job{Id: "a", Upstreams: []string{"a"}}, js))
+}
+
+func TestTriggered(t *testing.T) {
+	assert.True(t, triggered(TRIGGER_ALL_SUCCEEDED, []string{RECORD_SUCCEEDED, RECORD_SUCCEEDED}))
+	assert.False(t, triggered(TRIGGER_ALL_SUCCEEDED, []string{RECORD_SUCCEEDED, WORKFLOW_SKIPPED}))
+	assert.True(t, triggered(TRIGGER_ANY_FAILED, []string{RECORD_SUCCEEDED, RECORD_TIMEOUT}))
+	assert.False(t, triggered(TRIGGER_ANY_FAILED, []string{RECORD_SUCCEEDED, WORKFLOW_SKIPPED}))
+	assert.True(t, triggered(TRIGGER_ALWAYS, []string{RECORD_FAILED, WORKFLOW_SKIPPED}))
+}
+
+func TestWorkflowStore(t *testing.T) {
+	ws := &workflowStore{}
+	js := getWorkflowJobs()
+
+	id := ws.start(js[0], downstreamJobs("a", js), 0)
+	run, ok := ws.get(id)
+	assert.True(t, ok)
+	assert.Equal(t, RECORD_RUNNING, run.Status)
+	assert.Len(t, run.Jobs, 4)
+
+	assert.Equal(t, []string{"b"}, ws.finish(id, "a", RECORD_SUCCEEDED))
+	run, _ = ws.get(id)
+	assert.Equal(t, WORKFLOW_SKIPPED, run.Jobs[2].Status)
+	assert.Equal(t, WORKFLOW_PENDING, run.Jobs[3].Status)
+
+	assert.Equal(t, []string{"d"}, ws.finish(id, "b", RECORD_FAILED))
+	assert.Empty(t, ws.finish(id, "d", RECORD_SUCCEEDED))
+	run, _ = ws.get(id)
+	assert.Equal(t, RECORD_FAILED, run.Status)
+	assert.False(t, run.EndAt.IsZero())
+
+	assert.Empty(t, ws.finish("unknown", "a", RECORD_SUCCEEDED))
+	_, ok = ws.get("unknown")
+	assert.False(t, ok)
+}
+
+func TestWorkflowStoreLimit(t *testing.T) {
+	ws := &workflowStore{}
+	js := getWorkflowJobs()
+
+	first := ws.start(js[0], nil, 2)
+	ws.start(js[0], nil, 2)
+	ws.start(js[0], nil, 2)
+
+	assert.Len(t, ws.runs, 2)
+	_, ok := ws.get(first)
+	assert.False(t, ok)
+}
+
+func TestWorkflowRunToPbWorkflowRunPtr(t *testing.T) {
+	ws := &workflowStore{}
+	js := getWorkflowJobs()
+	id := ws.start(js[0], downstreamJobs("a", js), 0)
+	run, _ := ws.get(id)
+
+	pbRun := WorkflowRunToPbWorkflowRunPtr(run)
+
+	assert.Len(t, pbRun.Jobs, 4)
+	assert.Equal(t, run, PbWorkflowRunPtrToWorkflowRun(pbRun))
+}
+
+func TestSchedulerScheduleWorkflowUnreachableQueue(t *testing.T) {
+	s := &Scheduler{clusterNode: getClusterNode()}
+	js := getWorkflowJobs()[:2]
+	j := js[0]
+	j.Queues = []string{"unknown"}
+
+	err := s.scheduleWorkflow(j, js)
+	assert.Error(t, err)
+
+	runs := s.workflows.runs
+	assert.Len(t, runs, 1)
+	assert.Equal(t, RECORD_FAILED, runs[0].Status)
+	assert.False(t, runs[0].EndAt.IsZero())
+}